@@ -1,88 +1,166 @@
-package core
-
-import (
-	rl "github.com/gen2brain/raylib-go/raylib"
-
-	"github.com/noahdw/goui/node"
-)
-
-// Application represents a UI application window
-type Application struct {
-	title  string
-	width  int
-	height int
-	root   node.Node
-	engine *RenderEngine
-}
-
-// NewApplication creates a new application
-func NewApplication(title string, width, height int) *Application {
-	return &Application{
-		title:  title,
-		width:  width,
-		height: height,
-	}
-}
-
-// SetRoot sets the root node of the application
-func (app *Application) SetRoot(root node.Node) {
-	app.root = root
-}
-
-// Run starts the application main loop
-func (app *Application) Run() {
-
-	initRaylib(app.title, app.width, app.height)
-	defer closeRaylib()
-
-	// Create the render context
-	context := NewRaylibRenderContext()
-
-	// Create the render engine
-	app.engine = NewRenderEngine(app.root, context, float64(app.width), float64(app.height))
-
-	// Run the main loop
-	for !shouldWindowClose() {
-		// Handle input events and dispatch to UI
-		processInputEvents(app.root)
-
-		// Begin drawing
-		beginDrawing(app.engine.GetCamera())
-
-		// Render the UI
-		app.engine.RenderFrame()
-
-		// End drawing
-		endDrawing()
-	}
-}
-
-func initRaylib(title string, width, height int) {
-	rl.SetConfigFlags(rl.FlagMsaa4xHint)
-	rl.SetConfigFlags(rl.FlagWindowResizable)
-	rl.SetTargetFPS(120)
-	rl.InitWindow(int32(width), int32(height), title)
-}
-
-func closeRaylib() {
-	// Close Raylib
-}
-
-func beginDrawing(camera rl.Camera2D) {
-	rl.BeginDrawing()
-	rl.BeginMode2D(camera)
-	rl.ClearBackground(rl.RayWhite)
-}
-
-func endDrawing() {
-	rl.EndMode2D()
-	rl.EndDrawing()
-}
-
-func shouldWindowClose() bool {
-	return rl.WindowShouldClose()
-}
-
-func processInputEvents(root node.Node) {
-	// Process input events and dispatch to UI components
-}
+package core
+
+import (
+	"runtime"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+
+	"github.com/noahdw/goui/node"
+)
+
+// Application represents a UI application window
+type Application struct {
+	title       string
+	width       int
+	height      int
+	root        node.Node
+	engine      *RenderEngine
+	deviceScale float64 // 0 means detect from the monitor
+	workers     int     // Goroutines laying out independent subtrees
+}
+
+// ApplicationOption configures an Application
+type ApplicationOption func(*Application)
+
+// WithDeviceScale overrides the detected device pixel ratio, e.g. to test HiDPI rendering on a regular screen
+func WithDeviceScale(scale float64) ApplicationOption {
+	return func(app *Application) {
+		app.deviceScale = scale
+	}
+}
+
+// WithParallelLayout lays out subtrees with a fixed width and height on up to workers goroutines
+func WithParallelLayout(workers int) ApplicationOption {
+	return func(app *Application) {
+		app.workers = workers
+	}
+}
+
+// NewApplication creates a new application.
+// Width and height are in logical units, which are scaled to physical pixels by the device pixel ratio.
+func NewApplication(title string, width, height int, options ...ApplicationOption) *Application {
+	app := &Application{
+		title:  title,
+		width:  width,
+		height: height,
+	}
+	for _, option := range options {
+		option(app)
+	}
+	return app
+}
+
+// SetRoot sets the root node of the application
+func (app *Application) SetRoot(root node.Node) {
+	app.root = root
+}
+
+// Run starts the application main loop
+func (app *Application) Run() {
+
+	scale := initRaylib(app.title, app.width, app.height, app.deviceScale)
+	defer closeRaylib()
+
+	// Create the render context
+	context := NewRaylibRenderContext()
+	context.SetDeviceScale(scale)
+
+	// Create the render engine
+	app.engine = NewRenderEngine(app.root, context, float64(app.width), float64(app.height))
+	app.engine.SetDeviceScale(scale)
+	app.engine.SetParallelLayout(app.workers)
+
+	// Run the main loop
+	waiting := false
+	for !shouldWindowClose() {
+		// Handle input events and dispatch to UI
+		processInputEvents(app.root)
+
+		// Begin drawing
+		beginDrawing(app.engine.GetCamera())
+
+		// Render the UI
+		app.engine.RenderFrame()
+
+		// While idle, block in endDrawing until the next input event instead of spinning
+		if idle := app.engine.IsIdle(); idle != waiting {
+			setEventWaiting(idle)
+			waiting = idle
+		}
+
+		// End drawing
+		endDrawing()
+	}
+}
+
+// initRaylib opens a high-DPI window and returns the device pixel ratio in use, which is
+// the ratio of framebuffer pixels to screen coordinates unless it is overridden. goui draws
+// in framebuffer pixels itself, so text and images are rasterized at full resolution.
+func initRaylib(title string, width, height int, deviceScale float64) float64 {
+	rl.SetConfigFlags(rl.FlagMsaa4xHint)
+	rl.SetConfigFlags(rl.FlagWindowResizable)
+	rl.SetConfigFlags(rl.FlagWindowHighdpi)
+	rl.SetTargetFPS(120)
+	rl.InitWindow(int32(width), int32(height), title)
+
+	ratio := framebufferScale()
+	if deviceScale <= 0 {
+		return ratio
+	}
+
+	// An overridden scale gets a framebuffer of that many pixels per unit, which on a regular
+	// screen makes the window larger
+	if deviceScale != ratio {
+		rl.SetWindowSize(int(float64(width)*deviceScale/ratio), int(float64(height)*deviceScale/ratio))
+	}
+	return deviceScale
+}
+
+// framebufferScale returns the number of framebuffer pixels per screen coordinate
+func framebufferScale() float64 {
+	if rl.GetScreenWidth() <= 0 || rl.GetRenderWidth() <= 0 {
+		return 1.0
+	}
+	return float64(rl.GetRenderWidth()) / float64(rl.GetScreenWidth())
+}
+
+// framebufferCamera returns a camera for drawing to the window in framebuffer pixels. On macOS
+// raylib projects the window in screen coordinates, elsewhere in framebuffer pixels.
+func framebufferCamera() rl.Camera2D {
+	camera := rl.Camera2D{Zoom: 1}
+	if runtime.GOOS == "darwin" {
+		camera.Zoom = float32(1 / framebufferScale())
+	}
+	return camera
+}
+
+func closeRaylib() {
+	// Close Raylib
+}
+
+func beginDrawing(camera rl.Camera2D) {
+	rl.BeginDrawing()
+	rl.BeginMode2D(camera)
+	rl.ClearBackground(rl.RayWhite)
+}
+
+func endDrawing() {
+	rl.EndMode2D()
+	rl.EndDrawing()
+}
+
+func setEventWaiting(enabled bool) {
+	if enabled {
+		rl.EnableEventWaiting()
+	} else {
+		rl.DisableEventWaiting()
+	}
+}
+
+func shouldWindowClose() bool {
+	return rl.WindowShouldClose()
+}
+
+func processInputEvents(root node.Node) {
+	// Process input events and dispatch to UI components
+}
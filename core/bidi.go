@@ -0,0 +1,75 @@
+package core
+
+import "unicode"
+
+// mirroredBrackets are the brackets that face the other way in right-to-left text
+var mirroredBrackets = map[rune]rune{
+	'(': ')', ')': '(',
+	'[': ']', ']': '[',
+	'{': '}', '}': '{',
+	'<': '>', '>': '<',
+}
+
+// isRTLRune returns true if the rune belongs to a script written from right to left
+func isRTLRune(r rune) bool {
+	return unicode.In(r, unicode.Hebrew, unicode.Arabic, unicode.Syriac, unicode.Thaana, unicode.Nko)
+}
+
+// isLTRRune returns true if the rune is a letter or digit written from left to right
+func isLTRRune(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !isRTLRune(r)
+}
+
+// visualOrder reorders a line of right-to-left text into the order it is drawn in from left
+// to right. The line reads from right to left, while runs of left-to-right words and numbers
+// keep their own order, with the spaces and punctuation between their words. Brackets
+// outside those runs are mirrored.
+func visualOrder(text string) string {
+	runes := []rune(text)
+	count := len(runes)
+
+	// Find the left-to-right runs before reversing the line
+	ltr := make([]bool, count)
+	start := -1
+	for i, r := range runes {
+		switch {
+		case isLTRRune(r):
+			if start < 0 {
+				start = i
+			}
+			for j := start; j <= i; j++ {
+				ltr[j] = true
+			}
+			start = i + 1
+		case isRTLRune(r):
+			start = -1
+		}
+	}
+
+	visual := make([]rune, count)
+	visualLTR := make([]bool, count)
+	for i, r := range runes {
+		visual[count-1-i] = r
+		visualLTR[count-1-i] = ltr[i]
+	}
+
+	// Reverse the left-to-right runs back and mirror the brackets in the rest
+	for i := 0; i < count; {
+		if !visualLTR[i] {
+			if mirrored, ok := mirroredBrackets[visual[i]]; ok {
+				visual[i] = mirrored
+			}
+			i++
+			continue
+		}
+		end := i
+		for end < count && visualLTR[end] {
+			end++
+		}
+		for a, b := i, end-1; a < b; a, b = a+1, b-1 {
+			visual[a], visual[b] = visual[b], visual[a]
+		}
+		i = end
+	}
+	return string(visual)
+}
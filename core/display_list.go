@@ -0,0 +1,591 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/noahdw/goui/node"
+	"github.com/noahdw/goui/node/style"
+)
+
+// DisplayOpKind identifies the RenderContext call a display op was recorded from
+type DisplayOpKind string
+
+const (
+	OpClear          DisplayOpKind = "clear"
+	OpSave           DisplayOpKind = "save"
+	OpRestore        DisplayOpKind = "restore"
+	OpSetOpacity     DisplayOpKind = "setOpacity"
+	OpSetFillColor   DisplayOpKind = "setFillColor"
+	OpSetStrokeColor DisplayOpKind = "setStrokeColor"
+	OpSetLineWidth   DisplayOpKind = "setLineWidth"
+	OpSetFontSize    DisplayOpKind = "setFontSize"
+	OpScale          DisplayOpKind = "scale"
+	OpStrokeLine     DisplayOpKind = "strokeLine"
+	OpFillRect       DisplayOpKind = "fillRect"
+	OpDrawBackground DisplayOpKind = "drawBackground"
+	OpDrawBorders    DisplayOpKind = "drawBorders"
+	OpDrawText       DisplayOpKind = "drawText"
+	OpDrawTexture    DisplayOpKind = "drawTexture"
+	OpSetClipRect    DisplayOpKind = "setClipRect"
+	OpPresent        DisplayOpKind = "present"
+)
+
+// DisplayStyles holds the style values a draw call reads.
+// Only the properties that the RenderContext implementations use are captured,
+// which keeps recorded ops small and comparable.
+type DisplayStyles struct {
+	Background     *style.Color       `json:"background,omitempty"`
+	Color          *style.Color       `json:"color,omitempty"`
+	Border         *style.BorderStyle `json:"border,omitempty"`
+	BorderRadius   *style.EdgeInsets  `json:"borderRadius,omitempty"`
+	Padding        *style.EdgeInsets  `json:"padding,omitempty"`
+	FontFamily     string             `json:"fontFamily,omitempty"`
+	FontSize       float64            `json:"fontSize,omitempty"`
+	TextAlign      string             `json:"textAlign,omitempty"`
+	Direction      string             `json:"direction,omitempty"`
+	AlignItems     string             `json:"alignItems,omitempty"`
+	ObjectFit      string             `json:"objectFit,omitempty"`
+	ObjectPosition string             `json:"objectPosition,omitempty"`
+}
+
+// DisplayOp is a single recorded RenderContext call
+type DisplayOp struct {
+	Kind    DisplayOpKind  `json:"op"`
+	Bounds  style.Rect     `json:"bounds"`
+	Styles  *DisplayStyles `json:"styles,omitempty"`
+	Opacity float64        `json:"opacity"`
+	Text    string         `json:"text,omitempty"`
+	Source  string         `json:"source,omitempty"`
+	Color   style.Color    `json:"color"`
+	Value   float64        `json:"value,omitempty"`
+	ScaleX  float64        `json:"scaleX,omitempty"`
+	ScaleY  float64        `json:"scaleY,omitempty"`
+	Start   style.Point    `json:"start"`
+	End     style.Point    `json:"end"`
+}
+
+// DisplayList is the ordered list of draw calls made during a frame
+type DisplayList []DisplayOp
+
+// RecordingRenderContext wraps another RenderContext and records every call made to it.
+// The inner context may be nil, in which case calls are only recorded.
+type RecordingRenderContext struct {
+	inner    node.RenderContext
+	ops      DisplayList
+	clipRect style.Rect
+}
+
+// NewRecordingRenderContext creates a recording context that forwards to inner
+func NewRecordingRenderContext(inner node.RenderContext) *RecordingRenderContext {
+	r := &RecordingRenderContext{inner: inner}
+	if inner != nil {
+		r.clipRect = inner.ClipRect()
+	}
+	return r
+}
+
+// DisplayList returns a copy of the ops recorded so far
+func (r *RecordingRenderContext) DisplayList() DisplayList {
+	list := make(DisplayList, len(r.ops))
+	copy(list, r.ops)
+	return list
+}
+
+// Reset discards the recorded ops
+func (r *RecordingRenderContext) Reset() {
+	r.ops = r.ops[:0]
+}
+
+// record appends an op to the display list
+func (r *RecordingRenderContext) record(op DisplayOp) {
+	r.ops = append(r.ops, op)
+}
+
+// Clear clears the screen with a background color
+func (r *RecordingRenderContext) Clear() {
+	r.record(DisplayOp{Kind: OpClear})
+	if r.inner != nil {
+		r.inner.Clear()
+	}
+}
+
+// Save saves the current rendering state
+func (r *RecordingRenderContext) Save() {
+	r.record(DisplayOp{Kind: OpSave})
+	if r.inner != nil {
+		r.inner.Save()
+	}
+}
+
+// Restore restores the previously saved rendering state
+func (r *RecordingRenderContext) Restore() {
+	r.record(DisplayOp{Kind: OpRestore})
+	if r.inner != nil {
+		r.inner.Restore()
+	}
+}
+
+// SetOpacity sets the current opacity
+func (r *RecordingRenderContext) SetOpacity(opacity float64) {
+	r.record(DisplayOp{Kind: OpSetOpacity, Opacity: opacity})
+	if r.inner != nil {
+		r.inner.SetOpacity(opacity)
+	}
+}
+
+// SetFillColor sets the current fill color
+func (r *RecordingRenderContext) SetFillColor(color style.Color) {
+	r.record(DisplayOp{Kind: OpSetFillColor, Color: color})
+	if r.inner != nil {
+		r.inner.SetFillColor(color)
+	}
+}
+
+// SetStrokeColor sets the current stroke color
+func (r *RecordingRenderContext) SetStrokeColor(color style.Color) {
+	r.record(DisplayOp{Kind: OpSetStrokeColor, Color: color})
+	if r.inner != nil {
+		r.inner.SetStrokeColor(color)
+	}
+}
+
+// SetLineWidth sets the current line width
+func (r *RecordingRenderContext) SetLineWidth(width float64) {
+	r.record(DisplayOp{Kind: OpSetLineWidth, Value: width})
+	if r.inner != nil {
+		r.inner.SetLineWidth(width)
+	}
+}
+
+// SetFontSize sets the current font size
+func (r *RecordingRenderContext) SetFontSize(size float64) {
+	r.record(DisplayOp{Kind: OpSetFontSize, Value: size})
+	if r.inner != nil {
+		r.inner.SetFontSize(size)
+	}
+}
+
+// Scale sets the current scale transform
+func (r *RecordingRenderContext) Scale(x, y float64) {
+	r.record(DisplayOp{Kind: OpScale, ScaleX: x, ScaleY: y})
+	if r.inner != nil {
+		r.inner.Scale(x, y)
+	}
+}
+
+// StrokeLine draws a line from start to end
+func (r *RecordingRenderContext) StrokeLine(start, end style.Point) {
+	r.record(DisplayOp{Kind: OpStrokeLine, Start: start, End: end})
+	if r.inner != nil {
+		r.inner.StrokeLine(start, end)
+	}
+}
+
+// FillRect fills a rectangle with the current fill color
+func (r *RecordingRenderContext) FillRect(rect style.Rect) {
+	r.record(DisplayOp{Kind: OpFillRect, Bounds: rect})
+	if r.inner != nil {
+		r.inner.FillRect(rect)
+	}
+}
+
+// DrawBackground draws a background with the specified styles
+func (r *RecordingRenderContext) DrawBackground(bounds style.Rect, styles style.Styles, opacity float64) {
+	used := &DisplayStyles{}
+	if c, ok := styles.GetColor("background"); ok {
+		used.Background = &c
+	}
+	if e, ok := styles.GetEdgeInsets("borderRadius"); ok {
+		used.BorderRadius = &e
+	}
+	r.record(DisplayOp{Kind: OpDrawBackground, Bounds: bounds, Styles: used, Opacity: opacity})
+	if r.inner != nil {
+		r.inner.DrawBackground(bounds, styles, opacity)
+	}
+}
+
+// DrawBorders draws borders with the specified style
+func (r *RecordingRenderContext) DrawBorders(bounds style.Rect, styles style.Styles, opacity float64) {
+	used := &DisplayStyles{}
+	if border, ok := styles.Get("border"); ok {
+		if b, ok := border.(style.BorderStyle); ok {
+			used.Border = &b
+		}
+	}
+	r.record(DisplayOp{Kind: OpDrawBorders, Bounds: bounds, Styles: used, Opacity: opacity})
+	if r.inner != nil {
+		r.inner.DrawBorders(bounds, styles, opacity)
+	}
+}
+
+// DrawText draws text with the specified styles
+func (r *RecordingRenderContext) DrawText(text string, bounds style.Rect, styles style.Styles, opacity float64) {
+	used := &DisplayStyles{}
+	if c, ok := styles.GetColor("color"); ok {
+		used.Color = &c
+	}
+	if e, ok := styles.GetEdgeInsets("padding"); ok {
+		used.Padding = &e
+	}
+	used.FontFamily, _ = styles.GetString("fontFamily")
+	used.FontSize, _ = styles.GetFloat("fontSize")
+	used.TextAlign, _ = styles.GetString("textAlign")
+	used.AlignItems, _ = styles.GetString("alignItems")
+	if styles.IsRTL() {
+		used.Direction = "rtl"
+	}
+	r.record(DisplayOp{Kind: OpDrawText, Bounds: bounds, Styles: used, Opacity: opacity, Text: text})
+	if r.inner != nil {
+		r.inner.DrawText(text, bounds, styles, opacity)
+	}
+}
+
+// DrawTexture draws a texture with the specified styles
+func (r *RecordingRenderContext) DrawTexture(sourceURL string, bounds style.Rect, styles style.Styles, opacity float64) {
+	used := &DisplayStyles{}
+	used.ObjectFit, _ = styles.GetString("objectFit")
+	used.ObjectPosition, _ = styles.GetString("objectPosition")
+	r.record(DisplayOp{Kind: OpDrawTexture, Bounds: bounds, Styles: used, Opacity: opacity, Source: sourceURL})
+	if r.inner != nil {
+		r.inner.DrawTexture(sourceURL, bounds, styles, opacity)
+	}
+}
+
+// LoadTexture loads a texture through the inner context; loading is not recorded
+func (r *RecordingRenderContext) LoadTexture(sourceURL string) rl.Texture2D {
+	if r.inner != nil {
+		return r.inner.LoadTexture(sourceURL)
+	}
+	return rl.Texture2D{}
+}
+
+// ClipRect returns the current clipping rectangle
+func (r *RecordingRenderContext) ClipRect() style.Rect {
+	if r.inner != nil {
+		return r.inner.ClipRect()
+	}
+	return r.clipRect
+}
+
+// SetClipRect sets the current clipping rectangle
+func (r *RecordingRenderContext) SetClipRect(rect style.Rect) {
+	r.record(DisplayOp{Kind: OpSetClipRect, Bounds: rect})
+	r.clipRect = rect
+	if r.inner != nil {
+		r.inner.SetClipRect(rect)
+	}
+}
+
+// Present presents the frame
+func (r *RecordingRenderContext) Present() {
+	r.record(DisplayOp{Kind: OpPresent})
+	if r.inner != nil {
+		r.inner.Present()
+	}
+}
+
+// RecordPaint paints a node and its subtree into a new display list without drawing anything.
+// The result can be cached and replayed later as long as the subtree does not change.
+func RecordPaint(n node.Node) DisplayList {
+	recorder := NewRecordingRenderContext(nil)
+	n.Paint(recorder)
+	return recorder.DisplayList()
+}
+
+// toStyles rebuilds a Styles value carrying the captured properties
+func (d *DisplayStyles) toStyles() style.Styles {
+	props := make(map[string]interface{})
+	if d != nil {
+		if d.Background != nil {
+			props["background"] = *d.Background
+		}
+		if d.Color != nil {
+			props["color"] = *d.Color
+		}
+		if d.Border != nil {
+			props["border"] = *d.Border
+		}
+		if d.BorderRadius != nil {
+			props["borderRadius"] = *d.BorderRadius
+		}
+		if d.Padding != nil {
+			props["padding"] = *d.Padding
+		}
+		if d.FontFamily != "" {
+			props["fontFamily"] = d.FontFamily
+		}
+		if d.FontSize != 0 {
+			props["fontSize"] = d.FontSize
+		}
+		if d.TextAlign != "" {
+			props["textAlign"] = d.TextAlign
+		}
+		if d.Direction != "" {
+			props["direction"] = d.Direction
+		}
+		if d.AlignItems != "" {
+			props["alignItems"] = d.AlignItems
+		}
+		if d.ObjectFit != "" {
+			props["objectFit"] = d.ObjectFit
+		}
+		if d.ObjectPosition != "" {
+			props["objectPosition"] = d.ObjectPosition
+		}
+	}
+	return style.NewStyles(props)
+}
+
+// Replay issues every op in the list against ctx
+func (l DisplayList) Replay(ctx node.RenderContext) {
+	for _, op := range l {
+		op.Replay(ctx)
+	}
+}
+
+// Replay issues a single op against ctx
+func (op DisplayOp) Replay(ctx node.RenderContext) {
+	switch op.Kind {
+	case OpClear:
+		ctx.Clear()
+	case OpSave:
+		ctx.Save()
+	case OpRestore:
+		ctx.Restore()
+	case OpSetOpacity:
+		ctx.SetOpacity(op.Opacity)
+	case OpSetFillColor:
+		ctx.SetFillColor(op.Color)
+	case OpSetStrokeColor:
+		ctx.SetStrokeColor(op.Color)
+	case OpSetLineWidth:
+		ctx.SetLineWidth(op.Value)
+	case OpSetFontSize:
+		ctx.SetFontSize(op.Value)
+	case OpScale:
+		ctx.Scale(op.ScaleX, op.ScaleY)
+	case OpStrokeLine:
+		ctx.StrokeLine(op.Start, op.End)
+	case OpFillRect:
+		ctx.FillRect(op.Bounds)
+	case OpDrawBackground:
+		ctx.DrawBackground(op.Bounds, op.Styles.toStyles(), op.Opacity)
+	case OpDrawBorders:
+		ctx.DrawBorders(op.Bounds, op.Styles.toStyles(), op.Opacity)
+	case OpDrawText:
+		ctx.DrawText(op.Text, op.Bounds, op.Styles.toStyles(), op.Opacity)
+	case OpDrawTexture:
+		ctx.DrawTexture(op.Source, op.Bounds, op.Styles.toStyles(), op.Opacity)
+	case OpSetClipRect:
+		ctx.SetClipRect(op.Bounds)
+	case OpPresent:
+		ctx.Present()
+	}
+}
+
+// Encode writes the display list as JSON
+func (l DisplayList) Encode(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(l)
+}
+
+// DecodeDisplayList reads a display list written by Encode
+func DecodeDisplayList(r io.Reader) (DisplayList, error) {
+	var list DisplayList
+	if err := json.NewDecoder(r).Decode(&list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// DisplayDiffKind describes how an op differs between two display lists
+type DisplayDiffKind string
+
+const (
+	DiffAdded   DisplayDiffKind = "added"
+	DiffRemoved DisplayDiffKind = "removed"
+	DiffChanged DisplayDiffKind = "changed"
+)
+
+// DisplayDiff is one difference between two display lists.
+// OldIndex and NewIndex are -1 when the op is absent from that side.
+type DisplayDiff struct {
+	Kind     DisplayDiffKind
+	OldIndex int
+	NewIndex int
+	Old      *DisplayOp
+	New      *DisplayOp
+}
+
+func (d DisplayDiff) String() string {
+	switch d.Kind {
+	case DiffAdded:
+		return fmt.Sprintf("+ [%d] %s %v", d.NewIndex, d.New.Kind, d.New.Bounds)
+	case DiffRemoved:
+		return fmt.Sprintf("- [%d] %s %v", d.OldIndex, d.Old.Kind, d.Old.Bounds)
+	default:
+		return fmt.Sprintf("~ [%d->%d] %s %v -> %s %v", d.OldIndex, d.NewIndex, d.Old.Kind, d.Old.Bounds, d.New.Kind, d.New.Bounds)
+	}
+}
+
+// Equal reports whether two ops draw the same thing
+func (op DisplayOp) Equal(other DisplayOp) bool {
+	return op.key() == other.key() && reflect.DeepEqual(op.Styles, other.Styles)
+}
+
+// key returns the op without its styles, which is comparable with == and cheap to hash
+func (op DisplayOp) key() DisplayOp {
+	op.Styles = nil
+	return op
+}
+
+// Diff compares two display lists and returns the ops that were added, removed or changed.
+// Ops are matched by their longest common subsequence, found in linear space. Between two
+// matched ops, a removal and an addition of the same op kind are reported as a change.
+func (l DisplayList) Diff(other DisplayList) []DisplayDiff {
+	// Skip the common prefix and suffix, which is most of a frame in practice
+	prefix := 0
+	for prefix < len(l) && prefix < len(other) && l[prefix].Equal(other[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(l)-prefix && suffix < len(other)-prefix &&
+		l[len(l)-1-suffix].Equal(other[len(other)-1-suffix]) {
+		suffix++
+	}
+	a := l[prefix : len(l)-suffix]
+	b := other[prefix : len(other)-suffix]
+
+	ids := internOps(a, b)
+	var matches []opMatch
+	matchOps(ids[0], ids[1], 0, 0, &matches)
+	// A final match past the end flushes the ops after the last real one
+	matches = append(matches, opMatch{old: len(a), new: len(b)})
+
+	var diffs []DisplayDiff
+	i, j := 0, 0
+	for _, match := range matches {
+		for i < match.old || j < match.new {
+			removals, additions := match.old-i, match.new-j
+			switch {
+			case removals > 0 && additions > 0 && a[i].Kind == b[j].Kind:
+				diffs = append(diffs, DisplayDiff{Kind: DiffChanged, OldIndex: prefix + i, NewIndex: prefix + j, Old: &a[i], New: &b[j]})
+				i++
+				j++
+			case removals > additions:
+				diffs = append(diffs, DisplayDiff{Kind: DiffRemoved, OldIndex: prefix + i, NewIndex: -1, Old: &a[i]})
+				i++
+			default:
+				diffs = append(diffs, DisplayDiff{Kind: DiffAdded, OldIndex: -1, NewIndex: prefix + j, New: &b[j]})
+				j++
+			}
+		}
+		i, j = match.old+1, match.new+1
+	}
+	return diffs
+}
+
+// opMatch pairs the indexes of an op that is in both lists
+type opMatch struct {
+	old int
+	new int
+}
+
+// internOps numbers the ops of each list so that equal ops get the same number.
+// Ops are grouped by their key first, so styles are only compared within a group.
+func internOps(lists ...[]DisplayOp) [][]int {
+	type interned struct {
+		styles *DisplayStyles
+		id     int
+	}
+	seen := make(map[DisplayOp][]interned)
+	next := 0
+	ids := make([][]int, len(lists))
+	for l, list := range lists {
+		ids[l] = make([]int, len(list))
+		for i, op := range list {
+			key := op.key()
+			id := -1
+			for _, entry := range seen[key] {
+				if reflect.DeepEqual(entry.styles, op.Styles) {
+					id = entry.id
+					break
+				}
+			}
+			if id < 0 {
+				id = next
+				next++
+				seen[key] = append(seen[key], interned{styles: op.Styles, id: id})
+			}
+			ids[l][i] = id
+		}
+	}
+	return ids
+}
+
+// matchOps appends the matches of a longest common subsequence of a and b, offset by aOff
+// and bOff, using Hirschberg's algorithm: a is split in half and b where the LCS lengths of
+// the halves add up to the most, and each side is matched on its own
+func matchOps(a, b []int, aOff, bOff int, matches *[]opMatch) {
+	if len(a) == 0 || len(b) == 0 {
+		return
+	}
+	if len(a) == 1 {
+		for j, id := range b {
+			if id == a[0] {
+				*matches = append(*matches, opMatch{old: aOff, new: bOff + j})
+				return
+			}
+		}
+		return
+	}
+
+	mid := len(a) / 2
+	forward := lcsLengths(a[:mid], b, false)
+	backward := lcsLengths(a[mid:], b, true)
+	split := 0
+	for j := 1; j <= len(b); j++ {
+		if forward[j]+backward[len(b)-j] > forward[split]+backward[len(b)-split] {
+			split = j
+		}
+	}
+	matchOps(a[:mid], b[:split], aOff, bOff, matches)
+	matchOps(a[mid:], b[split:], aOff+mid, bOff+split, matches)
+}
+
+// lcsLengths returns the LCS lengths of a and the first j ids of b for every j, keeping only
+// one row of the table. With reverse set both are read from the end, giving the lengths for
+// the last j ids.
+func lcsLengths(a, b []int, reverse bool) []int {
+	prev := make([]int, len(b)+1)
+	row := make([]int, len(b)+1)
+	for i := range a {
+		ai := a[i]
+		if reverse {
+			ai = a[len(a)-1-i]
+		}
+		for j := 1; j <= len(b); j++ {
+			bj := b[j-1]
+			if reverse {
+				bj = b[len(b)-j]
+			}
+			if ai == bj {
+				row[j] = prev[j-1] + 1
+			} else {
+				row[j] = max(prev[j], row[j-1])
+			}
+		}
+		prev, row = row, prev
+	}
+	return prev
+}
+
+// DiffDisplayLists compares two display lists, see DisplayList.Diff
+func DiffDisplayLists(before, after DisplayList) []DisplayDiff {
+	return before.Diff(after)
+}
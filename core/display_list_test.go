@@ -0,0 +1,159 @@
+package core
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/noahdw/goui/node/style"
+)
+
+// fill returns a fillRect op at x
+func fill(x float64) DisplayOp {
+	return DisplayOp{Kind: OpFillRect, Bounds: style.Rect{Position: style.Point{X: x}, Size: style.Size{Width: 10, Height: 10}}}
+}
+
+// line returns a strokeLine op to x
+func line(x float64) DisplayOp {
+	return DisplayOp{Kind: OpStrokeLine, End: style.Point{X: x}}
+}
+
+func TestDiff(t *testing.T) {
+	clear, present := DisplayOp{Kind: OpClear}, DisplayOp{Kind: OpPresent}
+	tests := []struct {
+		name   string
+		before DisplayList
+		after  DisplayList
+		want   []DisplayDiff
+	}{
+		{
+			name:   "equal",
+			before: DisplayList{clear, fill(1), present},
+			after:  DisplayList{clear, fill(1), present},
+		},
+		{
+			name:   "changed between a common prefix and suffix",
+			before: DisplayList{clear, fill(1), fill(2), fill(3), present},
+			after:  DisplayList{clear, fill(1), fill(5), fill(3), present},
+			want:   []DisplayDiff{{Kind: DiffChanged, OldIndex: 2, NewIndex: 2}},
+		},
+		{
+			name:   "added",
+			before: DisplayList{clear, fill(1), present},
+			after:  DisplayList{clear, fill(1), fill(2), present},
+			want:   []DisplayDiff{{Kind: DiffAdded, OldIndex: -1, NewIndex: 2}},
+		},
+		{
+			name:   "removed",
+			before: DisplayList{clear, fill(1), fill(2), present},
+			after:  DisplayList{clear, fill(2), present},
+			want:   []DisplayDiff{{Kind: DiffRemoved, OldIndex: 1, NewIndex: -1}},
+		},
+		{
+			name:   "another kind is added and removed",
+			before: DisplayList{clear, fill(1), present},
+			after:  DisplayList{clear, line(1), present},
+			want: []DisplayDiff{
+				{Kind: DiffAdded, OldIndex: -1, NewIndex: 1},
+				{Kind: DiffRemoved, OldIndex: 1, NewIndex: -1},
+			},
+		},
+		{
+			name:   "moved past unchanged ops",
+			before: DisplayList{fill(1), fill(2), fill(3)},
+			after:  DisplayList{fill(2), fill(3), fill(1)},
+			want: []DisplayDiff{
+				{Kind: DiffRemoved, OldIndex: 0, NewIndex: -1},
+				{Kind: DiffAdded, OldIndex: -1, NewIndex: 2},
+			},
+		},
+		{
+			name:  "from empty",
+			after: DisplayList{clear, present},
+			want:  []DisplayDiff{{Kind: DiffAdded, OldIndex: -1, NewIndex: 0}, {Kind: DiffAdded, OldIndex: -1, NewIndex: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs := tt.before.Diff(tt.after)
+			if len(diffs) != len(tt.want) {
+				t.Fatalf("got %d diffs %v, want %d", len(diffs), diffs, len(tt.want))
+			}
+			for i, diff := range diffs {
+				want := tt.want[i]
+				if diff.Kind != want.Kind || diff.OldIndex != want.OldIndex || diff.NewIndex != want.NewIndex {
+					t.Errorf("diff %d is %v, want %s from %d to %d", i, diff, want.Kind, want.OldIndex, want.NewIndex)
+				}
+				// The ops point into the lists at their indexes
+				if diff.OldIndex >= 0 && diff.Old != &tt.before[diff.OldIndex] {
+					t.Errorf("diff %d: old op is not before[%d]", i, diff.OldIndex)
+				}
+				if diff.NewIndex >= 0 && diff.New != &tt.after[diff.NewIndex] {
+					t.Errorf("diff %d: new op is not after[%d]", i, diff.NewIndex)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffComparesStyles(t *testing.T) {
+	red, blue := style.Red, style.Blue
+	op := func(c *style.Color) DisplayOp {
+		return DisplayOp{Kind: OpDrawBackground, Styles: &DisplayStyles{Background: c}, Opacity: 1}
+	}
+	if diffs := (DisplayList{op(&red)}).Diff(DisplayList{op(&red)}); len(diffs) != 0 {
+		t.Errorf("equal styles behind different pointers: got %v, want no diffs", diffs)
+	}
+	diffs := (DisplayList{op(&red)}).Diff(DisplayList{op(&blue)})
+	if len(diffs) != 1 || diffs[0].Kind != DiffChanged {
+		t.Errorf("got %v, want one change", diffs)
+	}
+}
+
+func TestEncodeDecodeReplay(t *testing.T) {
+	padding := style.EdgeInsets{Top: 1, Right: 2, Bottom: 3, Left: 4}
+	styles := style.NewStyles(map[string]interface{}{
+		"background": style.Blue,
+		"color":      style.White,
+		"padding":    padding,
+		"fontSize":   20,
+		"textAlign":  "center",
+		"direction":  "rtl",
+	})
+	bounds := style.Rect{Position: style.Point{X: 5, Y: 6}, Size: style.Size{Width: 100, Height: 40}}
+
+	recorder := NewRecordingRenderContext(nil)
+	recorder.Clear()
+	recorder.Save()
+	recorder.SetOpacity(0.5)
+	recorder.SetFillColor(style.Red)
+	recorder.SetLineWidth(2)
+	recorder.Scale(2, 3)
+	recorder.StrokeLine(style.Point{X: 1, Y: 2}, style.Point{X: 3, Y: 4})
+	recorder.FillRect(bounds)
+	recorder.SetClipRect(bounds)
+	recorder.DrawBackground(bounds, styles, 0.75)
+	recorder.DrawText("hello", bounds, styles, 1)
+	recorder.Restore()
+	recorder.Present()
+	list := recorder.DisplayList()
+
+	var buf bytes.Buffer
+	if err := list.Encode(&buf); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	decoded, err := DecodeDisplayList(&buf)
+	if err != nil {
+		t.Fatalf("DecodeDisplayList: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, list) {
+		t.Fatalf("decoded list differs:\n got %+v\nwant %+v", decoded, list)
+	}
+
+	// Replaying the decoded list makes the same calls again
+	replayed := NewRecordingRenderContext(nil)
+	decoded.Replay(replayed)
+	if diffs := list.Diff(replayed.DisplayList()); len(diffs) != 0 {
+		t.Errorf("replay differs: %v", diffs)
+	}
+}
@@ -0,0 +1,129 @@
+package effects
+
+import (
+	"image"
+	"math"
+)
+
+// Blend composites src over dst at the given offset using a mix-blend-mode,
+// following the separable blend modes of the W3C compositing specification.
+// Unknown modes blend as "normal".
+func Blend(dst *image.NRGBA, src *image.NRGBA, at image.Point, mode string) {
+	blend, ok := blendFuncs[mode]
+	if !ok {
+		blend = blendFuncs["normal"]
+	}
+
+	dstWidth, dstHeight := dst.Rect.Dx(), dst.Rect.Dy()
+	srcWidth, srcHeight := src.Rect.Dx(), src.Rect.Dy()
+
+	for y := 0; y < srcHeight; y++ {
+		dy := y + at.Y
+		if dy < 0 || dy >= dstHeight {
+			continue
+		}
+		for x := 0; x < srcWidth; x++ {
+			dx := x + at.X
+			if dx < 0 || dx >= dstWidth {
+				continue
+			}
+
+			si := (y*srcWidth + x) * 4
+			di := (dy*dstWidth + dx) * 4
+			as := float64(src.Pix[si+3]) / 255
+			if as == 0 {
+				continue
+			}
+			ab := float64(dst.Pix[di+3]) / 255
+			ao := as + ab*(1-as)
+
+			for c := 0; c < 3; c++ {
+				cs := float64(src.Pix[si+c]) / 255
+				cb := float64(dst.Pix[di+c]) / 255
+
+				// Co = as*(1-ab)*Cs + as*ab*B(Cb, Cs) + (1-as)*ab*Cb, then un-premultiply
+				co := as*(1-ab)*cs + as*ab*blend(cb, cs) + (1-as)*ab*cb
+				dst.Pix[di+c] = toByte(co / ao)
+			}
+			dst.Pix[di+3] = toByte(ao)
+		}
+	}
+}
+
+// BlendChannel returns the blended value of a single backdrop and source channel
+func BlendChannel(mode string, backdrop, source float64) float64 {
+	blend, ok := blendFuncs[mode]
+	if !ok {
+		blend = blendFuncs["normal"]
+	}
+	return blend(backdrop, source)
+}
+
+// blendFuncs maps each blend mode to its separable blend function B(Cb, Cs)
+var blendFuncs = map[string]func(cb, cs float64) float64{
+	"normal": func(cb, cs float64) float64 {
+		return cs
+	},
+	"multiply": func(cb, cs float64) float64 {
+		return cb * cs
+	},
+	"screen": screen,
+	"overlay": func(cb, cs float64) float64 {
+		return hardLight(cs, cb)
+	},
+	"darken":  math.Min,
+	"lighten": math.Max,
+	"color-dodge": func(cb, cs float64) float64 {
+		if cb == 0 {
+			return 0
+		}
+		if cs >= 1 {
+			return 1
+		}
+		return math.Min(1, cb/(1-cs))
+	},
+	"color-burn": func(cb, cs float64) float64 {
+		if cb >= 1 {
+			return 1
+		}
+		if cs <= 0 {
+			return 0
+		}
+		return 1 - math.Min(1, (1-cb)/cs)
+	},
+	"hard-light": hardLight,
+	"soft-light": func(cb, cs float64) float64 {
+		if cs <= 0.5 {
+			return cb - (1-2*cs)*cb*(1-cb)
+		}
+		var d float64
+		if cb <= 0.25 {
+			d = ((16*cb-12)*cb + 4) * cb
+		} else {
+			d = math.Sqrt(cb)
+		}
+		return cb + (2*cs-1)*(d-cb)
+	},
+	"difference": func(cb, cs float64) float64 {
+		return math.Abs(cb - cs)
+	},
+	"exclusion": func(cb, cs float64) float64 {
+		return cb + cs - 2*cb*cs
+	},
+	"plus-lighter": func(cb, cs float64) float64 {
+		return math.Min(1, cb+cs)
+	},
+}
+
+// screen is the complement of multiplying the complements
+func screen(cb, cs float64) float64 {
+	return cb + cs - cb*cs
+}
+
+// hardLight multiplies or screens depending on the source
+func hardLight(cb, cs float64) float64 {
+	if cs <= 0.5 {
+		return cb * 2 * cs
+	}
+	return screen(cb, 2*cs-1)
+}
@@ -0,0 +1,252 @@
+// Package effects implements layer filters and blend modes on the CPU.
+// It is the reference for the shader implementation in core and the fallback
+// used when shaders are unavailable. It has no dependency on raylib, so the
+// results can be checked headlessly.
+//
+// Images are expected to start at the origin with no padding between rows,
+// as created by image.NewNRGBA(image.Rect(0, 0, width, height)).
+package effects
+
+import (
+	"image"
+	"math"
+
+	"github.com/noahdw/goui/node/style"
+)
+
+// Apply runs the filters over img in order and returns the result.
+// Blur and drop-shadow spread outside the content, so img should be padded
+// by style.FilterOutset for the result not to be cut off.
+func Apply(img *image.NRGBA, filters []style.Filter) *image.NRGBA {
+	for _, f := range filters {
+		switch f.Function {
+		case "blur":
+			img = Blur(img, f.Amount)
+		case "brightness":
+			img = Brightness(img, f.Amount)
+		case "contrast":
+			img = Contrast(img, f.Amount)
+		case "grayscale":
+			img = Grayscale(img, f.Amount)
+		case "drop-shadow":
+			img = DropShadow(img, f.Shadow)
+		}
+	}
+	return img
+}
+
+// Brightness multiplies the color channels by amount
+func Brightness(img *image.NRGBA, amount float64) *image.NRGBA {
+	return mapColors(img, func(r, g, b float64) (float64, float64, float64) {
+		return r * amount, g * amount, b * amount
+	})
+}
+
+// Contrast scales the color channels away from or towards mid gray by amount
+func Contrast(img *image.NRGBA, amount float64) *image.NRGBA {
+	return mapColors(img, func(r, g, b float64) (float64, float64, float64) {
+		return (r-0.5)*amount + 0.5, (g-0.5)*amount + 0.5, (b-0.5)*amount + 0.5
+	})
+}
+
+// Grayscale mixes the colors towards their luminance, amount 1 being fully gray
+func Grayscale(img *image.NRGBA, amount float64) *image.NRGBA {
+	amount = clamp01(amount)
+	return mapColors(img, func(r, g, b float64) (float64, float64, float64) {
+		luma := 0.2126*r + 0.7152*g + 0.0722*b
+		return r + (luma-r)*amount, g + (luma-g)*amount, b + (luma-b)*amount
+	})
+}
+
+// mapColors applies fn to the straight color channels of every pixel, leaving alpha untouched
+func mapColors(img *image.NRGBA, fn func(r, g, b float64) (float64, float64, float64)) *image.NRGBA {
+	out := image.NewNRGBA(img.Rect)
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		r, g, b := fn(
+			float64(img.Pix[i])/255,
+			float64(img.Pix[i+1])/255,
+			float64(img.Pix[i+2])/255,
+		)
+		out.Pix[i] = toByte(r)
+		out.Pix[i+1] = toByte(g)
+		out.Pix[i+2] = toByte(b)
+		out.Pix[i+3] = img.Pix[i+3]
+	}
+	return out
+}
+
+// Blur applies a gaussian blur with the given standard deviation in pixels.
+// The gaussian is approximated by three successive box blurs, done on
+// premultiplied colors so transparent pixels do not darken the edges.
+func Blur(img *image.NRGBA, sigma float64) *image.NRGBA {
+	if sigma <= 0 {
+		return cloneImage(img)
+	}
+
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	buf := premultiply(img)
+	tmp := make([]float64, len(buf))
+	for _, box := range boxSizes(sigma, 3) {
+		radius := (box - 1) / 2
+		boxBlurHorizontal(buf, tmp, width, height, radius)
+		boxBlurVertical(tmp, buf, width, height, radius)
+	}
+	return unpremultiply(buf, img.Rect)
+}
+
+// boxSizes returns the widths of n box blurs that together approximate a gaussian
+func boxSizes(sigma float64, n int) []int {
+	ideal := math.Sqrt(12*sigma*sigma/float64(n) + 1)
+	lower := int(math.Floor(ideal))
+	if lower%2 == 0 {
+		lower--
+	}
+	upper := lower + 2
+
+	m := math.Round((12*sigma*sigma - float64(n*lower*lower) - float64(4*n*lower) - float64(3*n)) / float64(-4*lower-4))
+	sizes := make([]int, n)
+	for i := range sizes {
+		if float64(i) < m {
+			sizes[i] = lower
+		} else {
+			sizes[i] = upper
+		}
+	}
+	return sizes
+}
+
+// boxBlurHorizontal averages each pixel with its neighbours on the same row
+func boxBlurHorizontal(src, dst []float64, width, height, radius int) {
+	scale := 1 / float64(2*radius+1)
+	for y := 0; y < height; y++ {
+		row := y * width * 4
+		for c := 0; c < 4; c++ {
+			sum := 0.0
+			for x := -radius; x <= radius; x++ {
+				sum += sampleRow(src, row, width, x, c)
+			}
+			for x := 0; x < width; x++ {
+				dst[row+x*4+c] = sum * scale
+				sum += sampleRow(src, row, width, x+radius+1, c) - sampleRow(src, row, width, x-radius, c)
+			}
+		}
+	}
+}
+
+// boxBlurVertical averages each pixel with its neighbours in the same column
+func boxBlurVertical(src, dst []float64, width, height, radius int) {
+	scale := 1 / float64(2*radius+1)
+	for x := 0; x < width; x++ {
+		for c := 0; c < 4; c++ {
+			sum := 0.0
+			for y := -radius; y <= radius; y++ {
+				sum += sampleColumn(src, x, width, height, y, c)
+			}
+			for y := 0; y < height; y++ {
+				dst[(y*width+x)*4+c] = sum * scale
+				sum += sampleColumn(src, x, width, height, y+radius+1, c) - sampleColumn(src, x, width, height, y-radius, c)
+			}
+		}
+	}
+}
+
+// sampleRow returns a channel of the pixel at x, treating pixels outside the row as transparent
+func sampleRow(buf []float64, row, width, x, c int) float64 {
+	if x < 0 || x >= width {
+		return 0
+	}
+	return buf[row+x*4+c]
+}
+
+// sampleColumn returns a channel of the pixel at y, treating pixels outside the column as transparent
+func sampleColumn(buf []float64, x, width, height, y, c int) float64 {
+	if y < 0 || y >= height {
+		return 0
+	}
+	return buf[(y*width+x)*4+c]
+}
+
+// DropShadow draws the image over a blurred, offset copy of its silhouette in the shadow color.
+// The shadow blur radius is twice the standard deviation, as for CSS box shadows.
+func DropShadow(img *image.NRGBA, shadow style.ShadowStyle) *image.NRGBA {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	offsetX := int(math.Round(shadow.OffsetX))
+	offsetY := int(math.Round(shadow.OffsetY))
+
+	// Build the shadow from the source alpha, shifted by the offset
+	silhouette := image.NewNRGBA(img.Rect)
+	for y := 0; y < height; y++ {
+		sy := y - offsetY
+		if sy < 0 || sy >= height {
+			continue
+		}
+		for x := 0; x < width; x++ {
+			sx := x - offsetX
+			if sx < 0 || sx >= width {
+				continue
+			}
+			alpha := float64(img.Pix[(sy*width+sx)*4+3]) / 255 * float64(shadow.Color.A) / 255
+			i := (y*width + x) * 4
+			silhouette.Pix[i] = shadow.Color.R
+			silhouette.Pix[i+1] = shadow.Color.G
+			silhouette.Pix[i+2] = shadow.Color.B
+			silhouette.Pix[i+3] = toByte(alpha)
+		}
+	}
+
+	out := Blur(silhouette, shadow.BlurRadius/2)
+	Blend(out, img, image.Point{}, "normal")
+	return out
+}
+
+// premultiply converts an image to premultiplied channels in the 0-1 range
+func premultiply(img *image.NRGBA) []float64 {
+	buf := make([]float64, len(img.Pix))
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		alpha := float64(img.Pix[i+3]) / 255
+		buf[i] = float64(img.Pix[i]) / 255 * alpha
+		buf[i+1] = float64(img.Pix[i+1]) / 255 * alpha
+		buf[i+2] = float64(img.Pix[i+2]) / 255 * alpha
+		buf[i+3] = alpha
+	}
+	return buf
+}
+
+// unpremultiply converts premultiplied channels back to an image
+func unpremultiply(buf []float64, rect image.Rectangle) *image.NRGBA {
+	out := image.NewNRGBA(rect)
+	for i := 0; i+3 < len(buf); i += 4 {
+		alpha := buf[i+3]
+		if alpha <= 0 {
+			continue
+		}
+		out.Pix[i] = toByte(buf[i] / alpha)
+		out.Pix[i+1] = toByte(buf[i+1] / alpha)
+		out.Pix[i+2] = toByte(buf[i+2] / alpha)
+		out.Pix[i+3] = toByte(alpha)
+	}
+	return out
+}
+
+// cloneImage returns a copy of img
+func cloneImage(img *image.NRGBA) *image.NRGBA {
+	out := image.NewNRGBA(img.Rect)
+	copy(out.Pix, img.Pix)
+	return out
+}
+
+// toByte converts a 0-1 channel value to a byte, clamping out of range values
+func toByte(v float64) uint8 {
+	return uint8(math.Round(clamp01(v) * 255))
+}
+
+// clamp01 clamps v to the 0-1 range
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
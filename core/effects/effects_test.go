@@ -0,0 +1,166 @@
+package effects
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/noahdw/goui/node/style"
+)
+
+// solid returns a width by height image filled with c
+func solid(width, height int, c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestColorFilters(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter func(*image.NRGBA) *image.NRGBA
+		in     color.NRGBA
+		want   color.NRGBA
+	}{
+		{"brightness halves", func(img *image.NRGBA) *image.NRGBA { return Brightness(img, 0.5) }, color.NRGBA{200, 100, 50, 255}, color.NRGBA{100, 50, 25, 255}},
+		{"brightness clamps", func(img *image.NRGBA) *image.NRGBA { return Brightness(img, 2) }, color.NRGBA{200, 100, 50, 255}, color.NRGBA{255, 200, 100, 255}},
+		{"brightness keeps alpha", func(img *image.NRGBA) *image.NRGBA { return Brightness(img, 1) }, color.NRGBA{200, 100, 50, 128}, color.NRGBA{200, 100, 50, 128}},
+		{"contrast 0 is mid gray", func(img *image.NRGBA) *image.NRGBA { return Contrast(img, 0) }, color.NRGBA{200, 100, 50, 255}, color.NRGBA{128, 128, 128, 255}},
+		{"contrast 1 is unchanged", func(img *image.NRGBA) *image.NRGBA { return Contrast(img, 1) }, color.NRGBA{200, 100, 50, 255}, color.NRGBA{200, 100, 50, 255}},
+		{"contrast 2 stretches", func(img *image.NRGBA) *image.NRGBA { return Contrast(img, 2) }, color.NRGBA{200, 50, 200, 255}, color.NRGBA{255, 0, 255, 255}},
+		{"grayscale 1 is luminance", func(img *image.NRGBA) *image.NRGBA { return Grayscale(img, 1) }, color.NRGBA{255, 0, 0, 255}, color.NRGBA{54, 54, 54, 255}},
+		{"grayscale 0 is unchanged", func(img *image.NRGBA) *image.NRGBA { return Grayscale(img, 0) }, color.NRGBA{255, 0, 0, 255}, color.NRGBA{255, 0, 0, 255}},
+		{"grayscale clamps amount", func(img *image.NRGBA) *image.NRGBA { return Grayscale(img, 3) }, color.NRGBA{0, 255, 0, 255}, color.NRGBA{182, 182, 182, 255}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := tt.filter(solid(2, 2, tt.in))
+			if got := out.NRGBAAt(1, 1); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlur(t *testing.T) {
+	red := color.NRGBA{255, 0, 0, 255}
+
+	t.Run("zero sigma copies", func(t *testing.T) {
+		img := solid(3, 3, red)
+		out := Blur(img, 0)
+		if out == img || out.NRGBAAt(1, 1) != red {
+			t.Errorf("got %v, want a copy of %v", out.NRGBAAt(1, 1), red)
+		}
+	})
+
+	t.Run("uniform center is unchanged", func(t *testing.T) {
+		out := Blur(solid(21, 21, red), 1)
+		if got := out.NRGBAAt(10, 10); got != red {
+			t.Errorf("got %v, want %v", got, red)
+		}
+	})
+
+	t.Run("spreads without darkening", func(t *testing.T) {
+		img := image.NewNRGBA(image.Rect(0, 0, 9, 9))
+		img.SetNRGBA(4, 4, red)
+		out := Blur(img, 1)
+
+		center, neighbour := out.NRGBAAt(4, 4), out.NRGBAAt(5, 4)
+		if center.A == 0 || center.A == 255 || neighbour.A == 0 || neighbour.A > center.A {
+			t.Errorf("alpha center %d, neighbour %d: want a peak that falls off", center.A, neighbour.A)
+		}
+		if neighbour.R != 255 || neighbour.G != 0 || neighbour.B != 0 {
+			t.Errorf("neighbour color %v: transparent pixels darkened the edge", neighbour)
+		}
+	})
+}
+
+func TestDropShadow(t *testing.T) {
+	white := color.NRGBA{255, 255, 255, 255}
+	img := image.NewNRGBA(image.Rect(0, 0, 6, 6))
+	img.SetNRGBA(1, 1, white)
+
+	out := DropShadow(img, style.ShadowStyle{OffsetX: 2, OffsetY: 3, Color: style.Color{R: 10, G: 20, B: 30, A: 255}})
+
+	tests := []struct {
+		name string
+		x, y int
+		want color.NRGBA
+	}{
+		{"source on top", 1, 1, white},
+		{"offset shadow", 3, 4, color.NRGBA{10, 20, 30, 255}},
+	}
+	for _, tt := range tests {
+		if got := out.NRGBAAt(tt.x, tt.y); got != tt.want {
+			t.Errorf("%s: pixel %d,%d is %v, want %v", tt.name, tt.x, tt.y, got, tt.want)
+		}
+	}
+	if got := out.NRGBAAt(4, 4); got.A != 0 {
+		t.Errorf("pixel 4,4 away from the source and shadow is %v, want transparent", got)
+	}
+}
+
+func TestApplyRunsFiltersInOrder(t *testing.T) {
+	img := solid(2, 2, color.NRGBA{100, 100, 100, 255})
+	out := Apply(img, []style.Filter{
+		{Function: "brightness", Amount: 2},
+		{Function: "contrast", Amount: 0},
+	})
+	if got, want := out.NRGBAAt(0, 0), (color.NRGBA{128, 128, 128, 255}); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestBlend(t *testing.T) {
+	backdrop := color.NRGBA{200, 100, 50, 255}
+	tests := []struct {
+		mode string
+		src  color.NRGBA
+		want color.NRGBA
+	}{
+		{"normal", color.NRGBA{100, 150, 50, 255}, color.NRGBA{100, 150, 50, 255}},
+		{"normal", color.NRGBA{0, 0, 0, 128}, color.NRGBA{100, 50, 25, 255}},
+		{"multiply", color.NRGBA{100, 255, 0, 255}, color.NRGBA{78, 100, 0, 255}},
+		{"screen", color.NRGBA{100, 0, 255, 255}, color.NRGBA{222, 100, 255, 255}},
+		{"darken", color.NRGBA{100, 150, 50, 255}, color.NRGBA{100, 100, 50, 255}},
+		{"lighten", color.NRGBA{100, 150, 50, 255}, color.NRGBA{200, 150, 50, 255}},
+		{"difference", color.NRGBA{100, 150, 50, 255}, color.NRGBA{100, 50, 0, 255}},
+		{"darken", color.NRGBA{0, 0, 0, 0}, backdrop},
+		{"multiply", color.NRGBA{0, 0, 0, 0}, backdrop},
+		{"unknown", color.NRGBA{100, 150, 50, 255}, color.NRGBA{100, 150, 50, 255}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			dst := solid(1, 1, backdrop)
+			Blend(dst, solid(1, 1, tt.src), image.Point{}, tt.mode)
+			if got := dst.NRGBAAt(0, 0); got != tt.want {
+				t.Errorf("%v over %v: got %v, want %v", tt.src, backdrop, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlendOffset(t *testing.T) {
+	backdrop := color.NRGBA{200, 100, 50, 255}
+	dst := solid(3, 3, backdrop)
+	src := solid(2, 2, color.NRGBA{0, 0, 255, 255})
+	Blend(dst, src, image.Point{X: 2, Y: -1}, "normal")
+
+	tests := []struct {
+		x, y int
+		want color.NRGBA
+	}{
+		{2, 0, color.NRGBA{0, 0, 255, 255}},
+		{1, 0, backdrop},
+		{2, 1, backdrop},
+	}
+	for _, tt := range tests {
+		if got := dst.NRGBAAt(tt.x, tt.y); got != tt.want {
+			t.Errorf("pixel %d,%d is %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
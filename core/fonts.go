@@ -0,0 +1,77 @@
+package core
+
+import (
+	"math"
+	"os"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// sansSerifFonts are the system fonts tried, in order, for families that are not a font file
+var sansSerifFonts = []string{
+	"C:/Windows/Fonts/segoeui.ttf",
+	"C:/Windows/Fonts/arial.ttf",
+	"/System/Library/Fonts/Supplemental/Arial.ttf",
+	"/Library/Fonts/Arial.ttf",
+	"/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf",
+	"/usr/share/fonts/TTF/DejaVuSans.ttf",
+	"/usr/share/fonts/dejavu/DejaVuSans.ttf",
+}
+
+// fontKey identifies a font file rasterized at a size in physical pixels
+type fontKey struct {
+	path string
+	size int32
+}
+
+// fontPath returns the file to load for a font family. A family ending in .ttf or .otf is
+// a file, anything else uses the first system sans-serif font found. It returns "" if
+// there is none, in which case raylib's built-in font is used.
+func fontPath(family string) string {
+	lower := strings.ToLower(family)
+	if strings.HasSuffix(lower, ".ttf") || strings.HasSuffix(lower, ".otf") {
+		return family
+	}
+	for _, path := range sansSerifFonts {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// font returns the font to draw a family with at fontSize logical units, with the size and
+// spacing to draw it at in physical pixels. Fonts are rasterized at the physical size, so
+// text stays sharp on HiDPI screens, and kept for later frames.
+func (r *RaylibRenderContext) font(family string, fontSize float64) (font rl.Font, size, spacing float32) {
+	size = float32(math.Round(fontSize * r.deviceScale))
+	path := fontPath(family)
+	if path == "" || size <= 0 {
+		// The built-in font is a 10px bitmap, spaced like rl.DrawText does
+		return rl.GetFontDefault(), size, float32(math.Max(1, math.Floor(float64(size)/10)))
+	}
+
+	key := fontKey{path: path, size: int32(size)}
+	if loaded, ok := r.fonts[key]; ok {
+		return loaded, size, 0
+	}
+	font = rl.LoadFontEx(path, key.size, nil)
+	if font.Texture.ID == 0 {
+		font = rl.GetFontDefault()
+	} else {
+		rl.SetTextureFilter(font.Texture, rl.FilterBilinear)
+	}
+	r.fonts[key] = font
+	return font, size, 0
+}
+
+// unloadFonts unloads the fonts loaded for drawing text
+func (r *RaylibRenderContext) unloadFonts() {
+	for _, font := range r.fonts {
+		if font.Texture.ID != rl.GetFontDefault().Texture.ID {
+			rl.UnloadFont(font)
+		}
+	}
+	r.fonts = make(map[fontKey]rl.Font)
+}
@@ -1,77 +1,113 @@
-package core
-
-import (
-	. "github.com/noahdw/goui/node"
-	"github.com/noahdw/goui/node/style"
-)
-
-// LayoutManager handles the layout calculation and management
-type LayoutManager struct {
-	rootNode      Node
-	renderContext RenderContext
-	windowWidth   float64
-	windowHeight  float64
-	needsLayout   bool
-}
-
-// NewLayoutManager creates a new layout manager
-func NewLayoutManager(root Node, context RenderContext, width, height float64) *LayoutManager {
-	return &LayoutManager{
-		rootNode:      root,
-		renderContext: context,
-		windowWidth:   width,
-		windowHeight:  height,
-		needsLayout:   true,
-	}
-}
-
-// UpdateLayout performs a complete layout pass if needed
-func (l *LayoutManager) UpdateLayout() bool {
-	if !l.needsLayout {
-		return false
-	}
-
-	// Pass 1: Resolve styles
-	l.rootNode.ResolveStyles(style.NewStyles(make(map[string]interface{})))
-
-	// Pass 2: Measure preferred sizes
-	l.rootNode.MeasurePreferred(l.renderContext)
-
-	// Pass 3: Apply constraints and layout
-	viewport := Constraints{
-		MinWidth:  0,
-		MaxWidth:  l.windowWidth,
-		MinHeight: 0,
-		MaxHeight: l.windowHeight,
-	}
-	finalSize := l.rootNode.Layout(l.renderContext, viewport)
-
-	// Pass 4: Position elements
-	bounds := style.Rect{
-		Position: style.Point{X: 0, Y: 0},
-		Size:     finalSize,
-	}
-	l.rootNode.ArrangeChildren(l.renderContext, bounds)
-
-	l.needsLayout = false
-	return true
-}
-
-// MarkDirty marks the layout as needing recalculation
-func (l *LayoutManager) MarkDirty() {
-	l.needsLayout = true
-}
-
-// UpdateWindowSize updates the window dimensions
-func (l *LayoutManager) UpdateWindowSize(width, height float64) {
-	if l.windowWidth != width || l.windowHeight != height {
-		l.windowWidth = width
-		l.windowHeight = height
-		l.needsLayout = true
-	}
-}
-
-// GetWindowSize returns the current window dimensions
-func (l *LayoutManager) GetWindowSize() (width, height float64) {
-	return l.windowWidth, l.windowHeight
-}
+package core
+
+import (
+	. "github.com/noahdw/goui/node"
+	"github.com/noahdw/goui/node/style"
+)
+
+// LayoutManager handles the layout calculation and management
+type LayoutManager struct {
+	rootNode      Node
+	renderContext RenderContext
+	windowWidth   float64
+	windowHeight  float64
+	needsLayout   bool
+	layoutWorkers int // Goroutines laying out independent subtrees, 1 or less lays out serially
+}
+
+// NewLayoutManager creates a new layout manager
+func NewLayoutManager(root Node, context RenderContext, width, height float64) *LayoutManager {
+	return &LayoutManager{
+		rootNode:      root,
+		renderContext: context,
+		windowWidth:   width,
+		windowHeight:  height,
+		needsLayout:   true,
+	}
+}
+
+// UpdateLayout performs a layout pass if needed. Nodes whose styles and subtree have not
+// changed keep their resolved styles and reuse their cached sizes and positions.
+func (l *LayoutManager) UpdateLayout() bool {
+	if !l.NeedsLayout() {
+		return false
+	}
+
+	// Pass 1: Resolve styles, with breakpoints evaluated against the window
+	window := style.NewStyles(make(map[string]interface{}))
+	window.SetViewport(style.Size{Width: l.windowWidth, Height: l.windowHeight})
+	l.rootNode.ResolveStyles(window)
+
+	// Pass 2: Measure preferred sizes
+	l.rootNode.MeasurePreferred(l.renderContext)
+
+	// Pass 3: Apply constraints and layout
+	viewport := Constraints{
+		MinWidth:  0,
+		MaxWidth:  l.windowWidth,
+		MinHeight: 0,
+		MaxHeight: l.windowHeight,
+	}
+	finalSize := l.rootNode.Layout(l.layoutContext(), viewport)
+
+	// Pass 4: Position elements
+	bounds := style.Rect{
+		Position: style.Point{X: 0, Y: 0},
+		Size:     finalSize,
+	}
+	l.rootNode.ArrangeChildren(l.layoutContext(), bounds)
+
+	l.needsLayout = false
+	return true
+}
+
+// SetParallelLayout lays out and arranges subtrees with a fixed width and height on up to
+// workers goroutines. The result is the same as laying them out serially, which a worker
+// count of 1 or less does.
+func (l *LayoutManager) SetParallelLayout(workers int) {
+	l.layoutWorkers = workers
+}
+
+// layoutContext returns the render context to lay out with
+func (l *LayoutManager) layoutContext() RenderContext {
+	if l.layoutWorkers > 1 {
+		return ParallelLayout{RenderContext: l.renderContext, Workers: l.layoutWorkers}
+	}
+	return l.renderContext
+}
+
+// MarkDirty marks the layout as needing a pass, which only lays out the nodes marked dirty
+func (l *LayoutManager) MarkDirty() {
+	l.needsLayout = true
+}
+
+// NeedsLayout returns true if a layout pass is pending
+func (l *LayoutManager) NeedsLayout() bool {
+	return l.needsLayout || l.rootNode.NeedsLayout()
+}
+
+// UpdateWindowSize updates the window dimensions
+func (l *LayoutManager) UpdateWindowSize(width, height float64) {
+	if l.windowWidth != width || l.windowHeight != height {
+		l.windowWidth = width
+		l.windowHeight = height
+		l.needsLayout = true
+
+		// Breakpoints, percentages and fixed nodes anywhere in the tree can depend on the window
+		markSubtreeDirty(l.rootNode)
+	}
+}
+
+// markSubtreeDirty marks every node in the subtree as needing to be restyled and laid out
+func markSubtreeDirty(node Node) {
+	node.MarkStyleDirty()
+	node.MarkLayoutDirty()
+	for _, child := range node.Children() {
+		markSubtreeDirty(child)
+	}
+}
+
+// GetWindowSize returns the current window dimensions
+func (l *LayoutManager) GetWindowSize() (width, height float64) {
+	return l.windowWidth, l.windowHeight
+}
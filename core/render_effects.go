@@ -0,0 +1,495 @@
+package core
+
+import (
+	"image"
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/noahdw/goui/core/effects"
+	"github.com/noahdw/goui/node/style"
+)
+
+// OpenGL blend factors and equations used with custom blend modes
+const (
+	glZero             = 0
+	glOne              = 1
+	glOneMinusSrcColor = 0x0301
+	glFuncAdd          = 0x8006
+)
+
+// maxBlurTaps limits the radius of the blur shader, in pixels on each side
+const maxBlurTaps = 64
+
+// scratchTargets is the number of intermediate targets a drop-shadow needs:
+// the filter input, the shadow and the blur pass in between
+const scratchTargets = 3
+
+// colorShaderCode adjusts brightness, contrast and grayscale in that order.
+// Each filter runs as its own pass with the other uniforms left neutral.
+const colorShaderCode = `#version 330
+in vec2 fragTexCoord;
+in vec4 fragColor;
+uniform sampler2D texture0;
+uniform float brightness;
+uniform float contrast;
+uniform float grayscale;
+out vec4 finalColor;
+
+void main() {
+    vec4 texel = texture(texture0, fragTexCoord);
+    vec3 color = texel.rgb * brightness;
+    color = (color - 0.5) * contrast + 0.5;
+    float luma = dot(color, vec3(0.2126, 0.7152, 0.0722));
+    color = mix(color, vec3(luma), clamp(grayscale, 0.0, 1.0));
+    finalColor = vec4(clamp(color, 0.0, 1.0), texel.a);
+}
+`
+
+// blurShaderCode is one direction of a separable gaussian blur on premultiplied colors
+const blurShaderCode = `#version 330
+in vec2 fragTexCoord;
+in vec4 fragColor;
+uniform sampler2D texture0;
+uniform vec2 direction;
+uniform float sigma;
+out vec4 finalColor;
+
+void main() {
+    int radius = min(int(ceil(sigma * 3.0)), 64);
+    vec4 sum = vec4(0.0);
+    float total = 0.0;
+    for (int i = -radius; i <= radius; i++) {
+        float weight = exp(-float(i * i) / (2.0 * sigma * sigma));
+        vec4 texel = texture(texture0, fragTexCoord + direction * float(i));
+        sum += vec4(texel.rgb * texel.a, texel.a) * weight;
+        total += weight;
+    }
+    sum /= total;
+    finalColor = sum.a > 0.0 ? vec4(sum.rgb / sum.a, sum.a) : vec4(0.0);
+}
+`
+
+// tintShaderCode draws the silhouette of the texture in the shadow color
+const tintShaderCode = `#version 330
+in vec2 fragTexCoord;
+in vec4 fragColor;
+uniform sampler2D texture0;
+uniform vec4 shadowColor;
+out vec4 finalColor;
+
+void main() {
+    finalColor = vec4(shadowColor.rgb, shadowColor.a * texture(texture0, fragTexCoord).a);
+}
+`
+
+// overShaderCode composites the source texture over texture0 with straight alpha
+const overShaderCode = `#version 330
+in vec2 fragTexCoord;
+in vec4 fragColor;
+uniform sampler2D texture0;
+uniform sampler2D source;
+out vec4 finalColor;
+
+void main() {
+    vec4 dst = texture(texture0, fragTexCoord);
+    vec4 src = texture(source, fragTexCoord);
+    float alpha = src.a + dst.a * (1.0 - src.a);
+    vec3 color = src.rgb * src.a + dst.rgb * dst.a * (1.0 - src.a);
+    finalColor = alpha > 0.0 ? vec4(color / alpha, alpha) : vec4(0.0);
+}
+`
+
+// effectResources holds the shaders and scratch targets used to apply layer effects
+type effectResources struct {
+	loaded  bool
+	valid   bool
+	color   rl.Shader
+	blur    rl.Shader
+	tint    rl.Shader
+	over    rl.Shader
+	scratch [scratchTargets]rl.RenderTexture2D
+	// readback holds the part of a layer copied for blending on the CPU
+	readback rl.RenderTexture2D
+}
+
+// effectShaders loads the effect shaders on first use and reports whether they all compiled.
+// Without them, filters are applied on the CPU.
+func (r *RaylibRenderContext) effectShaders() bool {
+	if r.effectRes.loaded {
+		return r.effectRes.valid
+	}
+	r.effectRes.loaded = true
+	r.effectRes.color = rl.LoadShaderFromMemory("", colorShaderCode)
+	r.effectRes.blur = rl.LoadShaderFromMemory("", blurShaderCode)
+	r.effectRes.tint = rl.LoadShaderFromMemory("", tintShaderCode)
+	r.effectRes.over = rl.LoadShaderFromMemory("", overShaderCode)
+	r.effectRes.valid = rl.IsShaderValid(r.effectRes.color) &&
+		rl.IsShaderValid(r.effectRes.blur) &&
+		rl.IsShaderValid(r.effectRes.tint) &&
+		rl.IsShaderValid(r.effectRes.over)
+	return r.effectRes.valid
+}
+
+// UnloadEffects frees the shaders and scratch targets used for layer effects
+func (r *RaylibRenderContext) UnloadEffects() {
+	if r.effectRes.loaded {
+		rl.UnloadShader(r.effectRes.color)
+		rl.UnloadShader(r.effectRes.blur)
+		rl.UnloadShader(r.effectRes.tint)
+		rl.UnloadShader(r.effectRes.over)
+	}
+	for _, target := range r.effectRes.scratch {
+		if target.ID != 0 {
+			rl.UnloadRenderTexture(target)
+		}
+	}
+	if r.effectRes.readback.ID != 0 {
+		rl.UnloadRenderTexture(r.effectRes.readback)
+	}
+	r.effectRes = effectResources{}
+}
+
+// DrawLayerEffects composites a layer at bounds through the filters and blend mode.
+// Filters run in shaders when available and on the CPU otherwise.
+func (r *RaylibRenderContext) DrawLayerEffects(key string, bounds style.Rect, filters []style.Filter, blendMode string) bool {
+	layer, has := r.layers[key]
+	if !has {
+		return false
+	}
+
+	source := layer.Texture
+	// Render textures are stored upside down, CPU uploads are not
+	flipped := true
+	var uploaded rl.Texture2D
+
+	if len(filters) > 0 {
+		if r.effectShaders() {
+			source = r.applyFilterShaders(layer.Texture, r.deviceFilters(filters))
+		} else {
+			img := effects.Apply(readTexture(layer.Texture, true), r.deviceFilters(filters))
+			uploaded = uploadImage(img)
+			source = uploaded
+			flipped = false
+		}
+	}
+
+	r.compositeTexture(source, flipped, bounds, blendMode)
+
+	if uploaded.ID != 0 {
+		// The texture is drawn by the batch, which must be flushed before it is unloaded
+		rl.DrawRenderBatchActive()
+		rl.UnloadTexture(uploaded)
+	}
+	return true
+}
+
+// deviceFilters converts filter lengths from layout units to physical pixels
+func (r *RaylibRenderContext) deviceFilters(filters []style.Filter) []style.Filter {
+	scaled := make([]style.Filter, len(filters))
+	for i, f := range filters {
+		switch f.Function {
+		case "blur":
+			f.Amount *= r.deviceScale
+		case "drop-shadow":
+			f.Shadow.OffsetX *= r.deviceScale
+			f.Shadow.OffsetY *= r.deviceScale
+			f.Shadow.BlurRadius *= r.deviceScale
+		}
+		scaled[i] = f
+	}
+	return scaled
+}
+
+// applyFilterShaders runs the filters over the texture on the GPU and returns the
+// scratch texture holding the result. The result is stored upside down like the input.
+func (r *RaylibRenderContext) applyFilterShaders(texture rl.Texture2D, filters []style.Filter) rl.Texture2D {
+	r.ensureScratch(texture.Width, texture.Height)
+
+	// Shader passes draw whole textures, so scissoring is suspended until they are done
+	rl.EndScissorMode()
+
+	res := &r.effectRes
+	current := -1 // index of the scratch target holding the result, -1 for the input
+	input := func() rl.Texture2D {
+		if current < 0 {
+			return texture
+		}
+		return res.scratch[current].Texture
+	}
+
+	for _, f := range filters {
+		switch f.Function {
+		case "brightness", "contrast", "grayscale":
+			brightness, contrast, grayscale := float32(1), float32(1), float32(0)
+			switch f.Function {
+			case "brightness":
+				brightness = float32(f.Amount)
+			case "contrast":
+				contrast = float32(f.Amount)
+			case "grayscale":
+				grayscale = float32(f.Amount)
+			}
+			out := freeScratch(current)
+			r.filterPass(input(), res.scratch[out], res.color, rl.Vector2{}, func() {
+				setUniform(res.color, "brightness", []float32{brightness}, rl.ShaderUniformFloat)
+				setUniform(res.color, "contrast", []float32{contrast}, rl.ShaderUniformFloat)
+				setUniform(res.color, "grayscale", []float32{grayscale}, rl.ShaderUniformFloat)
+			})
+			current = out
+
+		case "blur":
+			if f.Amount <= 0 {
+				continue
+			}
+			current = r.blurPasses(input(), current, f.Amount)
+
+		case "drop-shadow":
+			shadow := freeScratch(current)
+			color := f.Shadow.Color
+			r.filterPass(input(), res.scratch[shadow], res.tint, rl.Vector2{X: float32(f.Shadow.OffsetX), Y: float32(f.Shadow.OffsetY)}, func() {
+				setUniform(res.tint, "shadowColor", []float32{
+					float32(color.R) / 255, float32(color.G) / 255, float32(color.B) / 255, float32(color.A) / 255,
+				}, rl.ShaderUniformVec4)
+			})
+			if f.Shadow.BlurRadius > 0 {
+				shadow = r.blurPasses(res.scratch[shadow].Texture, shadow, f.Shadow.BlurRadius/2, current)
+			}
+
+			out := freeScratch(current, shadow)
+			source := input()
+			r.filterPass(res.scratch[shadow].Texture, res.scratch[out], res.over, rl.Vector2{}, func() {
+				rl.SetShaderValueTexture(res.over, rl.GetShaderLocation(res.over, "source"), source)
+			})
+			current = out
+		}
+	}
+
+	// Return drawing to the target that was active before the passes
+	if frame, ok := r.activeLayer(); ok {
+		r.bindLayer(r.layers[frame.key], frame.bounds)
+	}
+	r.SetClipRect(r.clipRect)
+
+	return input()
+}
+
+// blurPasses blurs src horizontally then vertically and returns the scratch index holding the result.
+// Targets in busy, and the one src lives in, are left untouched.
+func (r *RaylibRenderContext) blurPasses(src rl.Texture2D, srcIndex int, sigma float64, busy ...int) int {
+	res := &r.effectRes
+	width, height := float32(src.Width), float32(src.Height)
+
+	horizontal := freeScratch(append(busy, srcIndex)...)
+	r.filterPass(src, res.scratch[horizontal], res.blur, rl.Vector2{}, func() {
+		setUniform(res.blur, "direction", []float32{1 / width, 0}, rl.ShaderUniformVec2)
+		setUniform(res.blur, "sigma", []float32{float32(math.Min(sigma, maxBlurTaps/3))}, rl.ShaderUniformFloat)
+	})
+
+	vertical := freeScratch(append(busy, horizontal)...)
+	r.filterPass(res.scratch[horizontal].Texture, res.scratch[vertical], res.blur, rl.Vector2{}, func() {
+		setUniform(res.blur, "direction", []float32{0, 1 / height}, rl.ShaderUniformVec2)
+		setUniform(res.blur, "sigma", []float32{float32(math.Min(sigma, maxBlurTaps/3))}, rl.ShaderUniformFloat)
+	})
+	return vertical
+}
+
+// filterPass draws src into dst through a shader, replacing the contents of dst
+func (r *RaylibRenderContext) filterPass(src rl.Texture2D, dst rl.RenderTexture2D, shader rl.Shader, offset rl.Vector2, uniforms func()) {
+	width, height := float32(src.Width), float32(src.Height)
+
+	rl.BeginTextureMode(dst)
+	rl.ClearBackground(rl.Blank)
+	rl.SetBlendFactors(glOne, glZero, glFuncAdd)
+	rl.BeginBlendMode(rl.BlendCustom)
+	rl.BeginShaderMode(shader)
+	uniforms()
+	rl.DrawTexturePro(
+		src,
+		rl.Rectangle{X: 0, Y: 0, Width: width, Height: -height},
+		rl.Rectangle{X: offset.X, Y: offset.Y, Width: width, Height: height},
+		rl.Vector2{X: 0, Y: 0},
+		0,
+		rl.White,
+	)
+	rl.EndShaderMode()
+	rl.EndBlendMode()
+	rl.EndTextureMode()
+}
+
+// ensureScratch makes sure the scratch targets match the size of the layer being filtered
+func (r *RaylibRenderContext) ensureScratch(width, height int32) {
+	for i, target := range r.effectRes.scratch {
+		if target.ID != 0 && target.Texture.Width == width && target.Texture.Height == height {
+			continue
+		}
+		if target.ID != 0 {
+			rl.UnloadRenderTexture(target)
+		}
+		r.effectRes.scratch[i] = rl.LoadRenderTexture(width, height)
+	}
+}
+
+// freeScratch returns the index of a scratch target not in busy
+func freeScratch(busy ...int) int {
+	for i := 0; i < scratchTargets; i++ {
+		free := true
+		for _, b := range busy {
+			if b == i {
+				free = false
+				break
+			}
+		}
+		if free {
+			return i
+		}
+	}
+	return 0
+}
+
+// setUniform sets a shader uniform by name
+func setUniform(shader rl.Shader, name string, value []float32, uniformType rl.ShaderUniformDataType) {
+	rl.SetShaderValue(shader, rl.GetShaderLocation(shader, name), value, uniformType)
+}
+
+// compositeTexture draws a filtered layer at bounds using a blend mode.
+// Modes the GPU blend stage can express are drawn directly, the rest are
+// blended on the CPU against the contents of the active layer.
+func (r *RaylibRenderContext) compositeTexture(texture rl.Texture2D, flipped bool, bounds style.Rect, blendMode string) {
+	width, height := float32(texture.Width), float32(texture.Height)
+	source := rl.Rectangle{X: 0, Y: 0, Width: width, Height: height}
+	if flipped {
+		source.Height = -height
+	}
+	dest := rl.Rectangle{X: r.toDeviceFloat(bounds.Position.X), Y: r.toDeviceFloat(bounds.Position.Y), Width: width, Height: height}
+
+	switch blendMode {
+	case "", "normal":
+		rl.BeginBlendMode(rl.BlendAlpha)
+	case "multiply":
+		rl.BeginBlendMode(rl.BlendMultiplied)
+	case "plus-lighter":
+		rl.BeginBlendMode(rl.BlendAdditive)
+	case "screen":
+		rl.SetBlendFactors(glOne, glOneMinusSrcColor, glFuncAdd)
+		rl.BeginBlendMode(rl.BlendCustom)
+	default:
+		// GL_MIN and GL_MAX ignore the blend factors, so darken and lighten cannot apply
+		// source alpha on the GPU and are blended on the CPU with the other modes
+		if r.blendOnCPU(texture, flipped, bounds, blendMode) {
+			return
+		}
+		// Without a layer to read the backdrop from, fall back to normal blending
+		rl.BeginBlendMode(rl.BlendAlpha)
+	}
+
+	rl.DrawTexturePro(texture, source, dest, rl.Vector2{X: 0, Y: 0}, 0, rl.White)
+	rl.EndBlendMode()
+}
+
+// blendOnCPU reads back the part of the active layer under the texture and inside the clip
+// rect, blends the texture into it and writes it back. It returns false if drawing is not
+// going to a layer, as the window cannot be read back.
+func (r *RaylibRenderContext) blendOnCPU(texture rl.Texture2D, flipped bool, bounds style.Rect, blendMode string) bool {
+	frame, ok := r.activeLayer()
+	if !ok {
+		return false
+	}
+	target := r.layers[frame.key]
+
+	visible := bounds.Intersection(r.clipRect)
+	region := visible.Intersection(frame.bounds)
+	if region.Size.Width <= 0 || region.Size.Height <= 0 {
+		return true
+	}
+	x := int32(math.Floor((region.Position.X - frame.bounds.Position.X) * r.deviceScale))
+	y := int32(math.Floor((region.Position.Y - frame.bounds.Position.Y) * r.deviceScale))
+	width := int32(math.Ceil((region.Position.X-frame.bounds.Position.X+region.Size.Width)*r.deviceScale)) - x
+	height := int32(math.Ceil((region.Position.Y-frame.bounds.Position.Y+region.Size.Height)*r.deviceScale)) - y
+	width = min(width, target.Texture.Width-x)
+	height = min(height, target.Texture.Height-y)
+	if width <= 0 || height <= 0 {
+		return true
+	}
+
+	// Finish pending drawing so the read back sees it
+	rl.EndTextureMode()
+	backdrop := r.readLayerRegion(target, x, y, width, height)
+	src := readTexture(texture, flipped)
+
+	at := image.Point{
+		X: int(r.toDevice(bounds.Position.X-frame.bounds.Position.X)) - int(x),
+		Y: int(r.toDevice(bounds.Position.Y-frame.bounds.Position.Y)) - int(y),
+	}
+	effects.Blend(backdrop, src, at, blendMode)
+
+	// Write the result back, replacing the layer contents inside the clip rect
+	r.bindLayer(target, frame.bounds)
+	r.SetClipRect(r.clipRect)
+	result := uploadImage(backdrop)
+	rl.SetBlendFactors(glOne, glZero, glFuncAdd)
+	rl.BeginBlendMode(rl.BlendCustom)
+	rl.DrawTextureV(result, rl.Vector2{
+		X: r.toDeviceFloat(frame.bounds.Position.X) + float32(x),
+		Y: r.toDeviceFloat(frame.bounds.Position.Y) + float32(y),
+	}, rl.White)
+	rl.EndBlendMode()
+	rl.DrawRenderBatchActive()
+	rl.UnloadTexture(result)
+	return true
+}
+
+// readLayerRegion copies a rectangle of a layer, in its pixels from the top left, to an
+// image. The rectangle is drawn into a target of its own size first, so only it is read
+// back from the GPU.
+func (r *RaylibRenderContext) readLayerRegion(layer rl.RenderTexture2D, x, y, width, height int32) *image.NRGBA {
+	res := &r.effectRes
+	if res.readback.ID == 0 || res.readback.Texture.Width != width || res.readback.Texture.Height != height {
+		if res.readback.ID != 0 {
+			rl.UnloadRenderTexture(res.readback)
+		}
+		res.readback = rl.LoadRenderTexture(width, height)
+	}
+
+	// Render textures are stored upside down, so the rows of the region are counted from the bottom
+	rl.BeginTextureMode(res.readback)
+	rl.ClearBackground(rl.Blank)
+	rl.SetBlendFactors(glOne, glZero, glFuncAdd)
+	rl.BeginBlendMode(rl.BlendCustom)
+	rl.DrawTexturePro(
+		layer.Texture,
+		rl.Rectangle{X: float32(x), Y: float32(layer.Texture.Height - y - height), Width: float32(width), Height: -float32(height)},
+		rl.Rectangle{X: 0, Y: 0, Width: float32(width), Height: float32(height)},
+		rl.Vector2{X: 0, Y: 0},
+		0,
+		rl.White,
+	)
+	rl.EndBlendMode()
+	rl.EndTextureMode()
+	return readTexture(res.readback.Texture, true)
+}
+
+// readTexture copies a texture to an image, flipping render textures upright
+func readTexture(texture rl.Texture2D, flipped bool) *image.NRGBA {
+	img := rl.LoadImageFromTexture(texture)
+	if flipped {
+		rl.ImageFlipVertical(img)
+	}
+	colors := rl.LoadImageColors(img)
+	rl.UnloadImage(img)
+
+	out := image.NewNRGBA(image.Rect(0, 0, int(texture.Width), int(texture.Height)))
+	for i, c := range colors {
+		out.Pix[i*4] = c.R
+		out.Pix[i*4+1] = c.G
+		out.Pix[i*4+2] = c.B
+		out.Pix[i*4+3] = c.A
+	}
+	rl.UnloadImageColors(colors)
+	return out
+}
+
+// uploadImage creates a texture from an image
+func uploadImage(img *image.NRGBA) rl.Texture2D {
+	raw := rl.NewImage(img.Pix, int32(img.Rect.Dx()), int32(img.Rect.Dy()), 1, rl.UncompressedR8g8b8a8)
+	return rl.LoadTextureFromImage(raw)
+}
@@ -1,111 +1,214 @@
-package core
-
-import (
-	rl "github.com/gen2brain/raylib-go/raylib"
-
-	. "github.com/noahdw/goui/node"
-	"github.com/noahdw/goui/node/style"
-)
-
-// RenderEngine handles the rendering process
-type RenderEngine struct {
-	rootNode      Node
-	renderContext RenderContext
-	layoutManager *LayoutManager
-	eventManager  *EventManager
-	windowWidth   float64
-	windowHeight  float64
-	needsLayout   bool
-	camera        rl.Camera2D
-	hasAnimations bool
-	lastFoundObj  Node
-	focusedNode   Node // Currently focused node for keyboard events
-	pressedObj    Node // Node that was pressed, for click detection
-	pressX        float64
-	pressY        float64
-	lastMouseX    float64 // Track last mouse position
-	lastMouseY    float64
-}
-
-// NewRenderEngine creates a new render engine
-func NewRenderEngine(root Node, context RenderContext, width, height float64) *RenderEngine {
-	engine := &RenderEngine{
-		rootNode:      root,
-		renderContext: context,
-		windowWidth:   width,
-		windowHeight:  height,
-		needsLayout:   true,
-		camera: rl.Camera2D{
-			Zoom: 1,
-		},
-	}
-
-	engine.layoutManager = NewLayoutManager(root, context, width, height)
-	engine.eventManager = NewEventManager(engine)
-
-	return engine
-}
-
-// RenderFrame handles a single frame of rendering
-func (r *RenderEngine) RenderFrame() {
-	// Update window size if needed
-	if rl.IsWindowResized() {
-		r.layoutManager.UpdateWindowSize(
-			float64(rl.GetScreenWidth()),
-			float64(rl.GetScreenHeight()),
-		)
-	}
-
-	// Update layout if needed
-	r.layoutManager.UpdateLayout()
-
-	// Get mouse position in world coordinates
-	mouseWorldPos := rl.GetScreenToWorld2D(rl.GetMousePosition(), r.camera)
-	mouseX := float64(mouseWorldPos.X)
-	mouseY := float64(mouseWorldPos.Y)
-
-	// Find object under cursor
-	cursor := style.Rect{
-		Position: style.Point{X: mouseX, Y: mouseY},
-		Size:     style.Size{Width: 1, Height: 1},
-	}
-	foundObj := r.getObjUnderCursor(r.rootNode, cursor)
-
-	// Handle all events
-	r.eventManager.HandleMouseEvents(mouseX, mouseY, foundObj)
-	r.eventManager.HandleKeyboardEvents()
-
-	// Clear the screen and render
-	r.renderContext.Clear()
-	r.rootNode.Paint(r.renderContext)
-}
-
-// MarkLayoutDirty marks the layout as needing recalculation
-func (r *RenderEngine) MarkLayoutDirty() {
-	r.layoutManager.MarkDirty()
-}
-
-// GetCamera returns the current camera
-func (r *RenderEngine) GetCamera() rl.Camera2D {
-	return r.camera
-}
-
-// SetFocus sets the currently focused node
-func (r *RenderEngine) SetFocus(node Node) {
-	r.eventManager.SetFocus(node)
-}
-
-func (r *RenderEngine) getObjUnderCursor(node Node, cursor style.Rect) Node {
-	nodebr := node.GetFinalBounds()
-	if !nodebr.Intersects(cursor) {
-		return nil
-	}
-	foundObj := node
-	for _, child := range node.Children() {
-		fo := r.getObjUnderCursor(child, cursor)
-		if fo != nil {
-			foundObj = fo
-		}
-	}
-	return foundObj
-}
+package core
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+
+	. "github.com/noahdw/goui/node"
+	"github.com/noahdw/goui/node/style"
+)
+
+// RenderEngine handles the rendering process
+type RenderEngine struct {
+	rootNode      Node
+	renderContext RenderContext
+	layoutManager *LayoutManager
+	eventManager  *EventManager
+	windowWidth   float64
+	windowHeight  float64
+	needsLayout   bool
+	hasAnimations bool
+	lastFoundObj  Node
+	focusedNode   Node // Currently focused node for keyboard events
+	pressedObj    Node // Node that was pressed, for click detection
+	pressX        float64
+	pressY        float64
+	lastMouseX    float64 // Track last mouse position
+	lastMouseY    float64
+	fullRepaint   bool    // Repaint the whole window instead of only the damaged regions
+	deviceScale   float64 // Physical pixels per logical layout unit
+}
+
+// frameLayerKey is the layer that holds the retained contents of the window
+const frameLayerKey = "goui-frame"
+
+// maxDamageRegions is the number of separate regions repainted before they are merged into one
+const maxDamageRegions = 8
+
+// NewRenderEngine creates a new render engine
+func NewRenderEngine(root Node, context RenderContext, width, height float64) *RenderEngine {
+	engine := &RenderEngine{
+		rootNode:      root,
+		renderContext: context,
+		windowWidth:   width,
+		windowHeight:  height,
+		needsLayout:   true,
+		fullRepaint:   true,
+		deviceScale:   1.0,
+	}
+
+	engine.layoutManager = NewLayoutManager(root, context, width, height)
+	engine.eventManager = NewEventManager(engine)
+
+	return engine
+}
+
+// RenderFrame handles a single frame of rendering.
+// It returns true if any part of the tree was repainted.
+func (r *RenderEngine) RenderFrame() bool {
+	// Update window size if needed
+	if rl.IsWindowResized() {
+		r.windowWidth = float64(rl.GetRenderWidth()) / r.deviceScale
+		r.windowHeight = float64(rl.GetRenderHeight()) / r.deviceScale
+		r.layoutManager.UpdateWindowSize(r.windowWidth, r.windowHeight)
+		r.renderContext.SetClipRect(r.windowRect())
+		r.fullRepaint = true
+	}
+
+	// Handle input first so state changes are laid out and painted this frame
+	r.handleEvents()
+
+	// Update layout if needed
+	r.layoutManager.UpdateLayout()
+
+	return r.paint()
+}
+
+// handleEvents hit tests the cursor and dispatches input events
+func (r *RenderEngine) handleEvents() {
+	// Get mouse position in screen coordinates, converted to framebuffer pixels and then to layout units
+	mouse := rl.GetMousePosition()
+	toPixels := framebufferScale()
+	mouseX := float64(mouse.X) * toPixels / r.deviceScale
+	mouseY := float64(mouse.Y) * toPixels / r.deviceScale
+
+	// Find object under cursor
+	cursor := style.Rect{
+		Position: style.Point{X: mouseX, Y: mouseY},
+		Size:     style.Size{Width: 1, Height: 1},
+	}
+	foundObj := HitTest(r.rootNode, cursor)
+
+	// Handle all events
+	r.eventManager.HandleMouseEvents(mouseX, mouseY, foundObj)
+	r.eventManager.HandleKeyboardEvents()
+}
+
+// paint repaints the damaged parts of the window into the retained frame layer and presents it
+func (r *RenderEngine) paint() bool {
+	layerContext, ok := r.renderContext.(LayerRenderContext)
+	if !ok {
+		// Without layers nothing is retained between frames, so repaint everything
+		r.renderContext.Clear()
+		r.rootNode.Paint(r.renderContext)
+		r.rootNode.ClearPaintDirty()
+		return true
+	}
+
+	window := r.windowRect()
+	var damage []style.Rect
+	if r.fullRepaint {
+		damage = []style.Rect{window}
+	} else {
+		damage = mergeDamage(r.rootNode.CollectDamage(nil), window)
+	}
+
+	if len(damage) > 0 {
+		layerContext.BeginLayer(frameLayerKey, window, false)
+		for _, region := range damage {
+			layerContext.SetClipRect(region)
+			layerContext.Clear()
+			r.rootNode.Paint(layerContext)
+		}
+		layerContext.EndLayer()
+
+		r.rootNode.ClearPaintDirty()
+		r.fullRepaint = false
+	}
+
+	layerContext.DrawLayer(frameLayerKey, window)
+	return len(damage) > 0
+}
+
+// IsIdle returns true if nothing needs to be laid out, repainted or animated
+func (r *RenderEngine) IsIdle() bool {
+	return !r.hasAnimations && !r.fullRepaint && !r.layoutManager.NeedsLayout() && !r.rootNode.NeedsPaint()
+}
+
+// windowRect returns the area covered by the window
+func (r *RenderEngine) windowRect() style.Rect {
+	return style.Rect{
+		Position: style.Point{X: 0, Y: 0},
+		Size:     style.Size{Width: r.windowWidth, Height: r.windowHeight},
+	}
+}
+
+// mergeDamage clips damaged regions to the window and merges overlapping ones.
+// When too many separate regions remain they are merged into a single region.
+func mergeDamage(regions []style.Rect, window style.Rect) []style.Rect {
+	var merged []style.Rect
+	for _, region := range regions {
+		region = window.Intersection(region)
+		if region.IsEmpty() {
+			continue
+		}
+
+		// Absorb every merged region that overlaps, repeating until the region is stable
+		for changed := true; changed; {
+			changed = false
+			for i := 0; i < len(merged); i++ {
+				if region.Intersects(merged[i]) {
+					region = region.Union(merged[i])
+					merged = append(merged[:i], merged[i+1:]...)
+					changed = true
+					break
+				}
+			}
+		}
+		merged = append(merged, region)
+	}
+
+	if len(merged) > maxDamageRegions {
+		union := merged[0]
+		for _, region := range merged[1:] {
+			union = union.Union(region)
+		}
+		merged = []style.Rect{union}
+	}
+	return merged
+}
+
+// SetDeviceScale sets the number of physical pixels per logical layout unit
+func (r *RenderEngine) SetDeviceScale(scale float64) {
+	if scale <= 0 {
+		scale = 1.0
+	}
+	if scale != r.deviceScale {
+		r.deviceScale = scale
+		r.fullRepaint = true
+	}
+}
+
+// DeviceScale returns the number of physical pixels per logical layout unit
+func (r *RenderEngine) DeviceScale() float64 {
+	return r.deviceScale
+}
+
+// SetParallelLayout sets the number of goroutines laying out independent subtrees
+func (r *RenderEngine) SetParallelLayout(workers int) {
+	r.layoutManager.SetParallelLayout(workers)
+}
+
+// MarkLayoutDirty marks the layout as needing recalculation
+func (r *RenderEngine) MarkLayoutDirty() {
+	r.layoutManager.MarkDirty()
+}
+
+// GetCamera returns the camera the window is drawn with, which maps framebuffer pixels to the window
+func (r *RenderEngine) GetCamera() rl.Camera2D {
+	return framebufferCamera()
+}
+
+// SetFocus sets the currently focused node
+func (r *RenderEngine) SetFocus(node Node) {
+	r.eventManager.SetFocus(node)
+}
@@ -0,0 +1,423 @@
+package core
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/noahdw/goui/node"
+	"github.com/noahdw/goui/node/style"
+)
+
+// svgState holds the drawing state that Save and Restore push and pop
+type svgState struct {
+	opacity     float64
+	fillColor   style.Color
+	strokeColor style.Color
+	lineWidth   float64
+	fontSize    float64
+	scaleX      float64
+	scaleY      float64
+	clipRect    style.Rect
+}
+
+// SVGRenderContext implements the RenderContext interface by emitting SVG elements.
+// It mirrors what RaylibRenderContext draws, so the resulting document is a faithful
+// snapshot of a frame that can be attached to documentation or bug reports.
+type SVGRenderContext struct {
+	width    float64
+	height   float64
+	elements []string
+	state    svgState
+	stack    []svgState
+	textures map[string]svgTexture
+
+	// Elements drawn while a clip rect is set go in a group clipped to it
+	grouped   bool
+	groupClip style.Rect
+	clipCount int
+}
+
+// svgTexture caches the decoded size and the inlined data of an image
+type svgTexture struct {
+	width   int32
+	height  int32
+	dataURI string
+}
+
+// NewSVGRenderContext creates a new SVG render context for a canvas of the given size
+func NewSVGRenderContext(width, height float64) *SVGRenderContext {
+	return &SVGRenderContext{
+		width:  width,
+		height: height,
+		state: svgState{
+			opacity:     1.0,
+			fillColor:   style.White,
+			strokeColor: style.Black,
+			lineWidth:   1.0,
+			fontSize:    16.0,
+			scaleX:      1.0,
+			scaleY:      1.0,
+			clipRect: style.Rect{
+				Size: style.Size{Width: width, Height: height},
+			},
+		},
+		textures: make(map[string]svgTexture),
+	}
+}
+
+// Clear discards everything drawn so far and fills the canvas with the clear color
+func (s *SVGRenderContext) Clear() {
+	s.elements = s.elements[:0]
+	s.grouped = false
+	s.clipCount = 0
+	s.emit(`<rect x="0" y="0" width="%s" height="%s" fill="%s"/>`,
+		svgNum(s.width), svgNum(s.height), svgRGB(style.Color{R: rl.RayWhite.R, G: rl.RayWhite.G, B: rl.RayWhite.B}))
+}
+
+// Save pushes the current drawing state
+func (s *SVGRenderContext) Save() {
+	s.stack = append(s.stack, s.state)
+}
+
+// Restore pops the most recently saved drawing state
+func (s *SVGRenderContext) Restore() {
+	if len(s.stack) == 0 {
+		return
+	}
+	s.state = s.stack[len(s.stack)-1]
+	s.stack = s.stack[:len(s.stack)-1]
+}
+
+// SetOpacity sets the current opacity
+func (s *SVGRenderContext) SetOpacity(opacity float64) {
+	s.state.opacity = opacity
+}
+
+// SetFillColor sets the current fill color
+func (s *SVGRenderContext) SetFillColor(color style.Color) {
+	s.state.fillColor = color
+}
+
+// SetStrokeColor sets the current stroke color
+func (s *SVGRenderContext) SetStrokeColor(color style.Color) {
+	s.state.strokeColor = color
+}
+
+// SetLineWidth sets the current line width
+func (s *SVGRenderContext) SetLineWidth(width float64) {
+	s.state.lineWidth = width
+}
+
+// SetFontSize sets the current font size
+func (s *SVGRenderContext) SetFontSize(size float64) {
+	s.state.fontSize = size
+}
+
+// Scale sets the current scale transform
+func (s *SVGRenderContext) Scale(x, y float64) {
+	// Like the raylib context, the scale is tracked but not applied to drawing
+	s.state.scaleX *= x
+	s.state.scaleY *= y
+}
+
+// StrokeLine draws a line from start to end
+func (s *SVGRenderContext) StrokeLine(start, end style.Point) {
+	s.emit(`<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="%s" stroke-opacity="%s" stroke-width="%s"/>`,
+		svgNum(start.X), svgNum(start.Y), svgNum(end.X), svgNum(end.Y),
+		svgRGB(s.state.strokeColor), svgAlpha(s.state.opacity), svgNum(s.state.lineWidth))
+}
+
+// FillRect fills a rectangle with the current fill color
+func (s *SVGRenderContext) FillRect(rect style.Rect) {
+	s.emitRect(rect, s.state.fillColor, s.state.opacity)
+}
+
+// DrawBackground draws a background with the specified styles
+func (s *SVGRenderContext) DrawBackground(bounds style.Rect, styles style.Styles, opacity float64) {
+	bgColor, ok := styles.GetColor("background")
+	if !ok {
+		return
+	}
+	s.emitRect(bounds, bgColor, opacity*s.state.opacity)
+}
+
+// DrawBorders draws borders with the specified style
+func (s *SVGRenderContext) DrawBorders(bounds style.Rect, styles style.Styles, opacity float64) {
+	border, ok := styles.Get("border")
+	if !ok {
+		return
+	}
+	borderStyle, ok := border.(style.BorderStyle)
+	if !ok {
+		return
+	}
+
+	alpha := opacity * s.state.opacity
+	x, y := bounds.Position.X, bounds.Position.Y
+	w, h := bounds.Size.Width, bounds.Size.Height
+
+	if borderStyle.Width.Top > 0 {
+		s.emitRect(style.NewRect(style.NewPoint(x, y), style.NewSize(w, borderStyle.Width.Top)), borderStyle.Color, alpha)
+	}
+	if borderStyle.Width.Right > 0 {
+		s.emitRect(style.NewRect(style.NewPoint(x+w-borderStyle.Width.Right, y), style.NewSize(borderStyle.Width.Right, h)), borderStyle.Color, alpha)
+	}
+	if borderStyle.Width.Bottom > 0 {
+		s.emitRect(style.NewRect(style.NewPoint(x, y+h-borderStyle.Width.Bottom), style.NewSize(w, borderStyle.Width.Bottom)), borderStyle.Color, alpha)
+	}
+	if borderStyle.Width.Left > 0 {
+		s.emitRect(style.NewRect(style.NewPoint(x, y), style.NewSize(borderStyle.Width.Left, h)), borderStyle.Color, alpha)
+	}
+}
+
+// DrawText draws text with the specified styles
+func (s *SVGRenderContext) DrawText(text string, bounds style.Rect, styles style.Styles, opacity float64) {
+	fontSize, _ := styles.GetFloat("fontSize")
+	fontFamily, _ := styles.GetString("fontFamily")
+	padding, _ := styles.GetEdgeInsets("padding")
+	textAlign := styles.TextAlign()
+	alignItems, _ := styles.GetString("alignItems")
+	textColor, _ := styles.GetColor("color")
+
+	textHeight := fontSize * 1.2
+
+	// Right-to-left text is written in visual order, which the viewer must not reorder again
+	bidi := ""
+	if styles.IsRTL() {
+		text = visualOrder(text)
+		bidi = ` unicode-bidi="bidi-override"`
+	}
+
+	// SVG measures the text itself, so alignment is expressed through the anchor
+	var x float64
+	anchor := "start"
+	switch textAlign {
+	case "center":
+		x = bounds.Position.X + bounds.Size.Width/2
+		anchor = "middle"
+	case "right":
+		x = bounds.Position.X + bounds.Size.Width - padding.Right
+		anchor = "end"
+	default:
+		x = bounds.Position.X + padding.Left
+	}
+
+	var y float64
+	switch alignItems {
+	case "center":
+		y = bounds.Position.Y + (bounds.Size.Height-textHeight)/2
+	case "bottom":
+		y = bounds.Position.Y + bounds.Size.Height - textHeight - padding.Bottom
+	default:
+		y = bounds.Position.Y + padding.Top
+	}
+
+	if fontFamily == "" {
+		fontFamily = "sans-serif"
+	}
+
+	s.emit(`<text x="%s" y="%s" font-family="%s" font-size="%s" text-anchor="%s" dominant-baseline="hanging" fill="%s" fill-opacity="%s"%s xml:space="preserve">%s</text>`,
+		svgNum(x), svgNum(y), svgEscape(fontFamily), svgNum(float64(int32(fontSize))), anchor,
+		svgRGB(textColor), svgAlpha(opacity*s.state.opacity), bidi, svgEscape(text))
+}
+
+// LoadTexture reads the size of an image without uploading it to the GPU.
+// The returned texture has a zero ID and is only meant for measuring.
+func (s *SVGRenderContext) LoadTexture(sourceURL string) rl.Texture2D {
+	tex, ok := s.loadTexture(sourceURL)
+	if !ok {
+		return rl.Texture2D{}
+	}
+	return rl.Texture2D{Width: tex.width, Height: tex.height}
+}
+
+// loadTexture decodes and caches an image so it can be inlined into the document
+func (s *SVGRenderContext) loadTexture(sourceURL string) (svgTexture, bool) {
+	if tex, ok := s.textures[sourceURL]; ok {
+		return tex, true
+	}
+
+	data, err := os.ReadFile(sourceURL)
+	if err != nil {
+		return svgTexture{}, false
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return svgTexture{}, false
+	}
+
+	tex := svgTexture{
+		width:   int32(config.Width),
+		height:  int32(config.Height),
+		dataURI: "data:image/" + format + ";base64," + base64.StdEncoding.EncodeToString(data),
+	}
+	s.textures[sourceURL] = tex
+	return tex, true
+}
+
+// DrawTexture draws an image with the specified styles
+func (s *SVGRenderContext) DrawTexture(sourceURL string, bounds style.Rect, styles style.Styles, opacity float64) {
+	tex, ok := s.loadTexture(sourceURL)
+	if !ok {
+		// Skip drawing if the image failed to load
+		return
+	}
+
+	objectFit, _ := styles.GetString("objectFit")
+
+	// Map object-fit onto the equivalent preserveAspectRatio value
+	var aspect string
+	switch objectFit {
+	case "cover":
+		aspect = "xMidYMid slice"
+	case "fill":
+		aspect = "none"
+	default:
+		aspect = "xMidYMid meet"
+	}
+
+	s.emit(`<image x="%s" y="%s" width="%s" height="%s" preserveAspectRatio="%s" opacity="%s" href="%s"/>`,
+		svgNum(bounds.Position.X), svgNum(bounds.Position.Y), svgNum(bounds.Size.Width), svgNum(bounds.Size.Height),
+		aspect, svgAlpha(opacity*s.state.opacity), tex.dataURI)
+}
+
+// ClipRect returns the current clipping rectangle
+func (s *SVGRenderContext) ClipRect() style.Rect {
+	return s.state.clipRect
+}
+
+// SetClipRect sets the current clipping rectangle, which the elements drawn next are grouped and clipped to
+func (s *SVGRenderContext) SetClipRect(rect style.Rect) {
+	s.state.clipRect = rect
+}
+
+// Present does nothing, the document is produced by WriteTo
+func (s *SVGRenderContext) Present() {
+}
+
+// WriteTo writes the recorded frame as a standalone SVG document
+func (s *SVGRenderContext) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n",
+		svgNum(s.width), svgNum(s.height), svgNum(s.width), svgNum(s.height))
+	for _, element := range s.elements {
+		b.WriteString("  ")
+		b.WriteString(element)
+		b.WriteString("\n")
+	}
+	if s.grouped {
+		b.WriteString("  </g>\n")
+	}
+	b.WriteString("</svg>\n")
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// emit appends a formatted element to the document, clipped to the current clip rect
+func (s *SVGRenderContext) emit(format string, args ...interface{}) {
+	s.applyClip()
+	s.elements = append(s.elements, fmt.Sprintf(format, args...))
+}
+
+// applyClip starts a group clipped to the current clip rect if it changed since the last
+// element, closing the previous group. Nothing is grouped while the whole canvas is visible.
+func (s *SVGRenderContext) applyClip() {
+	clip := s.state.clipRect
+	if s.grouped && clip == s.groupClip {
+		return
+	}
+	covers := clip.Position.X <= 0 && clip.Position.Y <= 0 &&
+		clip.Position.X+clip.Size.Width >= s.width && clip.Position.Y+clip.Size.Height >= s.height
+	if !s.grouped && covers {
+		return
+	}
+
+	if s.grouped {
+		s.elements = append(s.elements, "</g>")
+		s.grouped = false
+	}
+	if covers {
+		return
+	}
+	s.clipCount++
+	s.elements = append(s.elements,
+		fmt.Sprintf(`<clipPath id="clip%d"><rect x="%s" y="%s" width="%s" height="%s"/></clipPath>`,
+			s.clipCount, svgNum(clip.Position.X), svgNum(clip.Position.Y), svgNum(clip.Size.Width), svgNum(clip.Size.Height)),
+		fmt.Sprintf(`<g clip-path="url(#clip%d)">`, s.clipCount))
+	s.grouped = true
+	s.groupClip = clip
+}
+
+// emitRect appends a filled rectangle, snapped to whole pixels like raylib does
+func (s *SVGRenderContext) emitRect(rect style.Rect, color style.Color, opacity float64) {
+	s.emit(`<rect x="%s" y="%s" width="%s" height="%s" fill="%s" fill-opacity="%s"/>`,
+		svgNum(float64(int32(rect.Position.X))), svgNum(float64(int32(rect.Position.Y))),
+		svgNum(float64(int32(rect.Size.Width))), svgNum(float64(int32(rect.Size.Height))),
+		svgRGB(color), svgAlpha(opacity))
+}
+
+// svgNum formats a number without trailing zeros
+func svgNum(v float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.3f", v), "0"), ".")
+}
+
+// svgRGB formats the color channels of a color, ignoring its alpha like raylib does
+func svgRGB(c style.Color) string {
+	return fmt.Sprintf("rgb(%d,%d,%d)", c.R, c.G, c.B)
+}
+
+// svgAlpha quantizes an opacity the same way NormalizedFloatToUint8 does
+func svgAlpha(opacity float64) string {
+	return svgNum(float64(NormalizedFloatToUint8(opacity)) / 255.0)
+}
+
+// svgEscape escapes text for use in element content and attribute values
+func svgEscape(text string) string {
+	return strings.NewReplacer(
+		"&", "&amp;",
+		"<", "&lt;",
+		">", "&gt;",
+		`"`, "&quot;",
+		"'", "&apos;",
+	).Replace(text)
+}
+
+// ExportSVG lays out the tree at the given size and writes what it paints as an SVG document
+func ExportSVG(root node.Node, width, height float64, w io.Writer) error {
+	context := NewSVGRenderContext(width, height)
+
+	layoutManager := NewLayoutManager(root, context, width, height)
+	layoutManager.UpdateLayout()
+
+	context.Clear()
+	root.Paint(context)
+
+	_, err := context.WriteTo(w)
+	return err
+}
+
+// SaveSVG lays out the tree at the given size and writes what it paints to an SVG file.
+// Closing the file can fail to flush what was written, so its error is returned too.
+func SaveSVG(root node.Node, width, height float64, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = ExportSVG(root, width, height, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,160 @@
+package node
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/noahdw/goui/node/style"
+)
+
+// Breakpoint is a condition on the size of the window, for styles that only apply to some
+// window sizes. Limits of 0 are not checked.
+type Breakpoint struct {
+	MinWidth  float64
+	MaxWidth  float64
+	MinHeight float64
+	MaxHeight float64
+}
+
+// MinWidth matches windows at least width wide
+func MinWidth(width float64) Breakpoint {
+	return Breakpoint{MinWidth: width}
+}
+
+// MaxWidth matches windows at most width wide
+func MaxWidth(width float64) Breakpoint {
+	return Breakpoint{MaxWidth: width}
+}
+
+// MinHeight matches windows at least height high
+func MinHeight(height float64) Breakpoint {
+	return Breakpoint{MinHeight: height}
+}
+
+// MaxHeight matches windows at most height high
+func MaxHeight(height float64) Breakpoint {
+	return Breakpoint{MaxHeight: height}
+}
+
+// And matches the windows both breakpoints match, e.g. MinWidth(800).And(MaxWidth(1279))
+func (b Breakpoint) And(other Breakpoint) Breakpoint {
+	return Breakpoint{
+		MinWidth:  math.Max(b.MinWidth, other.MinWidth),
+		MaxWidth:  smallestLimit(b.MaxWidth, other.MaxWidth),
+		MinHeight: math.Max(b.MinHeight, other.MinHeight),
+		MaxHeight: smallestLimit(b.MaxHeight, other.MaxHeight),
+	}
+}
+
+// Matches returns true if a window of the given size meets every limit
+func (b Breakpoint) Matches(window style.Size) bool {
+	return window.Width >= b.MinWidth && window.Height >= b.MinHeight &&
+		(b.MaxWidth == 0 || window.Width <= b.MaxWidth) &&
+		(b.MaxHeight == 0 || window.Height <= b.MaxHeight)
+}
+
+// smallestLimit returns the smaller of two maximums, where 0 is no maximum
+func smallestLimit(a, b float64) float64 {
+	if a == 0 || b == 0 {
+		return math.Max(a, b)
+	}
+	return math.Min(a, b)
+}
+
+// breakpointStyle is a set of style properties that applies while its breakpoint matches
+type breakpointStyle struct {
+	breakpoint Breakpoint
+	props      map[string]interface{}
+}
+
+// breakpointSetters are the builder methods that parse the props of the same names, so that
+// When takes the same values as the builder, such as color names or "1fr 200px" track lists
+var breakpointSetters = map[string]func(*BaseNode, interface{}) Node{
+	"width":               (*BaseNode).Width,
+	"height":              (*BaseNode).Height,
+	"minWidth":            (*BaseNode).MinWidth,
+	"maxWidth":            (*BaseNode).MaxWidth,
+	"minHeight":           (*BaseNode).MinHeight,
+	"maxHeight":           (*BaseNode).MaxHeight,
+	"aspectRatio":         (*BaseNode).AspectRatio,
+	"margin":              (*BaseNode).Margin,
+	"padding":             (*BaseNode).Padding,
+	"top":                 (*BaseNode).Top,
+	"right":               (*BaseNode).Right,
+	"bottom":              (*BaseNode).Bottom,
+	"left":                (*BaseNode).Left,
+	"rowGap":              (*BaseNode).RowGap,
+	"columnGap":           (*BaseNode).ColumnGap,
+	"gridTemplateColumns": (*BaseNode).GridTemplateColumns,
+	"gridTemplateRows":    (*BaseNode).GridTemplateRows,
+	"gridColumn":          (*BaseNode).GridColumn,
+	"gridRow":             (*BaseNode).GridRow,
+	"flexBasis":           (*BaseNode).FlexBasis,
+	"fontSize":            (*BaseNode).FontSize,
+	"fontWeight":          (*BaseNode).FontWeight,
+	"lineHeight":          (*BaseNode).LineHeight,
+	"color":               (*BaseNode).Color,
+	"background":          (*BaseNode).Background,
+	"border":              (*BaseNode).Border,
+	"borderRadius":        (*BaseNode).BorderRadius,
+	"shadow":              (*BaseNode).Shadow,
+	"opacity":             (*BaseNode).Opacity,
+	"scale":               (*BaseNode).Scale,
+	"filter":              (*BaseNode).Filter,
+	"scrollbarColor":      (*BaseNode).ScrollbarColor,
+	"scrollbarTrackColor": (*BaseNode).ScrollbarTrackColor,
+}
+
+// parseBreakpointProps runs props through the builder methods on a scratch node and returns
+// the values they stored. Props the builder rejects are dropped.
+func parseBreakpointProps(props map[string]interface{}) map[string]interface{} {
+	scratch := &BaseNode{styles: style.NewStyles(map[string]interface{}{})}
+	parsed := make(map[string]interface{}, len(props))
+	for key, value := range props {
+		set, ok := breakpointSetters[key]
+		if !ok {
+			parsed[key] = value
+			continue
+		}
+		scratch.styles.Unset(key)
+		set(scratch, value)
+		if specified, ok := scratch.styles.Specified(key); ok {
+			parsed[key] = specified
+		} else {
+			fmt.Printf("[STYLE ERROR] Invalid breakpoint value for %s: %v\n", key, value)
+		}
+	}
+	return parsed
+}
+
+// applyBreakpoints puts back the values the breakpoints replaced the last time the node was
+// restyled, or removes the properties they added, then applies the props of the breakpoints
+// the window matches now. Later breakpoints win where they set the same property.
+func (n *BaseNode) applyBreakpoints(styles *style.Styles, window style.Size) {
+	if len(n.breakpoints) == 0 {
+		return
+	}
+	for key, value := range n.breakpointBase {
+		if value == nil {
+			styles.Unset(key)
+		} else {
+			styles.Set(key, value)
+		}
+	}
+
+	n.breakpointBase = make(map[string]interface{})
+	for _, b := range n.breakpoints {
+		if !b.breakpoint.Matches(window) {
+			continue
+		}
+		for key, value := range b.props {
+			if _, saved := n.breakpointBase[key]; !saved {
+				n.breakpointBase[key] = nil
+				if base, ok := styles.Specified(key); ok {
+					n.breakpointBase[key] = base
+				}
+			}
+			styles.Set(key, value)
+		}
+	}
+}
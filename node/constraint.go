@@ -0,0 +1,417 @@
+package node
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/noahdw/goui/node/solver"
+	"github.com/noahdw/goui/node/style"
+)
+
+// Strengths of the constraints a constraint layout adds for each child. A child keeps its
+// own size unless a rule of medium strength or more resizes it, sits at the top left of
+// the content box unless a rule places it, and only goes past the top or left of the
+// content box for a required rule.
+const (
+	insideStrength      = solver.Strong
+	naturalSizeStrength = solver.Medium
+	originStrength      = solver.Weak / 10
+)
+
+// anchors are the edges, sizes and centers of a box that rules refer to
+var anchors = map[string]bool{
+	"left": true, "right": true, "top": true, "bottom": true,
+	"width": true, "height": true, "centerX": true, "centerY": true,
+}
+
+// anchorRef is an anchor of the child with an ID, or of the content box for "parent"
+type anchorRef struct {
+	id     string
+	anchor string
+}
+
+// linearExpr is a sum of anchors times coefficients plus a constant
+type linearExpr struct {
+	terms    map[anchorRef]float64
+	constant float64
+}
+
+// constraintRule is a parsed rule of a constraint layout, such as "label.left == input.left"
+type constraintRule struct {
+	source   string
+	lhs      linearExpr
+	operator solver.Operator
+	rhs      linearExpr
+	strength solver.Strength
+}
+
+// boxVars are the solver variables of a child's frame
+type boxVars struct {
+	left, top, width, height *solver.Variable
+}
+
+// isConstraintLayout returns true if the children are placed by the node's rules
+func (n *BaseNode) isConstraintLayout() bool {
+	display, _ := n.styles.GetString("display")
+	return display == "constraint"
+}
+
+// Constrain adds a rule relating the anchors of children, found by their ID, or of the
+// content box, called parent. Rules compare two linear expressions with ==, <= or >=,
+// such as "label.left == input.left" or "panel.width >= 0.5 * sidebar.width + 10".
+// The anchors are left, right, top, bottom, width, height, centerX and centerY.
+func (n *BaseNode) Constrain(rule string, strength solver.Strength) Node {
+	parsed, err := parseConstraintRule(rule)
+	if err != nil {
+		fmt.Printf("[LAYOUT ERROR] Invalid constraint %q: %v\n", rule, err)
+		return n
+	}
+	parsed.strength = strength
+	n.constraintRules = append(n.constraintRules, parsed)
+	n.reportedRules = nil
+	n.MarkLayoutDirty()
+	return n
+}
+
+// parseConstraintRule splits a rule at its comparison and parses both sides
+func parseConstraintRule(rule string) (constraintRule, error) {
+	parsed := constraintRule{source: rule}
+	for _, op := range []struct {
+		token    string
+		operator solver.Operator
+	}{{"==", solver.Equal}, {"<=", solver.LessOrEqual}, {">=", solver.GreaterOrEqual}} {
+		lhs, rhs, found := strings.Cut(rule, op.token)
+		if !found {
+			continue
+		}
+		var err error
+		if parsed.lhs, err = parseLinearExpr(lhs); err != nil {
+			return parsed, err
+		}
+		if parsed.rhs, err = parseLinearExpr(rhs); err != nil {
+			return parsed, err
+		}
+		parsed.operator = op.operator
+		return parsed, nil
+	}
+	return parsed, fmt.Errorf("expected ==, <= or >=")
+}
+
+// parseLinearExpr parses a sum of terms, each a number, an anchor such as "input.left",
+// or a number times or an anchor divided by a number
+func parseLinearExpr(text string) (linearExpr, error) {
+	tokens := tokenizeRule(text)
+	expr := linearExpr{terms: make(map[anchorRef]float64)}
+	if len(tokens) == 0 {
+		return expr, fmt.Errorf("missing expression")
+	}
+
+	sign := 1.0
+	expectTerm := true
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if !expectTerm {
+			switch token {
+			case "+":
+				sign = 1
+			case "-":
+				sign = -1
+			default:
+				return expr, fmt.Errorf("expected + or - before %q", token)
+			}
+			expectTerm = true
+			continue
+		}
+		if token == "-" {
+			sign = -sign
+			continue
+		}
+
+		// A term is factors joined by * and /, of which at most one is an anchor
+		coefficient := sign
+		var ref *anchorRef
+		for {
+			factor := tokenAt(tokens, i)
+			if value, err := strconv.ParseFloat(factor, 64); err == nil {
+				coefficient *= value
+			} else if anchor, ok := parseAnchor(factor); ok && ref == nil {
+				ref = &anchor
+			} else {
+				return expr, fmt.Errorf("unexpected %q", factor)
+			}
+			for tokenAt(tokens, i+1) == "/" {
+				divisor, err := strconv.ParseFloat(tokenAt(tokens, i+2), 64)
+				if err != nil || divisor == 0 {
+					return expr, fmt.Errorf("can only divide by a number other than 0")
+				}
+				coefficient /= divisor
+				i += 2
+			}
+			if tokenAt(tokens, i+1) != "*" {
+				break
+			}
+			i += 2
+		}
+
+		if ref != nil {
+			expr.terms[*ref] += coefficient
+		} else {
+			expr.constant += coefficient
+		}
+		sign = 1
+		expectTerm = false
+	}
+	if expectTerm {
+		return expr, fmt.Errorf("missing term")
+	}
+	return expr, nil
+}
+
+// tokenAt returns the token at i, or an empty string past the end
+func tokenAt(tokens []string, i int) string {
+	if i < len(tokens) {
+		return tokens[i]
+	}
+	return ""
+}
+
+// tokenizeRule splits an expression into numbers, anchors and the operators + - * /.
+// A - inside an ID, as in "user-name.left", is part of it.
+func tokenizeRule(text string) []string {
+	var tokens []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case r == '-' && isIDPrefix(current.String()):
+			current.WriteRune(r)
+		case strings.ContainsRune("+-*/", r):
+			flush()
+			tokens = append(tokens, string(r))
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// isIDPrefix returns true if the text so far is the start of an ID, before its anchor
+func isIDPrefix(text string) bool {
+	return text != "" && unicode.IsLetter([]rune(text)[0]) && !strings.Contains(text, ".")
+}
+
+// parseAnchor parses an anchor reference of the form id.anchor
+func parseAnchor(token string) (anchorRef, bool) {
+	dot := strings.LastIndex(token, ".")
+	if dot <= 0 || !anchors[token[dot+1:]] {
+		return anchorRef{}, false
+	}
+	return anchorRef{id: token[:dot], anchor: token[dot+1:]}, true
+}
+
+// measureConstraints solves the rules with the children at their preferred sizes and takes
+// the box around them. The content box is as large as the rules need it to be.
+func (n *BaseNode) measureConstraints(children []Node, preferred []style.Size) (minContent, maxContent style.Size) {
+	for _, frame := range n.solveConstraints(children, preferred, nil) {
+		maxContent = largestSize(maxContent, style.Size{
+			Width:  frame.Position.X + frame.Size.Width,
+			Height: frame.Position.Y + frame.Size.Height,
+		})
+	}
+	return maxContent, maxContent
+}
+
+// layoutConstraints lays out each child at its natural size, solves the rules in the content
+// box and lays the children the rules resized out again at their solved sizes
+func (n *BaseNode) layoutConstraints(ctx RenderContext, available style.Size) {
+	children := n.flowChildren()
+	natural := make([]style.Size, len(children))
+	for i, child := range children {
+		natural[i] = child.Layout(ctx, Constraints{
+			MaxWidth:  math.Max(0, available.Width),
+			MaxHeight: math.Max(0, available.Height),
+		})
+	}
+
+	frames := n.solveConstraints(children, natural, &available)
+	for i, child := range children {
+		if frames[i].Size != natural[i] {
+			resizeChild(ctx, child, frames[i].Size, true, true)
+		}
+	}
+	n.gridAreas = frames
+}
+
+// arrangeConstraints places the children at their solved frames in the content box
+func (n *BaseNode) arrangeConstraints(ctx RenderContext, content style.Rect) {
+	children := n.flowChildren()
+	placements := make([]placement, 0, len(children))
+	for i, child := range children {
+		if i >= len(n.gridAreas) {
+			break
+		}
+		frame := n.gridAreas[i]
+		placements = append(placements, placement{node: child, bounds: style.Rect{
+			Position: style.Point{X: content.Position.X + frame.Position.X, Y: content.Position.Y + frame.Position.Y},
+			Size:     child.GetFinalSize(),
+		}})
+	}
+	arrangePlacements(ctx, placements)
+}
+
+// solveConstraints returns the frame of each child in the content box, starting from the
+// given sizes. The size of the content box is fixed when it is known. A rule that
+// contradicts the required rules before it is left out, and reported the first time.
+func (n *BaseNode) solveConstraints(children []Node, sizes []style.Size, content *style.Size) []style.Rect {
+	skipped := make(map[int]bool)
+	for {
+		frames, failed, err := n.trySolveConstraints(children, sizes, content, skipped)
+		if err == nil {
+			return frames
+		}
+		if !n.reportedRules[failed] {
+			fmt.Printf("[LAYOUT ERROR] Ignoring constraint %q: %v\n", n.constraintRules[failed].source, err)
+			if n.reportedRules == nil {
+				n.reportedRules = make(map[int]bool)
+			}
+			n.reportedRules[failed] = true
+		}
+		skipped[failed] = true
+	}
+}
+
+// trySolveConstraints solves the rules that are not skipped, returning the index of the
+// rule that could not be added if one fails
+func (n *BaseNode) trySolveConstraints(children []Node, sizes []style.Size, content *style.Size, skipped map[int]bool) ([]style.Rect, int, error) {
+	s := solver.NewSolver()
+	add := func(expr solver.Expression, operator solver.Operator, strength solver.Strength) {
+		// The built-in constraints never contradict each other
+		_ = s.AddConstraint(solver.NewConstraint(expr, operator, strength))
+	}
+	equals := func(v *solver.Variable, value float64, strength solver.Strength) {
+		add(solver.Expression{Terms: []solver.Term{{Variable: v, Coefficient: 1}}, Constant: -value}, solver.Equal, strength)
+	}
+
+	// Each child has a size of at least 0 and keeps its own size and position if it can
+	ids := make(map[string]boxVars, len(children))
+	boxes := make([]boxVars, len(children))
+	for i, child := range children {
+		box := boxVars{
+			left:   solver.NewVariable("left"),
+			top:    solver.NewVariable("top"),
+			width:  solver.NewVariable("width"),
+			height: solver.NewVariable("height"),
+		}
+		for _, size := range []*solver.Variable{box.width, box.height} {
+			add(solver.Expression{Terms: []solver.Term{{Variable: size, Coefficient: 1}}}, solver.GreaterOrEqual, solver.Required)
+		}
+		for _, position := range []*solver.Variable{box.left, box.top} {
+			add(solver.Expression{Terms: []solver.Term{{Variable: position, Coefficient: 1}}}, solver.GreaterOrEqual, insideStrength)
+		}
+		equals(box.width, sizes[i].Width, naturalSizeStrength)
+		equals(box.height, sizes[i].Height, naturalSizeStrength)
+		equals(box.left, 0, originStrength)
+		equals(box.top, 0, originStrength)
+		boxes[i] = box
+		if id := child.ID(); id != "" {
+			ids[id] = box
+		}
+	}
+
+	// The content box starts at the origin, and its size is only free while measuring
+	parent := boxVars{width: solver.NewVariable("parent.width"), height: solver.NewVariable("parent.height")}
+	if content != nil {
+		equals(parent.width, content.Width, solver.Required)
+		equals(parent.height, content.Height, solver.Required)
+	}
+
+	for i, rule := range n.constraintRules {
+		if skipped[i] {
+			continue
+		}
+		expr, ok := rule.expression(ids, parent)
+		if !ok {
+			continue
+		}
+		if err := s.AddConstraint(solver.NewConstraint(expr, rule.operator, rule.strength)); err != nil {
+			return nil, i, err
+		}
+	}
+	s.UpdateVariables()
+
+	frames := make([]style.Rect, len(children))
+	for i, box := range boxes {
+		frames[i] = style.Rect{
+			Position: style.Point{X: box.left.Value, Y: box.top.Value},
+			Size:     style.Size{Width: math.Max(0, box.width.Value), Height: math.Max(0, box.height.Value)},
+		}
+	}
+	return frames, 0, nil
+}
+
+// expression returns the rule as lhs - rhs for the solver, or false if it names an ID
+// that no child has
+func (r constraintRule) expression(ids map[string]boxVars, parent boxVars) (solver.Expression, bool) {
+	expr := solver.Expression{Constant: r.lhs.constant - r.rhs.constant}
+	for _, side := range []struct {
+		linear linearExpr
+		sign   float64
+	}{{r.lhs, 1}, {r.rhs, -1}} {
+		for ref, coefficient := range side.linear.terms {
+			box, ok := ids[ref.id]
+			if ref.id == "parent" {
+				box, ok = parent, true
+			}
+			if !ok {
+				return expr, false
+			}
+			expr.Terms = append(expr.Terms, box.anchorTerms(ref.anchor, coefficient*side.sign)...)
+		}
+	}
+	return expr, true
+}
+
+// anchorTerms returns an anchor of the box as solver terms. The content box has no
+// position variables, as its left and top are 0.
+func (b boxVars) anchorTerms(anchor string, coefficient float64) []solver.Term {
+	var terms []solver.Term
+	term := func(v *solver.Variable, c float64) {
+		if v != nil {
+			terms = append(terms, solver.Term{Variable: v, Coefficient: c * coefficient})
+		}
+	}
+	switch anchor {
+	case "left":
+		term(b.left, 1)
+	case "top":
+		term(b.top, 1)
+	case "width":
+		term(b.width, 1)
+	case "height":
+		term(b.height, 1)
+	case "right":
+		term(b.left, 1)
+		term(b.width, 1)
+	case "bottom":
+		term(b.top, 1)
+		term(b.height, 1)
+	case "centerX":
+		term(b.left, 1)
+		term(b.width, 0.5)
+	case "centerY":
+		term(b.top, 1)
+		term(b.height, 0.5)
+	}
+	return terms
+}
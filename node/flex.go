@@ -0,0 +1,710 @@
+package node
+
+import (
+	"math"
+
+	"github.com/noahdw/goui/node/style"
+)
+
+// flexItem holds a child's sizes and margins while its container is laid out
+type flexItem struct {
+	node       Node
+	size       style.Size
+	margin     style.EdgeInsets
+	autoMargin style.EdgeInsets // 1 on each side whose margin is auto
+
+	// Main axis sizes used to resolve flexible lengths
+	base         float64
+	hypothetical float64
+	target       float64
+	minMain      float64
+	maxMain      float64
+	grow         float64
+	shrink       float64
+	frozen       bool
+}
+
+// baselineNode is implemented by nodes that can report where their first line of text sits
+type baselineNode interface {
+	firstBaseline() (float64, bool)
+}
+
+// sizedNode is implemented by nodes whose size can be decided by their parent
+type sizedNode interface {
+	layoutAtSize(ctx RenderContext, size style.Size, definiteWidth, definiteHeight bool)
+}
+
+// isRow returns true if the children are laid out horizontally
+func (n *BaseNode) isRow() bool {
+	dir, ok := n.styles.GetString("flexDirection")
+	return !ok || dir != "column"
+}
+
+// flexLine is a run of children laid out together on the main axis
+type flexLine struct {
+	start, end int // range of children on the line
+	cross      float64
+}
+
+// gaps returns the gaps between rows and between columns. Percentages are of the content
+// height and width, which are 0 while the container is measured.
+func (n *BaseNode) gaps(content style.Size) (rowGap, columnGap float64) {
+	rowGap, _ = resolveLength(&n.styles, "rowGap", content.Height)
+	columnGap, _ = resolveLength(&n.styles, "columnGap", content.Width)
+	return rowGap, columnGap
+}
+
+// flexGaps returns the gap between items on the main axis and between lines on the cross axis
+func (n *BaseNode) flexGaps(row bool, content style.Size) (mainGap, crossGap float64) {
+	rowGap, columnGap := n.gaps(content)
+	if row {
+		return columnGap, rowGap
+	}
+	return rowGap, columnGap
+}
+
+// isWrapping returns true if the children may be broken into several lines
+func (n *BaseNode) isWrapping() bool {
+	wrap, _ := n.styles.GetString("flexWrap")
+	return wrap == "wrap" || wrap == "wrap-reverse"
+}
+
+// layoutFlex sizes the children within the available content size.
+// Children are broken into lines when wrapping. On each line they start at their
+// flex basis, then grow into the free space or shrink to remove overflow in proportion
+// to flexGrow and flexShrink, without leaving their min and max sizes. On the cross
+// axis lines share free space by alignContent and children are stretched by alignItems.
+func (n *BaseNode) layoutFlex(ctx RenderContext, available style.Size, definiteWidth, definiteHeight bool) {
+	row := n.isRow()
+	wrap := n.isWrapping()
+	align, _ := n.styles.GetString("alignItems")
+	alignContent, _ := n.styles.GetString("alignContent")
+	mainGap, crossGap := n.flexGaps(row, available)
+	availableMain, availableCross := axes(available, row)
+	definiteMain, definiteCross := definiteWidth, definiteHeight
+	if !row {
+		definiteMain, definiteCross = definiteHeight, definiteWidth
+	}
+
+	// Children are first laid out at their natural size
+	childConstraints := Constraints{
+		MaxWidth:  math.Max(0, available.Width),
+		MaxHeight: math.Max(0, available.Height),
+	}
+	children := n.flowChildren()
+	items := make([]flexItem, len(children))
+	for i, child := range children {
+		constraints := childConstraints
+
+		// A single line child with an aspect ratio and no size of its own is stretched first,
+		// so its main size follows from the cross size
+		childStyles := child.GetStyles()
+		_, hasRatio := aspectRatio(childStyles)
+		unsized := isAutoLength(childStyles, "width") && isAutoLength(childStyles, "height")
+		if hasRatio && unsized && isStretched(child, align, row) && definiteCross && !wrap {
+			margin, _ := childStyles.GetEdgeInsets("margin")
+			_, crossStart, _, crossEnd := marginAxes(margin, row)
+			cross := math.Max(0, availableCross-crossStart-crossEnd)
+			if row {
+				constraints.MinHeight, constraints.MaxHeight = cross, cross
+			} else {
+				constraints.MinWidth, constraints.MaxWidth = cross, cross
+			}
+		}
+		items[i] = n.newFlexItem(ctx, child, constraints, availableMain, row)
+	}
+
+	// Without a definite size the container fits its children on one line, leaving nothing to flex
+	if !definiteMain {
+		availableMain = outerMainSize(items, mainGap, row)
+		wrap = false
+	}
+	lines := breakFlexLines(items, availableMain, mainGap, wrap, row)
+
+	for li := range lines {
+		line := &lines[li]
+		lineItems := items[line.start:line.end]
+		gaps := mainGap * float64(len(lineItems)-1)
+		resolveFlexibleLengths(lineItems, availableMain-gaps, row)
+
+		// Flexed children are laid out at their new main size to find their cross size
+		for i := range lineItems {
+			item := &lineItems[i]
+			naturalMain, cross := axes(item.size, row)
+			if item.target != naturalMain {
+				resizeChild(ctx, item.node, fromAxes(item.target, cross, row), row, !row)
+				item.size = item.node.GetFinalSize()
+			}
+			_, crossStart, _, crossEnd := marginAxes(item.margin, row)
+			_, itemCross := axes(item.size, row)
+			line.cross = math.Max(line.cross, crossStart+itemCross+crossEnd)
+		}
+	}
+
+	// A single line fills a definite container, several lines share its free space
+	if !wrap && definiteCross && len(lines) == 1 {
+		lines[0].cross = availableCross
+	} else if definiteCross && len(lines) > 0 && (alignContent == "" || alignContent == "stretch") {
+		used := crossGap * float64(len(lines)-1)
+		for _, line := range lines {
+			used += line.cross
+		}
+		if free := availableCross - used; free > 0 {
+			for li := range lines {
+				lines[li].cross += free / float64(len(lines))
+			}
+		}
+	}
+
+	// Stretched children fill their line unless they have a cross size of their own
+	// or an aspect ratio to size it from
+	for _, line := range lines {
+		for i := line.start; i < line.end; i++ {
+			item := &items[i]
+			childStyles := item.node.GetStyles()
+			if !isStretched(item.node, align, row) {
+				continue
+			}
+			if _, ok := aspectRatio(childStyles); ok || !isAutoLength(childStyles, crossSizeKey(row)) {
+				continue
+			}
+			_, crossStart, _, crossEnd := marginAxes(item.margin, row)
+			minCross, maxCross := minMaxLengths(childStyles, !row, availableCross)
+			itemMain, _ := axes(item.size, row)
+			item.size = fromAxes(itemMain, clamp(line.cross-crossStart-crossEnd, minCross, maxCross), row)
+			resizeChild(ctx, item.node, item.size, true, true)
+		}
+	}
+
+	n.flexLines = lines
+}
+
+// breakFlexLines splits the items into lines that fit the available main size.
+// Without wrapping all items go on a single line.
+func breakFlexLines(items []flexItem, availableMain, mainGap float64, wrap bool, row bool) []flexLine {
+	if !wrap {
+		return []flexLine{{start: 0, end: len(items)}}
+	}
+
+	var lines []flexLine
+	line := flexLine{}
+	used := 0.0
+	for i, item := range items {
+		mainStart, _, mainEnd, _ := marginAxes(item.margin, row)
+		outer := mainStart + item.hypothetical + mainEnd
+		if i > line.start && used+mainGap+outer > availableMain {
+			line.end = i
+			lines = append(lines, line)
+			line = flexLine{start: i}
+			used = 0
+		}
+		if i > line.start {
+			used += mainGap
+		}
+		used += outer
+	}
+	line.end = len(items)
+	return append(lines, line)
+}
+
+// outerMainSize returns the main size the items take up with their margins and the gaps between them
+func outerMainSize(items []flexItem, mainGap float64, row bool) float64 {
+	used := 0.0
+	for i, item := range items {
+		mainStart, _, mainEnd, _ := marginAxes(item.margin, row)
+		used += mainStart + item.hypothetical + mainEnd
+		if i > 0 {
+			used += mainGap
+		}
+	}
+	return used
+}
+
+// newFlexItem lays out a child at its natural size and works out its flex base size
+func (n *BaseNode) newFlexItem(ctx RenderContext, child Node, constraints Constraints, availableMain float64, row bool) flexItem {
+	childStyles := child.GetStyles()
+	item := flexItem{node: child, size: child.Layout(ctx, constraints), shrink: 1}
+	if m, ok := childStyles.GetEdgeInsets("margin"); ok {
+		item.margin = m
+	}
+	if grow, ok := childStyles.GetFloat("flexGrow"); ok {
+		item.grow = math.Max(0, grow)
+	}
+	if shrink, ok := childStyles.GetFloat("flexShrink"); ok {
+		item.shrink = math.Max(0, shrink)
+	}
+
+	// The basis falls back to the child's own size, explicit or measured
+	item.base, _ = axes(item.size, row)
+	if basis, ok := resolveLength(childStyles, "flexBasis", availableMain); ok {
+		item.base = basis
+	}
+
+	item.minMain, item.maxMain = minMaxLengths(childStyles, row, availableMain)
+
+	// An auto minimum keeps the item from shrinking below its content, or its own size if that is smaller
+	minKey, sizeKey := "minWidth", "width"
+	if !row {
+		minKey, sizeKey = "minHeight", "height"
+	}
+	if isAutoLength(childStyles, minKey) {
+		contentMin, _ := axes(minContentOf(child, item.size), row)
+		if specified, ok := resolveLength(childStyles, sizeKey, availableMain); ok {
+			contentMin = math.Min(contentMin, specified)
+		}
+		item.minMain = math.Min(contentMin, item.maxMain)
+	}
+	item.hypothetical = clamp(item.base, item.minMain, item.maxMain)
+	return item
+}
+
+// resolveFlexibleLengths sets each item's target main size so the items fill availableMain.
+// Items that would leave their min or max size are frozen at it and the remaining
+// space is shared again between the others, as in the CSS flexbox algorithm.
+func resolveFlexibleLengths(items []flexItem, availableMain float64, row bool) {
+	used := 0.0
+	for _, item := range items {
+		mainStart, _, mainEnd, _ := marginAxes(item.margin, row)
+		used += mainStart + item.hypothetical + mainEnd
+	}
+	growing := used < availableMain
+
+	// Items that cannot flex in this direction keep their hypothetical size
+	for i := range items {
+		item := &items[i]
+		item.target = item.hypothetical
+		factor := item.shrink
+		if growing {
+			factor = item.grow
+		}
+		item.frozen = factor == 0 ||
+			(growing && item.base > item.hypothetical) ||
+			(!growing && item.base < item.hypothetical)
+	}
+
+	initialFree := freeSpace(items, availableMain, row)
+	violations := make([]float64, len(items))
+	for {
+		free := freeSpace(items, availableMain, row)
+		sumFactors, sumScaledShrink := 0.0, 0.0
+		unfrozen := 0
+		for _, item := range items {
+			if item.frozen {
+				continue
+			}
+			unfrozen++
+			if growing {
+				sumFactors += item.grow
+			} else {
+				sumFactors += item.shrink
+				sumScaledShrink += item.shrink * item.base
+			}
+		}
+		if unfrozen == 0 {
+			return
+		}
+
+		// Factors that add up to less than one only claim part of the space
+		if sumFactors < 1 && math.Abs(initialFree*sumFactors) < math.Abs(free) {
+			free = initialFree * sumFactors
+		}
+
+		totalViolation := 0.0
+		for i := range items {
+			item := &items[i]
+			if item.frozen {
+				continue
+			}
+			target := item.base
+			if growing {
+				target += free * item.grow / sumFactors
+			} else if sumScaledShrink > 0 {
+				target += free * item.shrink * item.base / sumScaledShrink
+			}
+			clamped := math.Max(0, clamp(target, item.minMain, item.maxMain))
+			violations[i] = clamped - target
+			totalViolation += violations[i]
+			item.target = clamped
+		}
+
+		// Freeze the items whose limits pushed back in the direction of the total violation
+		for i := range items {
+			item := &items[i]
+			if item.frozen {
+				continue
+			}
+			switch {
+			case totalViolation == 0:
+				item.frozen = true
+			case totalViolation > 0 && violations[i] > 0:
+				item.frozen = true
+			case totalViolation < 0 && violations[i] < 0:
+				item.frozen = true
+			}
+		}
+	}
+}
+
+// freeSpace returns the space left after frozen items take their target size and the rest their base size
+func freeSpace(items []flexItem, availableMain float64, row bool) float64 {
+	free := availableMain
+	for _, item := range items {
+		mainStart, _, mainEnd, _ := marginAxes(item.margin, row)
+		free -= mainStart + mainEnd
+		if item.frozen {
+			free -= item.target
+		} else {
+			free -= item.base
+		}
+	}
+	return free
+}
+
+// resizeChild lays a child out again at a size decided by its parent
+func resizeChild(ctx RenderContext, child Node, size style.Size, definiteWidth, definiteHeight bool) {
+	if sized, ok := child.(sizedNode); ok {
+		sized.layoutAtSize(ctx, size, definiteWidth, definiteHeight)
+		return
+	}
+	child.Layout(ctx, Constraints{
+		MinWidth:  size.Width,
+		MaxWidth:  size.Width,
+		MinHeight: size.Height,
+		MaxHeight: size.Height,
+	})
+}
+
+// arrangeFlex positions the laid out children inside the content area, line by line.
+// Lines are placed on the cross axis with alignContent, free space on the main axis
+// is distributed with justifyContent, and children are placed within their line with alignItems.
+func (n *BaseNode) arrangeFlex(ctx RenderContext, content style.Rect) {
+	row := n.isRow()
+	justify, _ := n.styles.GetString("justifyContent")
+	align, _ := n.styles.GetString("alignItems")
+	alignContent, _ := n.styles.GetString("alignContent")
+	wrap, _ := n.styles.GetString("flexWrap")
+	mainGap, crossGap := n.flexGaps(row, content.Size)
+	contentMain, contentCross := axes(content.Size, row)
+
+	children := n.flowChildren()
+	items := make([]flexItem, len(children))
+	for i, child := range children {
+		items[i] = flexItem{node: child, size: child.GetFinalSize(), autoMargin: n.flowMargin(autoMargins(child))}
+		if m, ok := child.GetStyles().GetEdgeInsets("margin"); ok {
+			items[i].margin = n.flowMargin(m)
+		}
+	}
+
+	// Children added since the last layout are arranged as a single line
+	lines := n.flexLines
+	if len(lines) == 0 || lines[len(lines)-1].end != len(items) {
+		lines = []flexLine{{start: 0, end: len(items), cross: contentCross}}
+	}
+
+	usedCross := crossGap * float64(len(lines)-1)
+	for _, line := range lines {
+		usedCross += line.cross
+	}
+	crossOffset, crossSpacing := 0.0, 0.0
+	if alignContent != "stretch" {
+		crossOffset, crossSpacing = justifySpacing(alignContent, contentCross-usedCross, len(lines))
+	}
+
+	// wrap-reverse stacks the lines from the cross end and swaps start and end within them
+	reverse := wrap == "wrap-reverse"
+
+	var placements []placement
+	for _, line := range lines {
+		lineStart := crossOffset
+		if reverse {
+			lineStart = contentCross - crossOffset - line.cross
+		}
+		placements = append(placements, n.arrangeFlexLine(items[line.start:line.end], content, lineStart, line.cross, contentMain, mainGap, justify, align, row, reverse)...)
+		crossOffset += line.cross + crossGap + crossSpacing
+	}
+	n.mirrorPlacements(content, placements)
+	arrangePlacements(ctx, placements)
+}
+
+// arrangeFlexLine returns where the children of one line go, whose cross axis starts at
+// lineStart from the content edge and spans lineCross. Auto margins take up the free space
+// before justifyContent and alignItems can.
+func (n *BaseNode) arrangeFlexLine(items []flexItem, content style.Rect, lineStart, lineCross, contentMain, mainGap float64, justify, align string, row, reverse bool) []placement {
+	usedMain := mainGap * float64(len(items)-1)
+	autoCount := 0.0
+	for _, item := range items {
+		mainStart, _, mainEnd, _ := marginAxes(item.margin, row)
+		itemMain, _ := axes(item.size, row)
+		usedMain += mainStart + itemMain + mainEnd
+		autoStart, _, autoEnd, _ := marginAxes(item.autoMargin, row)
+		autoCount += autoStart + autoEnd
+	}
+	free := contentMain - usedMain
+	offset, spacing, autoSpace := 0.0, 0.0, 0.0
+	if autoCount > 0 && free > 0 {
+		autoSpace = free / autoCount
+	} else {
+		offset, spacing = justifySpacing(justify, free, len(items))
+	}
+	spacing += mainGap
+	placements := make([]placement, 0, len(items))
+
+	// Each child's alignment, with start and end swapped within wrap-reverse lines
+	aligns := make([]string, len(items))
+	for i, item := range items {
+		aligns[i] = alignSelf(item.node, align)
+		if reverse {
+			aligns[i] = reverseAlign(aligns[i])
+		}
+	}
+
+	// Baselines only line up across a row, columns fall back to start
+	maxBaseline := 0.0
+	for i, item := range items {
+		if aligns[i] == "baseline" && row {
+			maxBaseline = math.Max(maxBaseline, item.margin.Top+itemBaseline(item))
+		}
+	}
+
+	for i, item := range items {
+		mainStart, crossStart, mainEnd, crossEnd := marginAxes(item.margin, row)
+		itemMain, itemCross := axes(item.size, row)
+		autoMainStart, autoCrossStart, autoMainEnd, autoCrossEnd := marginAxes(item.autoMargin, row)
+		mainStart += autoMainStart * autoSpace
+		mainEnd += autoMainEnd * autoSpace
+
+		var cross float64
+		if autoCrossStart > 0 || autoCrossEnd > 0 {
+			// Auto margins on the cross axis share the line's free space
+			crossFree := math.Max(0, lineCross-crossStart-itemCross-crossEnd)
+			cross = crossStart + crossFree*autoCrossStart/(autoCrossStart+autoCrossEnd)
+		} else {
+			switch aligns[i] {
+			case "end", "flex-end":
+				cross = lineCross - itemCross - crossEnd
+			case "center":
+				cross = crossStart + (lineCross-crossStart-itemCross-crossEnd)/2
+			case "baseline":
+				if row {
+					cross = maxBaseline - itemBaseline(item)
+				} else {
+					cross = crossStart
+				}
+			default: // "start", "flex-start" and "stretch"
+				cross = crossStart
+			}
+		}
+
+		main := offset + mainStart
+		position := content.Position
+		if row {
+			position.X += main
+			position.Y += lineStart + cross
+		} else {
+			position.X += lineStart + cross
+			position.Y += main
+		}
+		placements = append(placements, placement{node: item.node, bounds: style.Rect{Position: position, Size: item.size}})
+
+		offset += mainStart + itemMain + mainEnd + spacing
+	}
+	return placements
+}
+
+// alignSelf returns how a child is aligned on the cross axis, which is its parent's
+// alignItems unless it sets alignSelf
+func alignSelf(child Node, alignItems string) string {
+	if align, ok := child.GetStyles().GetString("alignSelf"); ok && align != "" && align != "auto" {
+		return align
+	}
+	return alignItems
+}
+
+// reverseAlign swaps start and end alignment, for lines stacked from the cross end
+func reverseAlign(align string) string {
+	switch align {
+	case "start", "flex-start", "stretch":
+		return "end"
+	case "end", "flex-end":
+		return "start"
+	}
+	return align
+}
+
+// isStretched returns true if a child fills its line on the cross axis, which auto margins
+// on that axis prevent
+func isStretched(child Node, alignItems string, row bool) bool {
+	if alignSelf(child, alignItems) != "stretch" {
+		return false
+	}
+	_, autoStart, _, autoEnd := marginAxes(autoMargins(child), row)
+	return autoStart == 0 && autoEnd == 0
+}
+
+// autoMargins returns 1 on each side of a child's margin that is auto and 0 on the others,
+// so that they can be counted along an axis with marginAxes
+func autoMargins(child Node) style.EdgeInsets {
+	var auto style.EdgeInsets
+	top, right, bottom, left := child.GetStyles().AutoEdges("margin")
+	if top {
+		auto.Top = 1
+	}
+	if right {
+		auto.Right = 1
+	}
+	if bottom {
+		auto.Bottom = 1
+	}
+	if left {
+		auto.Left = 1
+	}
+	return auto
+}
+
+// justifySpacing returns where the first item starts on the main axis and the extra space
+// between items. As with CSS's default unsafe alignment, overflowing content aligned to the
+// end or center overflows the start too, like it does on the cross axis. Space is only
+// distributed when there is some left, otherwise the items start at the start.
+func justifySpacing(justify string, free float64, count int) (offset, spacing float64) {
+	switch justify {
+	case "end", "flex-end":
+		return free, 0
+	case "center":
+		return free / 2, 0
+	case "space-between":
+		if free > 0 && count > 1 {
+			return 0, free / float64(count-1)
+		}
+	case "space-around":
+		if free > 0 && count > 0 {
+			spacing = free / float64(count)
+			return spacing / 2, spacing
+		}
+	case "space-evenly":
+		if free > 0 && count > 0 {
+			spacing = free / float64(count+1)
+			return spacing, spacing
+		}
+	}
+	return 0, 0
+}
+
+// itemBaseline returns the baseline of an item measured from its top edge.
+// Items without text use their bottom edge.
+func itemBaseline(item flexItem) float64 {
+	if b, ok := item.node.(baselineNode); ok {
+		if baseline, ok := b.firstBaseline(); ok {
+			return baseline
+		}
+	}
+	return item.size.Height
+}
+
+// firstBaseline returns the baseline of the node's text, or of its first child
+func (n *BaseNode) firstBaseline() (float64, bool) {
+	if _, ok := n.styles.GetString("text"); ok {
+		return textBaseline(&n.styles, n.finalSize.Height), true
+	}
+
+	children := n.flowChildren()
+	if len(children) == 0 {
+		return 0, false
+	}
+	child, ok := children[0].(baselineNode)
+	if !ok {
+		return 0, false
+	}
+	baseline, ok := child.firstBaseline()
+	if !ok {
+		return 0, false
+	}
+
+	// The first child starts after the padding and its own margin
+	padding, _ := n.styles.GetEdgeInsets("padding")
+	margin, _ := children[0].GetStyles().GetEdgeInsets("margin")
+	return padding.Top + margin.Top + baseline, true
+}
+
+func (n *TextNode) firstBaseline() (float64, bool) {
+	return textBaseline(&n.styles, n.finalSize.Height), true
+}
+
+// textBaseline returns the baseline of text drawn in a box of the given height,
+// matching the vertical placement used when drawing text
+func textBaseline(styles *style.Styles, height float64) float64 {
+	fontSize, _ := styles.GetFloat("fontSize")
+	padding, _ := styles.GetEdgeInsets("padding")
+	alignItems, _ := styles.GetString("alignItems")
+	lineHeight := fontSize * 1.2
+
+	top := padding.Top
+	switch alignItems {
+	case "center":
+		top = (height - lineHeight) / 2
+	case "bottom":
+		top = height - lineHeight - padding.Bottom
+	}
+	return top + fontSize
+}
+
+// resolveLength resolves a length style against the available space.
+// It returns false for auto and unset lengths.
+func resolveLength(styles *style.Styles, key string, available float64) (float64, bool) {
+	return styles.ResolveLength(key, available)
+}
+
+// minMaxLengths returns the min and max size of a node along one axis.
+// Unset maximums are unbounded.
+func minMaxLengths(styles *style.Styles, horizontal bool, available float64) (min, max float64) {
+	minKey, maxKey := "minWidth", "maxWidth"
+	if !horizontal {
+		minKey, maxKey = "minHeight", "maxHeight"
+	}
+	min, _ = resolveLength(styles, minKey, available)
+	max, ok := resolveLength(styles, maxKey, available)
+	if !ok || max < min {
+		max = math.Inf(1)
+		if ok {
+			max = min
+		}
+	}
+	return min, max
+}
+
+// isAutoLength returns true if the length style is auto or unset
+func isAutoLength(styles *style.Styles, key string) bool {
+	_, ok := resolveLength(styles, key, 0)
+	return !ok
+}
+
+// crossSizeKey returns the size style on the cross axis
+func crossSizeKey(row bool) string {
+	if row {
+		return "height"
+	}
+	return "width"
+}
+
+// fromAxes builds a size from its main and cross axis components
+func fromAxes(main, cross float64, row bool) style.Size {
+	if row {
+		return style.Size{Width: main, Height: cross}
+	}
+	return style.Size{Width: cross, Height: main}
+}
+
+// axes splits a size into its main and cross axis components
+func axes(size style.Size, row bool) (main, cross float64) {
+	if row {
+		return size.Width, size.Height
+	}
+	return size.Height, size.Width
+}
+
+// marginAxes returns the margins at the start and end of the main and cross axes
+func marginAxes(margin style.EdgeInsets, row bool) (mainStart, crossStart, mainEnd, crossEnd float64) {
+	if row {
+		return margin.Left, margin.Top, margin.Right, margin.Bottom
+	}
+	return margin.Top, margin.Left, margin.Bottom, margin.Right
+}
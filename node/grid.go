@@ -0,0 +1,484 @@
+package node
+
+import (
+	"math"
+	"sort"
+
+	"github.com/noahdw/goui/node/style"
+)
+
+// gridItem is a child and the tracks it covers while its grid is laid out
+type gridItem struct {
+	node       Node
+	size       style.Size
+	margin     style.EdgeInsets
+	column     int // first column, counted from 0
+	row        int // first row, counted from 0
+	columnSpan int
+	rowSpan    int
+}
+
+// isGrid returns true if the children are laid out on a grid instead of flex lines
+func (n *BaseNode) isGrid() bool {
+	display, _ := n.styles.GetString("display")
+	return display == "grid"
+}
+
+// gridTracks returns a grid template, parsing it if it was set as a string
+func (n *BaseNode) gridTracks(key string) []style.GridTrack {
+	value, ok := n.styles.Get(key)
+	if !ok {
+		return nil
+	}
+	switch v := value.(type) {
+	case []style.GridTrack:
+		return v
+	case string:
+		tracks, _ := style.ParseGridTemplate(v)
+		return tracks
+	}
+	return nil
+}
+
+// gridPlacement returns where a child asked to be placed on one axis
+func gridPlacement(styles *style.Styles, key string) style.GridPlacement {
+	value, ok := styles.Get(key)
+	if !ok {
+		return style.GridPlacement{Span: 1}
+	}
+	switch v := value.(type) {
+	case style.GridPlacement:
+		if v.Span < 1 {
+			v.Span = 1
+		}
+		return v
+	case string:
+		if placement, err := style.ParseGridPlacement(v); err == nil {
+			return placement
+		}
+	case int:
+		return style.GridPlacement{Start: v, Span: 1}
+	case float64:
+		return style.GridPlacement{Start: int(v), Span: 1}
+	}
+	return style.GridPlacement{Span: 1}
+}
+
+// layoutGrid places the children on the grid and sizes its tracks within the available content size.
+// Columns are sized first so that rows can be sized from the children's heights at their final widths.
+func (n *BaseNode) layoutGrid(ctx RenderContext, available style.Size, definiteWidth, definiteHeight bool) {
+	rowGap, columnGap := n.gaps(available)
+	align, _ := n.styles.GetString("alignItems")
+	columns := n.gridTracks("gridTemplateColumns")
+	rows := n.gridTracks("gridTemplateRows")
+
+	items, columnCount, rowCount := placeGridItems(n.flowChildren(), len(columns), len(rows))
+
+	columns = withImplicitTracks(columns, columnCount)
+	rows = withImplicitTracks(rows, rowCount)
+
+	constraints := Constraints{
+		MaxWidth:  math.Max(0, available.Width),
+		MaxHeight: math.Max(0, available.Height),
+	}
+	for i := range items {
+		items[i].size = items[i].node.Layout(ctx, constraints)
+	}
+	columnSizes := sizeGridTracks(columns, items, available.Width, definiteWidth, columnGap, true)
+
+	// Children without a width of their own fill their columns
+	for i := range items {
+		item := &items[i]
+		if !isAutoLength(item.node.GetStyles(), "width") {
+			continue
+		}
+		width := spanSize(columnSizes, item.column, item.columnSpan, columnGap) - item.margin.Left - item.margin.Right
+		minWidth, maxWidth := minMaxLengths(item.node.GetStyles(), true, available.Width)
+		item.size = style.Size{Width: clamp(math.Max(0, width), minWidth, maxWidth), Height: item.size.Height}
+		resizeChild(ctx, item.node, item.size, true, false)
+		item.size = item.node.GetFinalSize()
+	}
+	rowSizes := sizeGridTracks(rows, items, available.Height, definiteHeight, rowGap, false)
+
+	areas := make([]style.Rect, len(items))
+	columnStarts := trackStarts(columnSizes, columnGap)
+	rowStarts := trackStarts(rowSizes, rowGap)
+	for i := range items {
+		item := &items[i]
+		areas[i] = style.Rect{
+			Position: style.Point{X: columnStarts[item.column], Y: rowStarts[item.row]},
+			Size: style.Size{
+				Width:  spanSize(columnSizes, item.column, item.columnSpan, columnGap),
+				Height: spanSize(rowSizes, item.row, item.rowSpan, rowGap),
+			},
+		}
+
+		// Stretched children fill their rows unless they have a height of their own or an aspect ratio
+		_, hasRatio := aspectRatio(item.node.GetStyles())
+		if alignSelf(item.node, align) == "stretch" && !hasRatio && isAutoLength(item.node.GetStyles(), "height") {
+			height := areas[i].Size.Height - item.margin.Top - item.margin.Bottom
+			minHeight, maxHeight := minMaxLengths(item.node.GetStyles(), false, available.Height)
+			item.size.Height = clamp(math.Max(0, height), minHeight, maxHeight)
+			resizeChild(ctx, item.node, item.size, true, true)
+		}
+	}
+
+	n.gridAreas = areas
+}
+
+// withImplicitTracks extends a template to count tracks. Tracks outside the template are
+// implicit and sized to their content.
+func withImplicitTracks(tracks []style.GridTrack, count int) []style.GridTrack {
+	autoTrack := style.GridTrack{Min: style.StyleValue{Type: style.AUTO}, Max: style.StyleValue{Type: style.AUTO}}
+	for len(tracks) < count {
+		tracks = append(tracks, autoTrack)
+	}
+	return tracks
+}
+
+// placeGridItems assigns each child its tracks. Children with an explicit row and column
+// are placed first, then those with only a row, and the rest flow into the first free
+// cells in row order. It returns the number of columns and rows the items need.
+func placeGridItems(children []Node, columnCount, rowCount int) ([]gridItem, int, int) {
+	items := make([]gridItem, len(children))
+	columnPlacements := make([]style.GridPlacement, len(children))
+	rowPlacements := make([]style.GridPlacement, len(children))
+
+	// The explicit columns are widened to fit every explicit column placement and span
+	columnCount = max(columnCount, 1)
+	for i, child := range children {
+		childStyles := child.GetStyles()
+		columnPlacements[i] = gridPlacement(childStyles, "gridColumn")
+		rowPlacements[i] = gridPlacement(childStyles, "gridRow")
+
+		items[i] = gridItem{
+			node:       child,
+			column:     -1,
+			row:        -1,
+			columnSpan: columnPlacements[i].Span,
+			rowSpan:    rowPlacements[i].Span,
+		}
+		if m, ok := childStyles.GetEdgeInsets("margin"); ok {
+			items[i].margin = m
+		}
+		columnCount = max(columnCount, columnPlacements[i].Start-1+columnPlacements[i].Span, columnPlacements[i].Span)
+	}
+
+	occupied := make(map[[2]int]bool)
+	fits := func(item gridItem, row, column int) bool {
+		if column < 0 || column+item.columnSpan > columnCount {
+			return false
+		}
+		for r := row; r < row+item.rowSpan; r++ {
+			for c := column; c < column+item.columnSpan; c++ {
+				if occupied[[2]int{r, c}] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	place := func(item *gridItem, row, column int) {
+		item.row, item.column = row, column
+		for r := row; r < row+item.rowSpan; r++ {
+			for c := column; c < column+item.columnSpan; c++ {
+				occupied[[2]int{r, c}] = true
+			}
+		}
+		rowCount = max(rowCount, row+item.rowSpan)
+	}
+
+	// Explicitly placed items may overlap each other
+	for i := range items {
+		if rowPlacements[i].Start > 0 && columnPlacements[i].Start > 0 {
+			place(&items[i], rowPlacements[i].Start-1, columnPlacements[i].Start-1)
+		}
+	}
+
+	// Items locked to a row take the first free column in it
+	for i := range items {
+		if rowPlacements[i].Start == 0 || columnPlacements[i].Start > 0 {
+			continue
+		}
+		row := rowPlacements[i].Start - 1
+		column := 0
+		for !fits(items[i], row, column) && column+items[i].columnSpan < columnCount {
+			column++
+		}
+		place(&items[i], row, column)
+	}
+
+	// The remaining items flow forward from a cursor, never backfilling earlier holes
+	cursorRow, cursorColumn := 0, 0
+	for i := range items {
+		item := &items[i]
+		if item.row >= 0 {
+			continue
+		}
+
+		if start := columnPlacements[i].Start; start > 0 {
+			column := start - 1
+			if column < cursorColumn {
+				cursorRow++
+			}
+			for !fits(*item, cursorRow, column) {
+				cursorRow++
+			}
+			place(item, cursorRow, column)
+			cursorColumn = column + item.columnSpan
+			continue
+		}
+
+		for {
+			if cursorColumn+item.columnSpan > columnCount {
+				cursorRow++
+				cursorColumn = 0
+			}
+			if fits(*item, cursorRow, cursorColumn) {
+				break
+			}
+			cursorColumn++
+		}
+		place(item, cursorRow, cursorColumn)
+		cursorColumn += item.columnSpan
+	}
+
+	return items, columnCount, rowCount
+}
+
+// sizeGridTracks works out the size of each track on one axis.
+// Tracks start at their fixed minimum, auto minimums grow to fit the items in them,
+// and any space left in a definite container goes to fixed maximums, then to
+// fractions, or to auto tracks when there are no fractions.
+func sizeGridTracks(tracks []style.GridTrack, items []gridItem, available float64, definite bool, gap float64, horizontal bool) []float64 {
+	sizes := make([]float64, len(tracks))
+	for i, track := range tracks {
+		if length, ok := trackLength(track.Min, available, definite); ok {
+			sizes[i] = length
+		}
+	}
+
+	// Items covering fewer tracks are fitted first, so spanning items only add what is still missing
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return itemSpan(items[order[a]], horizontal) < itemSpan(items[order[b]], horizontal)
+	})
+	for _, i := range order {
+		item := items[i]
+		start, span := item.row, item.rowSpan
+		contribution := item.size.Height + item.margin.Top + item.margin.Bottom
+		if horizontal {
+			start, span = item.column, item.columnSpan
+			contribution = item.size.Width + item.margin.Left + item.margin.Right
+		}
+
+		var autoTracks []int
+		for t := start; t < start+span; t++ {
+			if _, fixed := trackLength(tracks[t].Min, available, definite); !fixed {
+				autoTracks = append(autoTracks, t)
+			}
+		}
+		missing := contribution - spanSize(sizes, start, span, gap)
+		if missing <= 0 || len(autoTracks) == 0 {
+			continue
+		}
+		for _, t := range autoTracks {
+			sizes[t] += missing / float64(len(autoTracks))
+		}
+	}
+
+	totalFr := 0.0
+	for _, track := range tracks {
+		if track.Max.Type == style.FRACTION {
+			totalFr += trackValue(track.Max)
+		}
+	}
+
+	// Without a definite size, fractions keep the proportions of their content
+	if !definite {
+		if totalFr > 0 {
+			fraction := 0.0
+			for i, track := range tracks {
+				if fr := trackValue(track.Max); track.Max.Type == style.FRACTION && fr > 0 {
+					fraction = math.Max(fraction, sizes[i]/fr)
+				}
+			}
+			for i, track := range tracks {
+				if track.Max.Type == style.FRACTION {
+					sizes[i] = math.Max(sizes[i], fraction*trackValue(track.Max))
+				}
+			}
+		}
+		return sizes
+	}
+
+	free := available - spanSize(sizes, 0, len(sizes), gap)
+
+	// Grow tracks towards their fixed maximums, sharing the space equally
+	for free > 0 {
+		var growable []int
+		for i, track := range tracks {
+			if limit, ok := trackLength(track.Max, available, definite); ok && limit > sizes[i] {
+				growable = append(growable, i)
+			}
+		}
+		if len(growable) == 0 {
+			break
+		}
+		share := free / float64(len(growable))
+		for _, i := range growable {
+			limit, _ := trackLength(tracks[i].Max, available, definite)
+			grow := math.Min(share, limit-sizes[i])
+			sizes[i] += grow
+			free -= grow
+		}
+	}
+
+	if totalFr > 0 {
+		// Fractions share the space left by the other tracks. A track whose content
+		// is larger than its share keeps its size and the rest is shared again.
+		flexible := make([]bool, len(tracks))
+		for i, track := range tracks {
+			flexible[i] = track.Max.Type == style.FRACTION
+		}
+		for {
+			leftover := available - gap*float64(max(len(tracks)-1, 0))
+			sumFr := 0.0
+			for i, track := range tracks {
+				if flexible[i] {
+					sumFr += trackValue(track.Max)
+				} else {
+					leftover -= sizes[i]
+				}
+			}
+			fraction := leftover / math.Max(sumFr, 1)
+
+			changed := false
+			for i, track := range tracks {
+				if flexible[i] && sizes[i] > fraction*trackValue(track.Max) {
+					flexible[i] = false
+					changed = true
+				}
+			}
+			if !changed {
+				for i, track := range tracks {
+					if flexible[i] {
+						sizes[i] = math.Max(sizes[i], fraction*trackValue(track.Max))
+					}
+				}
+				return sizes
+			}
+		}
+	}
+
+	// Without fractions, auto tracks stretch to fill the container
+	var autoTracks []int
+	for i, track := range tracks {
+		if track.Max.Type == style.AUTO {
+			autoTracks = append(autoTracks, i)
+		}
+	}
+	if free > 0 && len(autoTracks) > 0 {
+		for _, i := range autoTracks {
+			sizes[i] += free / float64(len(autoTracks))
+		}
+	}
+	return sizes
+}
+
+// trackLength resolves a pixel or percentage track size.
+// Percentages of an indefinite size, fractions and auto have no fixed length.
+func trackLength(value style.StyleValue, available float64, definite bool) (float64, bool) {
+	switch value.Type {
+	case style.PIXEL:
+		return trackValue(value), true
+	case style.PERCENTAGE:
+		if definite {
+			return available * trackValue(value) / 100, true
+		}
+	}
+	return 0, false
+}
+
+// trackValue returns the number in a track size
+func trackValue(value style.StyleValue) float64 {
+	switch v := value.Value.(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	}
+	return 0
+}
+
+// itemSpan returns how many tracks an item covers on one axis
+func itemSpan(item gridItem, horizontal bool) int {
+	if horizontal {
+		return item.columnSpan
+	}
+	return item.rowSpan
+}
+
+// spanSize returns the size of a run of tracks including the gaps between them
+func spanSize(sizes []float64, start, span int, gap float64) float64 {
+	total := 0.0
+	for i := start; i < start+span && i < len(sizes); i++ {
+		total += sizes[i]
+		if i > start {
+			total += gap
+		}
+	}
+	return total
+}
+
+// trackStarts returns the offset of each track from the start of the grid
+func trackStarts(sizes []float64, gap float64) []float64 {
+	starts := make([]float64, len(sizes))
+	offset := 0.0
+	for i, size := range sizes {
+		starts[i] = offset
+		offset += size + gap
+	}
+	return starts
+}
+
+// arrangeGrid positions the laid out children in their grid areas.
+// alignItems or a child's alignSelf places children that do not fill their rows.
+func (n *BaseNode) arrangeGrid(ctx RenderContext, content style.Rect) {
+	align, _ := n.styles.GetString("alignItems")
+
+	children := n.flowChildren()
+	placements := make([]placement, 0, len(children))
+	for i, child := range children {
+		// Children added since the last layout wait for the next one at the grid origin
+		area := style.Rect{}
+		if i < len(n.gridAreas) {
+			area = n.gridAreas[i]
+		}
+
+		size := child.GetFinalSize()
+		margin, _ := child.GetStyles().GetEdgeInsets("margin")
+		margin = n.flowMargin(margin)
+
+		y := area.Position.Y + margin.Top
+		switch alignSelf(child, align) {
+		case "end", "flex-end":
+			y = area.Position.Y + area.Size.Height - size.Height - margin.Bottom
+		case "center":
+			y = area.Position.Y + margin.Top + (area.Size.Height-margin.Top-size.Height-margin.Bottom)/2
+		}
+
+		placements = append(placements, placement{node: child, bounds: style.Rect{
+			Position: style.Point{
+				X: content.Position.X + area.Position.X + margin.Left,
+				Y: content.Position.Y + y,
+			},
+			Size: size,
+		}})
+	}
+	n.mirrorPlacements(content, placements)
+	arrangePlacements(ctx, placements)
+}
@@ -0,0 +1,230 @@
+package node
+
+import (
+	"math"
+
+	"github.com/noahdw/goui/node/style"
+)
+
+// intrinsicNode is implemented by nodes that know their min-content size, the smallest
+// size they can take without their content overflowing
+type intrinsicNode interface {
+	minContentSize() style.Size
+}
+
+func (n *BaseNode) minContentSize() style.Size {
+	return n.minContent
+}
+
+// minContentOf returns a node's min-content size, or its preferred size if it has none
+func minContentOf(n Node, preferred style.Size) style.Size {
+	if intrinsic, ok := n.(intrinsicNode); ok {
+		return intrinsic.minContentSize()
+	}
+	return preferred
+}
+
+// minContentContribution returns the min-content size a child adds to its parent,
+// which is its own size on an axis where it has one
+func minContentContribution(child Node, preferred style.Size) style.Size {
+	size := minContentOf(child, preferred)
+	styles := child.GetStyles()
+	if _, ok := intrinsicLength(styles, "width"); ok {
+		size.Width = preferred.Width
+	}
+	if _, ok := intrinsicLength(styles, "height"); ok {
+		size.Height = preferred.Height
+	}
+	return clampIntrinsic(styles, size)
+}
+
+// intrinsicLength resolves a length that does not depend on the space available.
+// Percentages, also inside calc(), are treated as auto as there is nothing to resolve them against yet.
+func intrinsicLength(styles *style.Styles, key string) (float64, bool) {
+	value, ok := styles.GetValue(key)
+	if !ok || style.IsPercentageBased(value) {
+		return 0, false
+	}
+	return resolveLength(styles, key, 0)
+}
+
+// clampIntrinsic limits a measured size to the pixel and em min and max sizes in styles
+func clampIntrinsic(styles *style.Styles, size style.Size) style.Size {
+	if max, ok := intrinsicLength(styles, "maxWidth"); ok {
+		size.Width = math.Min(size.Width, max)
+	}
+	if max, ok := intrinsicLength(styles, "maxHeight"); ok {
+		size.Height = math.Min(size.Height, max)
+	}
+	if min, ok := intrinsicLength(styles, "minWidth"); ok {
+		size.Width = math.Max(size.Width, min)
+	}
+	if min, ok := intrinsicLength(styles, "minHeight"); ok {
+		size.Height = math.Max(size.Height, min)
+	}
+	return size
+}
+
+// aspectRatio returns the width to height ratio a node keeps, if it has one
+func aspectRatio(styles *style.Styles) (float64, bool) {
+	ratio, ok := styles.GetFloat("aspectRatio")
+	return ratio, ok && ratio > 0
+}
+
+// transferAspectRatio sizes an indefinite axis from the definite one using the node's
+// aspect ratio. Without either, the height follows the width the content gave it.
+func transferAspectRatio(styles *style.Styles, size style.Size, definiteWidth, definiteHeight bool) (style.Size, bool, bool) {
+	ratio, ok := aspectRatio(styles)
+	if !ok || (definiteWidth && definiteHeight) {
+		return size, definiteWidth, definiteHeight
+	}
+	if definiteHeight {
+		size.Width = size.Height * ratio
+		return size, true, true
+	}
+	size.Height = size.Width / ratio
+	return size, definiteWidth, true
+}
+
+// measureContent measures the children and returns the content size without padding.
+// The max-content size lays every child out at its preferred size, the min-content size
+// at its min-content size.
+func (n *BaseNode) measureContent(ctx RenderContext) (minContent, maxContent style.Size) {
+	if n.isTable() {
+		return n.measureTable(ctx)
+	}
+
+	measured := make(map[Node]style.Size, len(n.children))
+	for _, child := range n.children {
+		measured[child] = child.MeasurePreferred(ctx)
+	}
+	children := n.flowChildren()
+	preferred := make([]style.Size, len(children))
+	for i, child := range children {
+		preferred[i] = measured[child]
+	}
+
+	if len(children) == 0 {
+		return style.Size{}, style.Size{}
+	}
+	if n.isGrid() {
+		return n.measureGrid(children, preferred)
+	}
+	if n.isStack() {
+		return measureStack(children, preferred)
+	}
+	if n.isConstraintLayout() {
+		return n.measureConstraints(children, preferred)
+	}
+	return n.measureFlex(children, preferred)
+}
+
+// measureFlex adds up the children along the main axis and takes the largest across it.
+// A wrapping container can put every child on its own line, so its min-content main
+// size is that of its largest child. Its height once wrapped is only known after layout.
+func (n *BaseNode) measureFlex(children []Node, preferred []style.Size) (minContent, maxContent style.Size) {
+	row := n.isRow()
+	wrap := n.isWrapping()
+	mainGap, _ := n.flexGaps(row, style.Size{})
+
+	var minMain, minCross, maxMain, maxCross float64
+	for i, child := range children {
+		margin, _ := child.GetStyles().GetEdgeInsets("margin")
+		childMaxMain, childMaxCross := axes(outerSize(preferred[i], margin), row)
+		childMinMain, childMinCross := axes(outerSize(minContentContribution(child, preferred[i]), margin), row)
+
+		if i > 0 {
+			maxMain += mainGap
+		}
+		maxMain += childMaxMain
+		maxCross = math.Max(maxCross, childMaxCross)
+		minCross = math.Max(minCross, childMinCross)
+
+		if wrap {
+			minMain = math.Max(minMain, childMinMain)
+		} else {
+			if i > 0 {
+				minMain += mainGap
+			}
+			minMain += childMinMain
+		}
+	}
+	return fromAxes(minMain, minCross, row), fromAxes(maxMain, maxCross, row)
+}
+
+// measureGrid sizes the tracks of an indefinite grid around the children
+func (n *BaseNode) measureGrid(children []Node, preferred []style.Size) (minContent, maxContent style.Size) {
+	rowGap, columnGap := n.gaps(style.Size{})
+	columns := n.gridTracks("gridTemplateColumns")
+	rows := n.gridTracks("gridTemplateRows")
+
+	items, columnCount, rowCount := placeGridItems(children, len(columns), len(rows))
+	columns = withImplicitTracks(columns, columnCount)
+	rows = withImplicitTracks(rows, rowCount)
+
+	measure := func(contentSize func(child Node, preferred style.Size) style.Size) style.Size {
+		for i := range items {
+			items[i].size = contentSize(items[i].node, preferred[i])
+		}
+		columnSizes := sizeGridTracks(columns, items, 0, false, columnGap, true)
+		rowSizes := sizeGridTracks(rows, items, 0, false, rowGap, false)
+		return style.Size{
+			Width:  spanSize(columnSizes, 0, len(columnSizes), columnGap),
+			Height: spanSize(rowSizes, 0, len(rowSizes), rowGap),
+		}
+	}
+
+	maxContent = measure(func(child Node, preferred style.Size) style.Size { return preferred })
+	minContent = measure(minContentContribution)
+	return minContent, maxContent
+}
+
+// outerSize grows a size by its margins
+func outerSize(size style.Size, margin style.EdgeInsets) style.Size {
+	return style.Size{
+		Width:  size.Width + margin.Left + margin.Right,
+		Height: size.Height + margin.Top + margin.Bottom,
+	}
+}
+
+// fitContent returns the size a node with an auto length takes on one axis: its max-content
+// size, limited to the space available but never below its min-content size
+func fitContent(minContent, maxContent, available float64) float64 {
+	return math.Min(maxContent, math.Max(minContent, available))
+}
+
+// contentExtent returns the size the laid out children take up within the available
+// content size, which an auto height fits once lines have wrapped at the final width
+func (n *BaseNode) contentExtent(available style.Size) style.Size {
+	if n.isGrid() || n.isTable() || n.isConstraintLayout() {
+		var extent style.Size
+		for _, area := range n.gridAreas {
+			extent.Width = math.Max(extent.Width, area.Position.X+area.Size.Width)
+			extent.Height = math.Max(extent.Height, area.Position.Y+area.Size.Height)
+		}
+		return extent
+	}
+	if n.isStack() {
+		return n.stackExtent()
+	}
+
+	row := n.isRow()
+	mainGap, crossGap := n.flexGaps(row, available)
+	children := n.flowChildren()
+	var main, cross float64
+	for _, line := range n.flexLines {
+		if line.end > len(children) {
+			break
+		}
+		lineMain := mainGap * float64(max(line.end-line.start-1, 0))
+		for _, child := range children[line.start:line.end] {
+			margin, _ := child.GetStyles().GetEdgeInsets("margin")
+			childMain, _ := axes(outerSize(child.GetFinalSize(), margin), row)
+			lineMain += childMain
+		}
+		main = math.Max(main, lineMain)
+		cross += line.cross
+	}
+	cross += crossGap * float64(max(len(n.flexLines)-1, 0))
+	return fromAxes(main, cross, row)
+}
@@ -0,0 +1,269 @@
+package node
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/noahdw/goui/node/style"
+)
+
+// layerCounter hands out unique keys for retained layers
+var layerCounter atomic.Uint64
+
+// newLayerKey returns a key that identifies a node's retained layer
+func newLayerKey() string {
+	return fmt.Sprintf("node-layer-%d", layerCounter.Add(1))
+}
+
+// ancestorsMu guards the walks that mark ancestors dirty, since subtrees laid out in
+// parallel share the ancestors above them
+var ancestorsMu sync.Mutex
+
+// layoutCacheSize is the number of sizes a node remembers its layout for. Parents often lay
+// a child out once to see how big it wants to be and again at the size they give it.
+const layoutCacheSize = 4
+
+// layoutKey is what a node's layout depends on besides its subtree
+type layoutKey struct {
+	size           style.Size
+	definiteWidth  bool
+	definiteHeight bool
+	padding        style.EdgeInsets
+}
+
+// layoutEntry is the size a node came out at when laid out for a key
+type layoutEntry struct {
+	key    layoutKey
+	result style.Size
+}
+
+// layoutCache remembers the sizes a node was recently laid out at and the sizes that came out,
+// so that a node whose subtree has not changed is not laid out again for the same size.
+// The children are only placed for the computed key, so a node last asked for another
+// key is laid out again before it is arranged.
+type layoutCache struct {
+	entries   []layoutEntry
+	computed  layoutKey
+	requested layoutKey
+	valid     bool
+}
+
+// MarkStyleDirty marks the node's styles as needing to be resolved again, e.g. after a
+// state or style change. Ancestors are told so that the next layout pass finds the node.
+func (n *BaseNode) MarkStyleDirty() {
+	n.stylesValid = false
+	ancestorsMu.Lock()
+	defer ancestorsMu.Unlock()
+	for parent := n.parent; parent != nil; parent = parent.Parent() {
+		ancestor, ok := parent.(interface{ markDescendantStyleDirty() bool })
+		if !ok || !ancestor.markDescendantStyleDirty() {
+			return
+		}
+	}
+}
+
+// markDescendantStyleDirty records that a descendant needs its styles resolved.
+// It returns false if this was already known, which means the ancestors know too.
+func (n *BaseNode) markDescendantStyleDirty() bool {
+	if n.descendantStyleDirty {
+		return false
+	}
+	n.descendantStyleDirty = true
+	return true
+}
+
+// MarkLayoutDirty marks the node as needing to be measured and laid out again.
+// Its ancestors lay out their children again, where the clean ones reuse their cached sizes.
+func (n *BaseNode) MarkLayoutDirty() {
+	n.layoutValid = false
+	ancestorsMu.Lock()
+	defer ancestorsMu.Unlock()
+	for parent := n.parent; parent != nil; parent = parent.Parent() {
+		ancestor, ok := parent.(interface{ markDescendantLayoutDirty() bool })
+		if !ok || !ancestor.markDescendantLayoutDirty() {
+			return
+		}
+	}
+}
+
+// markDescendantLayoutDirty records that a descendant needs laying out.
+// It returns false if this was already known, which means the ancestors know too.
+func (n *BaseNode) markDescendantLayoutDirty() bool {
+	if n.descendantLayoutDirty {
+		return false
+	}
+	n.descendantLayoutDirty = true
+	return true
+}
+
+// NeedsLayout returns true if styles must be resolved or sizes computed anywhere in the subtree
+func (n *BaseNode) NeedsLayout() bool {
+	return !n.stylesValid || n.descendantStyleDirty || n.needsLayout()
+}
+
+// needsLayout returns true if the node or a descendant has to be laid out again
+func (n *BaseNode) needsLayout() bool {
+	return !n.layoutValid || n.descendantLayoutDirty
+}
+
+// layoutKey returns the key for laying the node out at size
+func (n *BaseNode) layoutKey(size style.Size, definiteWidth, definiteHeight bool) layoutKey {
+	padding, _ := n.styles.GetEdgeInsets("padding")
+	return layoutKey{size: size, definiteWidth: definiteWidth, definiteHeight: definiteHeight, padding: padding}
+}
+
+// cachedLayout returns the size the node came out at when it was laid out for key,
+// if nothing in the subtree changed since
+func (n *BaseNode) cachedLayout(key layoutKey) (style.Size, bool) {
+	if !n.layoutCache.valid || n.needsLayout() {
+		return style.Size{}, false
+	}
+	for _, entry := range n.layoutCache.entries {
+		if entry.key == key {
+			n.layoutCache.requested = key
+			return entry.result, true
+		}
+	}
+	return style.Size{}, false
+}
+
+// storeLayout records the result of laying out the node, which leaves its subtree clean
+func (n *BaseNode) storeLayout(key layoutKey) {
+	cache := &n.layoutCache
+	if !cache.valid || n.needsLayout() {
+		cache.entries = cache.entries[:0]
+	}
+	entries := append([]layoutEntry{{key: key, result: n.finalSize}}, cache.entries...)
+	for i := 1; i < len(entries); i++ {
+		if entries[i].key == key {
+			entries = append(entries[:i], entries[i+1:]...)
+			break
+		}
+	}
+	cache.entries = entries[:min(len(entries), layoutCacheSize)]
+	cache.computed, cache.requested, cache.valid = key, key, true
+
+	n.layoutValid = true
+	n.descendantLayoutDirty = false
+	n.arrangeValid = false
+}
+
+// restoreLayout lays the node out again for the key it was last asked for when its children
+// were placed for another one
+func (n *BaseNode) restoreLayout(ctx RenderContext) {
+	cache := n.layoutCache
+	if !cache.valid || cache.requested == cache.computed {
+		return
+	}
+	n.layoutValid = false
+	n.layoutAtSize(ctx, cache.requested.size, cache.requested.definiteWidth, cache.requested.definiteHeight)
+}
+
+// setStyle sets a style property from a builder method and marks the node for restyling,
+// and for layout unless the property only changes how the node is painted
+func (n *BaseNode) setStyle(key string, value interface{}) {
+	n.styles.Set(key, value)
+	n.MarkStyleDirty()
+
+	// A breakpoint still overrides the value, which is put back when the breakpoint stops matching
+	if _, ok := n.breakpointBase[key]; ok {
+		n.breakpointBase[key] = value
+	}
+	if style.IsLayoutProperty(key) {
+		n.MarkLayoutDirty()
+	}
+
+	// The change has already been made, so restyling cannot tell that children inherit it
+	if key == "direction" || key == "fontSize" || slices.Contains(inheritableProps, key) {
+		for _, child := range n.children {
+			child.MarkStyleDirty()
+		}
+	}
+}
+
+// MarkPaintDirty marks the node as needing to be repainted.
+// Ancestors are told that something below them changed so that retained
+// layers above the node are re-rendered and damage collection can find it.
+func (n *BaseNode) MarkPaintDirty() {
+	n.paintDirty = true
+
+	ancestorsMu.Lock()
+	defer ancestorsMu.Unlock()
+	for parent := n.parent; parent != nil; parent = parent.Parent() {
+		ancestor, ok := parent.(interface{ markDescendantPaintDirty() bool })
+		if !ok || !ancestor.markDescendantPaintDirty() {
+			return
+		}
+	}
+}
+
+// markDescendantPaintDirty records that a descendant needs repainting.
+// It returns false if this was already known, which means the ancestors know too.
+func (n *BaseNode) markDescendantPaintDirty() bool {
+	if n.descendantPaintDirty {
+		return false
+	}
+	n.descendantPaintDirty = true
+	return true
+}
+
+// NeedsPaint returns true if the node or any of its descendants needs repainting
+func (n *BaseNode) NeedsPaint() bool {
+	return n.paintDirty || n.descendantPaintDirty
+}
+
+// CollectDamage appends the screen areas that must be repainted for this subtree.
+// A dirty node damages the area it was last painted at as well as its current bounds.
+func (n *BaseNode) CollectDamage(damage []style.Rect) []style.Rect {
+	if n.paintDirty {
+		if n.painted && !n.paintedBounds.IsEmpty() {
+			damage = append(damage, n.paintedBounds)
+		}
+		if bounds := n.visualBounds(); !bounds.IsEmpty() {
+			damage = append(damage, bounds)
+		}
+	}
+
+	if n.descendantPaintDirty {
+		for _, child := range n.children {
+			damage = child.CollectDamage(damage)
+		}
+	}
+	return damage
+}
+
+// ClearPaintDirty records that the subtree has been painted at its current bounds
+func (n *BaseNode) ClearPaintDirty() {
+	if n.descendantPaintDirty {
+		for _, child := range n.children {
+			child.ClearPaintDirty()
+		}
+	}
+
+	n.paintDirty = false
+	n.descendantPaintDirty = false
+	n.painted = true
+	n.paintedBounds = n.visualBounds()
+}
+
+// visualBounds returns the area the node draws to, which filters can extend past its bounds
+func (n *BaseNode) visualBounds() style.Rect {
+	filters := n.filters()
+	if len(filters) == 0 {
+		return n.finalBounds
+	}
+
+	outset := style.FilterOutset(filters)
+	return style.Rect{
+		Position: style.Point{
+			X: n.finalBounds.Position.X - outset.Left,
+			Y: n.finalBounds.Position.Y - outset.Top,
+		},
+		Size: style.Size{
+			Width:  n.finalBounds.Size.Width + outset.Left + outset.Right,
+			Height: n.finalBounds.Size.Height + outset.Top + outset.Bottom,
+		},
+	}
+}
@@ -1,792 +1,1159 @@
-package node
-
-import (
-	"fmt"
-
-	rl "github.com/gen2brain/raylib-go/raylib"
-	"github.com/noahdw/goui/node/style"
-)
-
-type Node interface {
-	StyleBuilder // Include all style builder methods
-
-	// Style methods
-	GetStyle(key string) (interface{}, bool)
-	GetStyleFloat(key string) (float64, bool)
-	GetStyleString(key string) (string, bool)
-	GetStyleColor(key string) (style.Color, bool)
-	GetStyleEdgeInsets(key string) (style.EdgeInsets, bool)
-
-	// Structure methods
-	AddChildren(children ...Node)
-	Children() []Node
-	GetType() string
-	GetStyles() *style.Styles
-	Intersects(node Node) bool
-	Parent() Node
-	SetParent(parent Node)
-	DispatchEvent(event UIEvent)
-
-	// Layout methods
-	ResolveStyles(parentStyles style.Styles) style.Styles
-	MeasurePreferred(ctx RenderContext) style.Size
-	Layout(ctx RenderContext, constraints Constraints) style.Size
-	ArrangeChildren(ctx RenderContext, bounds style.Rect)
-	Paint(ctx RenderContext)
-	GetFinalSize() style.Size
-	GetFinalBounds() style.Rect
-
-	// State management methods
-	ID() string
-	SetID(id string) Node
-	GetState() NodeState
-	SetState(state string, value bool) Node
-	OnStateChange(state string, callback func(StateChange)) Node
-	NotifyStateChange(state string, value bool)
-}
-
-// NodeState represents the current state of a node
-type NodeState struct {
-	IsHovered    bool
-	IsActive     bool
-	IsFocused    bool
-	IsDisabled   bool
-	CustomStates map[string]bool
-}
-
-// StateChange represents a state change event
-type StateChange struct {
-	NodeID string
-	State  string
-	Value  bool
-}
-
-type BaseNode struct {
-	nodeType       string
-	parent         Node
-	children       []Node
-	styles         style.Styles
-	finalSize      style.Size
-	finalBounds    style.Rect
-	preferredSize  style.Size
-	finalOpacity   float64
-	eventCallbacks map[UIEventType]func(UIEvent)
-	id             string
-	state          NodeState
-	stateListeners map[string][]func(StateChange)
-}
-
-type Event struct {
-}
-
-func NewBaseNode(nodeType string, styles style.Styles) BaseNode {
-	return BaseNode{
-		nodeType:       nodeType,
-		styles:         styles,
-		eventCallbacks: make(map[UIEventType]func(UIEvent)),
-	}
-}
-
-// NewBaseNodeWithStyles creates a new base node with the given styles
-func NewBaseNodeWithStyles(nodeType string, styles style.Styles) Node {
-	return &BaseNode{
-		nodeType:       nodeType,
-		styles:         styles,
-		eventCallbacks: make(map[UIEventType]func(UIEvent)),
-	}
-}
-
-// NewBaseNodeWithProps creates a new base node with the given properties
-func NewBaseNodeWithProps(nodeType string, props map[string]interface{}) Node {
-	styles := style.NewStyles(props)
-
-	// If there were any style errors, create an error node to display them
-	if errorVal, ok := styles.Get("error"); ok {
-		if errorMsg, ok := errorVal.(string); ok {
-			errorStyles := style.NewStyles(map[string]interface{}{
-				"background": style.Red,
-				"color":      style.White,
-				"padding":    style.EdgeInsets{10, 10, 10, 10},
-				"width":      400,
-				"height":     100,
-			})
-			errorBase := BaseNode{
-				nodeType:       "error",
-				styles:         errorStyles,
-				eventCallbacks: make(map[UIEventType]func(UIEvent)),
-			}
-			errorText := TextNode{
-				BaseNode: errorBase,
-				text:     fmt.Sprintf("Style Error: %s", errorMsg),
-			}
-			return &errorText
-		}
-	}
-
-	return &BaseNode{
-		nodeType:       nodeType,
-		styles:         styles,
-		eventCallbacks: make(map[UIEventType]func(UIEvent)),
-	}
-}
-
-// Constraints represents size constraints
-type Constraints struct {
-	MinWidth, MaxWidth, MinHeight, MaxHeight float64
-}
-
-// RenderContext provides context for rendering
-type RenderContext interface {
-	LoadTexture(sourceURL string) rl.Texture2D
-	Present()
-	Save()
-	Restore()
-	SetOpacity(opacity float64)
-	SetFillColor(color style.Color)
-	SetStrokeColor(color style.Color)
-	SetLineWidth(width float64)
-	StrokeLine(start style.Point, end style.Point)
-	SetFontSize(size float64)
-	DrawText(text string, bounds style.Rect, styles style.Styles, opacity float64)
-	DrawBackground(bounds style.Rect, styles style.Styles, opacity float64)
-	DrawBorders(bounds style.Rect, styles style.Styles, opacity float64)
-	DrawTexture(sourceURL string, bounds style.Rect, styles style.Styles, opacity float64)
-	FillRect(rect style.Rect)
-	Scale(x, y float64)
-	Clear()
-	ClipRect() style.Rect
-	SetClipRect(rect style.Rect)
-}
-
-func (n *BaseNode) GetStyle(key string) (interface{}, bool) {
-	return n.styles.Get(key)
-}
-
-func (n *BaseNode) GetStyleFloat(key string) (float64, bool) {
-	return n.styles.GetFloat(key)
-}
-
-func (n *BaseNode) GetStyleString(key string) (string, bool) {
-	return n.styles.GetString(key)
-}
-
-func (n *BaseNode) GetStyleColor(key string) (style.Color, bool) {
-	return n.styles.GetColor(key)
-}
-
-func (n *BaseNode) GetStyleEdgeInsets(key string) (style.EdgeInsets, bool) {
-	return n.styles.GetEdgeInsets(key)
-}
-
-func (n *BaseNode) Parent() Node {
-	return n.parent
-}
-
-func (n *BaseNode) SetParent(parent Node) {
-	n.parent = parent
-}
-
-// Structure methods
-func (n *BaseNode) AddChildren(children ...Node) {
-	// We catch the event nodes and style event nodes being added and rather than add them as children to this node,
-	// we filter them out and incorporate their callbacks/styles into this node.
-	// This allows for a more declarative UI construction where events and styles are treated as first-class nodes.
-	var finalChildren []Node
-	for _, child := range children {
-		if eventNode, ok := child.(*EventNode); ok {
-			// Register the event callback with this node
-			n.eventCallbacks[eventNode.eventType] = eventNode.callback
-		} else if styleNode, ok := child.(*BaseNode); ok && styleNode.nodeType == "style_handler" {
-			// Incorporate the style changes into this node's styles
-			if stateStyles := styleNode.styles.GetStateStyle("all"); stateStyles != nil {
-				n.styles.AddStateStyle("all", stateStyles)
-			}
-		} else {
-			child.SetParent(n)
-			finalChildren = append(finalChildren, child)
-		}
-	}
-	n.children = append(n.children, finalChildren...)
-}
-
-func (n *BaseNode) Children() []Node {
-	return n.children
-}
-
-func (n *BaseNode) GetType() string {
-	return n.nodeType
-}
-
-// GetStyles returns a pointer to the node's styles
-func (n *BaseNode) GetStyles() *style.Styles {
-	return &n.styles
-}
-
-// Layout methods
-func (n *BaseNode) ResolveStyles(parentStyles style.Styles) style.Styles {
-	// Start with this node's styles
-	resolvedStyles := *n.GetStyles()
-
-	// For inheritable properties, check if they're set in this node
-	// If not, inherit from parent
-	inheritableProps := []string{
-		"fontFamily", "fontSize", "color", "lineHeight", "background", "opacity",
-	}
-
-	for _, prop := range inheritableProps {
-		// Only inherit if:
-		// 1. Parent has the property set (explicitly or inherited)
-		// 2. This node doesn't have it explicitly set
-		_, parentHasIt := parentStyles.Get(prop)
-		selfHasIt := resolvedStyles.IsExplicit(prop)
-
-		if parentHasIt && !selfHasIt {
-			if value, ok := parentStyles.Get(prop); ok {
-				resolvedStyles.Set(prop, value)
-			}
-		}
-	}
-
-	// Restore original styles if no states are active
-	if !n.state.IsHovered && !n.state.IsActive && !n.state.IsFocused && !n.state.IsDisabled {
-		resolvedStyles.RestoreOriginalStyles()
-	} else {
-		// Apply state-based styles
-		if n.state.IsHovered {
-			if hoverStyle := resolvedStyles.GetStateStyle("hover"); hoverStyle != nil {
-				applyStateStyle(&resolvedStyles, hoverStyle)
-			}
-		}
-		if n.state.IsActive {
-			if activeStyle := resolvedStyles.GetStateStyle("active"); activeStyle != nil {
-				applyStateStyle(&resolvedStyles, activeStyle)
-			}
-		}
-		if n.state.IsFocused {
-			if focusStyle := resolvedStyles.GetStateStyle("focus"); focusStyle != nil {
-				applyStateStyle(&resolvedStyles, focusStyle)
-			}
-		}
-		if n.state.IsDisabled {
-			if disabledStyle := resolvedStyles.GetStateStyle("disabled"); disabledStyle != nil {
-				applyStateStyle(&resolvedStyles, disabledStyle)
-			}
-		}
-	}
-
-	// Calculate final opacity
-	if opacity, ok := resolvedStyles.GetFloat("opacity"); ok {
-		if parentOpacity, ok := parentStyles.GetFloat("opacity"); ok {
-			resolvedStyles.SetFinalOpacity(opacity * parentOpacity)
-		} else {
-			resolvedStyles.SetFinalOpacity(opacity)
-		}
-	} else {
-		resolvedStyles.SetFinalOpacity(parentStyles.GetFinalOpacity())
-	}
-	n.finalOpacity = resolvedStyles.GetFinalOpacity()
-
-	// Apply the same process to all children
-	for _, child := range n.children {
-		child.ResolveStyles(resolvedStyles)
-	}
-	*n.GetStyles() = resolvedStyles
-	return resolvedStyles
-}
-
-// applyStateStyle applies a state style variation to the base styles
-func applyStateStyle(base *style.Styles, state *style.Styles) {
-	// Apply all explicitly set properties from the state style
-	inheritableProps := []string{
-		"fontFamily", "fontSize", "color", "lineHeight", "background", "opacity",
-	}
-	for _, prop := range inheritableProps {
-		if state.IsExplicit(prop) {
-			// Store original value if not already stored
-			if value, ok := base.Get(prop); ok {
-				base.StoreOriginalValue(prop, value)
-			}
-			// Apply new value
-			if value, ok := state.Get(prop); ok {
-				base.Set(prop, value)
-			}
-		}
-	}
-}
-
-func (n *BaseNode) Layout(ctx RenderContext, constraints Constraints) style.Size {
-	// Start with preferred size
-	preferredSize := n.preferredSize
-
-	// Apply constraints to determine final size
-	finalSize := style.Size{
-		Width:  clamp(preferredSize.Width, constraints.MinWidth, constraints.MaxWidth),
-		Height: clamp(preferredSize.Height, constraints.MinHeight, constraints.MaxHeight),
-	}
-
-	// Handle special cases like Fill Parent
-	if width, ok := n.styles.Get("width"); ok {
-		if widthValue, ok := width.(style.StyleValue); ok && widthValue.Type == style.PERCENTAGE {
-			finalSize.Width = constraints.MaxWidth * widthValue.Value.(float64) / 100
-		}
-	}
-
-	if height, ok := n.styles.Get("height"); ok {
-		if heightValue, ok := height.(style.StyleValue); ok && heightValue.Type == style.PERCENTAGE {
-			finalSize.Height = constraints.MaxHeight * heightValue.Value.(float64) / 100
-		}
-	}
-
-	// If we have children, layout them now
-	if len(n.children) > 0 {
-		// Calculate available space for children (minus padding)
-		availableWidth := finalSize.Width
-		availableHeight := finalSize.Height
-
-		if padding, ok := n.styles.GetEdgeInsets("padding"); ok {
-			availableWidth -= padding.Left + padding.Right
-			availableHeight -= padding.Top + padding.Bottom
-		}
-
-		// Create child constraints based on layout direction
-		childConstraints := Constraints{
-			MinWidth:  0,
-			MaxWidth:  availableWidth,
-			MinHeight: 0,
-			MaxHeight: availableHeight,
-		}
-
-		// Layout each child
-		for _, child := range n.children {
-			_ = child.Layout(ctx, childConstraints)
-		}
-	}
-
-	// Store final size
-	n.finalSize = finalSize
-	return finalSize
-}
-
-func (n *BaseNode) ArrangeChildren(ctx RenderContext, bounds style.Rect) {
-	// Set this node's bounds
-	n.finalBounds = bounds
-
-	// For leaf nodes, we're done
-	if len(n.children) == 0 {
-		return
-	}
-
-	// Calculate content area (bounds minus padding)
-	contentArea := style.Rect{
-		Position: style.Point{
-			X: bounds.Position.X,
-			Y: bounds.Position.Y,
-		},
-		Size: style.Size{
-			Width:  bounds.Size.Width,
-			Height: bounds.Size.Height,
-		},
-	}
-
-	// Apply padding if present
-	if padding, ok := n.styles.GetEdgeInsets("padding"); ok {
-		contentArea.Position.X += padding.Left
-		contentArea.Position.Y += padding.Top
-		contentArea.Size.Width -= padding.Left + padding.Right
-		contentArea.Size.Height -= padding.Top + padding.Bottom
-	}
-
-	// Position each child based on layout direction
-	var currentX = contentArea.Position.X
-	var currentY = contentArea.Position.Y
-
-	// Get flex direction
-	flexDir := "row" // default
-	if dir, ok := n.styles.GetString("flexDirection"); ok {
-		flexDir = dir
-	}
-
-	for _, child := range n.children {
-		childSize := child.GetFinalSize()
-		childStyles := child.GetStyles()
-
-		// Get margins for current child
-		var margin style.EdgeInsets
-		if m, ok := childStyles.GetEdgeInsets("margin"); ok {
-			margin = m
-		}
-
-		if flexDir == "row" {
-			// Position child horizontally
-			childBounds := style.Rect{
-				Position: style.Point{
-					X: currentX + margin.Left,
-					Y: currentY + margin.Top,
-				},
-				Size: childSize,
-			}
-			child.ArrangeChildren(ctx, childBounds)
-
-			// Update currentX for next child
-			currentX += childSize.Width + margin.Left + margin.Right
-		} else { // column
-			// Position child vertically
-			childBounds := style.Rect{
-				Position: style.Point{
-					X: currentX + margin.Left,
-					Y: currentY + margin.Top,
-				},
-				Size: childSize,
-			}
-			child.ArrangeChildren(ctx, childBounds)
-
-			// Update currentY for next child
-			currentY += childSize.Height + margin.Top + margin.Bottom
-		}
-	}
-}
-
-func (n *BaseNode) GetFinalSize() style.Size {
-	return n.finalSize
-}
-
-func (n *BaseNode) GetFinalBounds() style.Rect {
-	return n.finalBounds
-}
-
-func (n *BaseNode) Paint(ctx RenderContext) {
-	// Apply opacity if set
-	opacity, _ := n.styles.GetFloat("opacity")
-	if opacity < 1.0 {
-		ctx.Save()
-		ctx.SetOpacity(opacity)
-		defer ctx.Restore()
-	}
-
-	// Apply scale if set
-	scale, _ := n.styles.GetFloat("scale")
-	if scale != 1.0 {
-		ctx.Save()
-		ctx.Scale(scale, scale)
-		defer ctx.Restore()
-	}
-
-	// Draw background if set
-	if bgColor, ok := n.styles.GetColor("background"); ok {
-		ctx.SetFillColor(bgColor)
-		ctx.DrawBackground(n.finalBounds, n.styles, opacity)
-	}
-
-	// Draw border if set
-	if border, ok := n.styles.Get("border"); ok {
-		if borderStyle, ok := border.(style.BorderStyle); ok && borderStyle.CanDisplay() {
-			ctx.DrawBorders(n.finalBounds, n.styles, opacity)
-		}
-	}
-
-	// Draw text if set
-	if text, ok := n.styles.GetString("text"); ok {
-		if fontSize, ok := n.styles.GetFloat("fontSize"); ok {
-			if textColor, ok := n.styles.GetColor("color"); ok {
-				ctx.SetFontSize(fontSize)
-				ctx.SetFillColor(textColor)
-				ctx.DrawText(text, n.finalBounds, n.styles, opacity)
-			}
-		}
-	}
-
-	// Paint children
-	for _, child := range n.children {
-		child.Paint(ctx)
-	}
-}
-
-// TextNode is a specialized node for text content
-type TextNode struct {
-	BaseNode
-	text string
-}
-
-func NewTextNode(baseNode BaseNode, text string) Node {
-	return &TextNode{
-		BaseNode: baseNode,
-		text:     text,
-	}
-}
-
-// Specialized implementation for TextNode
-func (n *TextNode) MeasurePreferred(ctx RenderContext) style.Size {
-	fontSize, _ := n.styles.GetFloat("fontSize")
-	padding, _ := n.styles.GetEdgeInsets("padding")
-	textWidth := float64(rl.MeasureText(n.text, int32(fontSize)))
-	textHeight := fontSize * 1.2 // Add some line height
-
-	// Add padding to the text size
-	return style.Size{
-		Width:  textWidth + padding.Left + padding.Right,
-		Height: textHeight + padding.Top + padding.Bottom,
-	}
-}
-
-func (n *TextNode) Paint(ctx RenderContext) {
-	// Draw background and border first
-	ctx.DrawBackground(n.finalBounds, n.styles, n.finalOpacity)
-
-	// Draw borders if needed
-	if border, ok := n.styles.Get("border"); ok {
-		if borderStyle, ok := border.(style.BorderStyle); ok && borderStyle.CanDisplay() {
-			ctx.DrawBorders(n.finalBounds, n.styles, n.finalOpacity)
-		}
-	}
-
-	// Then draw the text
-	ctx.DrawText(n.text, n.finalBounds, n.styles, n.finalOpacity)
-}
-
-type ImageNode struct {
-	BaseNode
-	sourceURL string
-}
-
-func NewImageNode(baseNode Node, sourceURL string) Node {
-	if baseNode == nil {
-		return nil
-	}
-	// Convert the Node to BaseNode
-	base, ok := baseNode.(*BaseNode)
-	if !ok {
-		// If we can't convert, create an error node
-		errorNode := NewBaseNodeWithProps("error", map[string]interface{}{
-			"background": style.Red,
-			"color":      style.White,
-			"padding":    style.EdgeInsets{10, 10, 10, 10},
-			"width":      400,
-			"height":     100,
-		})
-		if errorText, ok := errorNode.(*TextNode); ok {
-			errorText.text = "Invalid node type for ImageNode"
-		}
-		return errorNode
-	}
-	imageNode := ImageNode{
-		BaseNode:  *base,
-		sourceURL: sourceURL,
-	}
-	return &imageNode
-}
-
-type EventNode struct {
-	BaseNode
-	callback  func(UIEvent)
-	eventType UIEventType
-}
-
-func NewEventNode(baseNode Node, eventType UIEventType, callback func(UIEvent)) Node {
-	if baseNode == nil {
-		return nil
-	}
-	// Convert the Node to BaseNode
-	base, ok := baseNode.(*BaseNode)
-	if !ok {
-		// If we can't convert, create an error node
-		errorNode := NewBaseNodeWithProps("error", map[string]interface{}{
-			"background": style.Red,
-			"color":      style.White,
-			"padding":    style.EdgeInsets{10, 10, 10, 10},
-			"width":      400,
-			"height":     100,
-		})
-		if errorText, ok := errorNode.(*TextNode); ok {
-			errorText.text = "Invalid node type for EventNode"
-		}
-		return errorNode
-	}
-	eventNode := EventNode{
-		BaseNode:  *base,
-		callback:  callback,
-		eventType: eventType,
-	}
-	return &eventNode
-}
-
-func (n *ImageNode) Paint(ctx RenderContext) {
-	// Draw background and border first
-	ctx.DrawBackground(n.finalBounds, n.styles, n.finalOpacity)
-
-	// Draw borders if needed
-	if border, ok := n.styles.Get("border"); ok {
-		if borderStyle, ok := border.(style.BorderStyle); ok && borderStyle.CanDisplay() {
-			ctx.DrawBorders(n.finalBounds, n.styles, n.finalOpacity)
-		}
-	}
-
-	// Then draw the image
-	ctx.DrawTexture(n.sourceURL, n.finalBounds, n.styles, n.finalOpacity)
-}
-
-func (n *ImageNode) MeasurePreferred(ctx RenderContext) style.Size {
-	texture := ctx.LoadTexture(n.sourceURL)
-	n.preferredSize = style.Size{
-		Width:  float64(texture.Width),
-		Height: float64(texture.Height),
-	}
-	return n.preferredSize
-}
-
-// Helper function to clamp a value between min and max
-func clamp(value, min, max float64) float64 {
-	if value < min {
-		return min
-	}
-	if value > max {
-		return max
-	}
-	return value
-}
-
-func (b *BaseNode) Intersects(node Node) bool {
-	return b.finalBounds.Intersects(node.GetFinalBounds())
-}
-
-// RemoveEventHandler removes an event handler for the given event type
-func (n *BaseNode) RemoveEventHandler(eventType UIEventType) {
-	delete(n.eventCallbacks, eventType)
-}
-
-// DispatchEvent dispatches an event to this node and its parent chain
-func (n *BaseNode) DispatchEvent(event UIEvent) {
-	fmt.Printf("[DEBUG] Dispatching event %s to node %s\n", event.Type, n.nodeType)
-
-	// Set the target if not already set
-	if event.Target == nil {
-		event.Target = n
-	}
-
-	// Handle built-in events
-	switch event.Type {
-	case UIEnter:
-		fmt.Printf("[DEBUG] Setting hover state to true for node %s\n", n.nodeType)
-		n.SetState("hover", true)
-	case UILeave:
-		fmt.Printf("[DEBUG] Setting hover state to false for node %s\n", n.nodeType)
-		n.SetState("hover", false)
-	case UIPress:
-		fmt.Printf("[DEBUG] Setting active state to true for node %s\n", n.nodeType)
-		n.SetState("active", true)
-	case UIRelease:
-		fmt.Printf("[DEBUG] Setting active state to false for node %s\n", n.nodeType)
-		n.SetState("active", false)
-	case UIFocus:
-		n.SetState("focus", true)
-	case UIBlur:
-		n.SetState("focus", false)
-	}
-
-	// Call the handler if one exists
-	if handler, has := n.eventCallbacks[event.Type]; has {
-		fmt.Printf("[DEBUG] Calling event handler for %s on node %s\n", event.Type, n.nodeType)
-		handler(event)
-		if event.StopPropagation {
-			return
-		}
-	}
-
-	// Propagate to parent
-	if parent := n.parent; parent != nil {
-		parent.DispatchEvent(event)
-	}
-}
-
-// State management implementations for BaseNode
-func (n *BaseNode) ID() string {
-	return n.id
-}
-
-func (n *BaseNode) SetID(id string) Node {
-	n.id = id
-	return n
-}
-
-func (n *BaseNode) GetState() NodeState {
-	return n.state
-}
-
-func (n *BaseNode) SetState(state string, value bool) Node {
-	fmt.Printf("[DEBUG] Setting state %s=%v for node %s\n", state, value, n.nodeType)
-
-	// Handle built-in states
-	switch state {
-	case "hover":
-		n.state.IsHovered = value
-		fmt.Printf("[DEBUG] Hover state is now %v for node %s\n", n.state.IsHovered, n.nodeType)
-	case "active":
-		n.state.IsActive = value
-		fmt.Printf("[DEBUG] Active state is now %v for node %s\n", n.state.IsActive, n.nodeType)
-	case "focus":
-		n.state.IsFocused = value
-	case "disabled":
-		n.state.IsDisabled = value
-	default:
-		// Handle custom states
-		if n.state.CustomStates == nil {
-			n.state.CustomStates = make(map[string]bool)
-		}
-		n.state.CustomStates[state] = value
-	}
-
-	// Notify listeners
-	n.NotifyStateChange(state, value)
-
-	// Mark layout as dirty to trigger re-render
-	if parent := n.Parent(); parent != nil {
-		if renderEngine, ok := parent.(interface{ MarkLayoutDirty() }); ok {
-			renderEngine.MarkLayoutDirty()
-		}
-	}
-
-	return n
-}
-
-func (n *BaseNode) OnStateChange(state string, callback func(StateChange)) Node {
-	if n.stateListeners == nil {
-		n.stateListeners = make(map[string][]func(StateChange))
-	}
-	n.stateListeners[state] = append(n.stateListeners[state], callback)
-	return n
-}
-
-func (n *BaseNode) NotifyStateChange(state string, value bool) {
-	if n.stateListeners == nil {
-		return
-	}
-
-	change := StateChange{
-		NodeID: n.id,
-		State:  state,
-		Value:  value,
-	}
-
-	// Notify all listeners for this state
-	if listeners, ok := n.stateListeners[state]; ok {
-		for _, listener := range listeners {
-			listener(change)
-		}
-	}
-
-	// Also notify listeners for "all" state changes
-	if listeners, ok := n.stateListeners["all"]; ok {
-		for _, listener := range listeners {
-			listener(change)
-		}
-	}
-}
-
-// Debug logging function
-func debugLog(format string, args ...interface{}) {
-	fmt.Printf("[DEBUG] "+format+"\n", args...)
-}
-
-func (n *BaseNode) MeasurePreferred(ctx RenderContext) style.Size {
-	// Default implementation returns zero size
-	return style.Size{Width: 0, Height: 0}
-}
+package node
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/noahdw/goui/node/style"
+)
+
+type Node interface {
+	StyleBuilder // Include all style builder methods
+
+	// Style methods
+	GetStyle(key string) (interface{}, bool)
+	GetStyleFloat(key string) (float64, bool)
+	GetStyleString(key string) (string, bool)
+	GetStyleColor(key string) (style.Color, bool)
+	GetStyleEdgeInsets(key string) (style.EdgeInsets, bool)
+
+	// Structure methods
+	AddChildren(children ...Node)
+	Children() []Node
+	GetType() string
+	GetStyles() *style.Styles
+	Intersects(node Node) bool
+	Parent() Node
+	SetParent(parent Node)
+	DispatchEvent(event UIEvent)
+
+	// Layout methods
+	ResolveStyles(parentStyles style.Styles) style.Styles
+	MeasurePreferred(ctx RenderContext) style.Size
+	Layout(ctx RenderContext, constraints Constraints) style.Size
+	ArrangeChildren(ctx RenderContext, bounds style.Rect)
+	Paint(ctx RenderContext)
+	GetFinalSize() style.Size
+	GetFinalBounds() style.Rect
+
+	// Style and layout invalidation methods
+	MarkStyleDirty()
+	MarkLayoutDirty()
+	NeedsLayout() bool
+
+	// Paint invalidation methods
+	MarkPaintDirty()
+	NeedsPaint() bool
+	CollectDamage(damage []style.Rect) []style.Rect
+	ClearPaintDirty()
+
+	// Scrolling methods
+	ScrollTo(x, y float64) Node
+	ScrollBy(dx, dy float64) Node
+	ScrollOffset() style.Point
+	ScrollIntoView() Node
+
+	// State management methods
+	ID() string
+	SetID(id string) Node
+	GetState() NodeState
+	SetState(state string, value bool) Node
+	OnStateChange(state string, callback func(StateChange)) Node
+	NotifyStateChange(state string, value bool)
+}
+
+// NodeState represents the current state of a node
+type NodeState struct {
+	IsHovered    bool
+	IsActive     bool
+	IsFocused    bool
+	IsDisabled   bool
+	CustomStates map[string]bool
+}
+
+// StateChange represents a state change event
+type StateChange struct {
+	NodeID string
+	State  string
+	Value  bool
+}
+
+type BaseNode struct {
+	nodeType       string
+	parent         Node
+	children       []Node
+	styles         style.Styles
+	finalSize      style.Size
+	finalBounds    style.Rect
+	preferredSize  style.Size
+	minContent     style.Size
+	flexLines      []flexLine
+	gridAreas      []style.Rect
+	viewport       style.Size
+	finalOpacity   float64
+	eventCallbacks map[UIEventType]func(UIEvent)
+	id             string
+	state          NodeState
+	stateListeners map[string][]func(StateChange)
+
+	// Style and layout invalidation
+	stylesValid           bool
+	descendantStyleDirty  bool
+	layoutValid           bool
+	descendantLayoutDirty bool
+	arrangeValid          bool
+	layoutCache           layoutCache
+
+	// Paint invalidation
+	paintDirty           bool
+	descendantPaintDirty bool
+	painted              bool
+	paintedBounds        style.Rect
+	layerKey             string
+	paintingLayers       bool
+
+	// Scrolling
+	scrollOffset    style.Point
+	scrollExtent    style.Size
+	hasScrollExtent bool
+	scrollDrag      scrollDrag
+	scrolled        func()  // Called after the scroll offset changed
+	stuck           float64 // How far a sticky node moved to stay in view
+
+	// Responsive styles
+	breakpoints    []breakpointStyle
+	breakpointBase map[string]interface{} // Values the matching breakpoints replaced, nil where they added one
+
+	// Constraint layout
+	constraintRules []constraintRule
+	reportedRules   map[int]bool // Rules already reported as contradictory, until the rules change
+}
+
+type Event struct {
+}
+
+func NewBaseNode(nodeType string, styles style.Styles) BaseNode {
+	return BaseNode{
+		nodeType:       nodeType,
+		styles:         styles,
+		eventCallbacks: make(map[UIEventType]func(UIEvent)),
+	}
+}
+
+// NewBaseNodeWithStyles creates a new base node with the given styles
+func NewBaseNodeWithStyles(nodeType string, styles style.Styles) Node {
+	return &BaseNode{
+		nodeType:       nodeType,
+		styles:         styles,
+		eventCallbacks: make(map[UIEventType]func(UIEvent)),
+	}
+}
+
+// NewBaseNodeWithProps creates a new base node with the given properties
+func NewBaseNodeWithProps(nodeType string, props map[string]interface{}) Node {
+	styles := style.NewStyles(props)
+
+	// If there were any style errors, create an error node to display them
+	if errorVal, ok := styles.Get("error"); ok {
+		if errorMsg, ok := errorVal.(string); ok {
+			errorStyles := style.NewStyles(map[string]interface{}{
+				"background": style.Red,
+				"color":      style.White,
+				"padding":    style.EdgeInsets{10, 10, 10, 10},
+				"width":      400,
+				"height":     100,
+			})
+			errorBase := BaseNode{
+				nodeType:       "error",
+				styles:         errorStyles,
+				eventCallbacks: make(map[UIEventType]func(UIEvent)),
+			}
+			errorText := TextNode{
+				BaseNode: errorBase,
+				text:     fmt.Sprintf("Style Error: %s", errorMsg),
+			}
+			return &errorText
+		}
+	}
+
+	return &BaseNode{
+		nodeType:       nodeType,
+		styles:         styles,
+		eventCallbacks: make(map[UIEventType]func(UIEvent)),
+	}
+}
+
+// Constraints represents size constraints
+type Constraints struct {
+	MinWidth, MaxWidth, MinHeight, MaxHeight float64
+}
+
+// RenderContext provides context for rendering
+type RenderContext interface {
+	LoadTexture(sourceURL string) rl.Texture2D
+	Present()
+	Save()
+	Restore()
+	SetOpacity(opacity float64)
+	SetFillColor(color style.Color)
+	SetStrokeColor(color style.Color)
+	SetLineWidth(width float64)
+	StrokeLine(start style.Point, end style.Point)
+	SetFontSize(size float64)
+	DrawText(text string, bounds style.Rect, styles style.Styles, opacity float64)
+	DrawBackground(bounds style.Rect, styles style.Styles, opacity float64)
+	DrawBorders(bounds style.Rect, styles style.Styles, opacity float64)
+	DrawTexture(sourceURL string, bounds style.Rect, styles style.Styles, opacity float64)
+	FillRect(rect style.Rect)
+	Scale(x, y float64)
+	Clear()
+	ClipRect() style.Rect
+	SetClipRect(rect style.Rect)
+}
+
+// LayerRenderContext is implemented by render contexts that can render into
+// offscreen layers and composite them later. Layers are addressed by key and
+// keep their contents between frames until they are drawn into again.
+type LayerRenderContext interface {
+	RenderContext
+
+	// BeginLayer redirects drawing into the layer covering bounds, creating or
+	// resizing it as needed. Drawing keeps using window coordinates.
+	BeginLayer(key string, bounds style.Rect, clear bool)
+	// EndLayer returns drawing to the previous target
+	EndLayer()
+	// DrawLayer composites a layer at bounds and reports whether the layer existed
+	DrawLayer(key string, bounds style.Rect) bool
+	// ReleaseLayer frees the resources held by a layer
+	ReleaseLayer(key string)
+}
+
+// EffectRenderContext is implemented by layer render contexts that can apply
+// filters and blend modes while compositing a layer
+type EffectRenderContext interface {
+	LayerRenderContext
+
+	// DrawLayerEffects composites a layer at bounds through the filters, in order,
+	// and blends the result with the target using the blend mode. It reports whether the layer existed.
+	DrawLayerEffects(key string, bounds style.Rect, filters []style.Filter, blendMode string) bool
+}
+
+func (n *BaseNode) GetStyle(key string) (interface{}, bool) {
+	return n.styles.Get(key)
+}
+
+func (n *BaseNode) GetStyleFloat(key string) (float64, bool) {
+	return n.styles.GetFloat(key)
+}
+
+func (n *BaseNode) GetStyleString(key string) (string, bool) {
+	return n.styles.GetString(key)
+}
+
+func (n *BaseNode) GetStyleColor(key string) (style.Color, bool) {
+	return n.styles.GetColor(key)
+}
+
+func (n *BaseNode) GetStyleEdgeInsets(key string) (style.EdgeInsets, bool) {
+	return n.styles.GetEdgeInsets(key)
+}
+
+func (n *BaseNode) Parent() Node {
+	return n.parent
+}
+
+func (n *BaseNode) SetParent(parent Node) {
+	n.parent = parent
+}
+
+// Structure methods
+func (n *BaseNode) AddChildren(children ...Node) {
+	// We catch the event nodes and style event nodes being added and rather than add them as children to this node,
+	// we filter them out and incorporate their callbacks/styles into this node.
+	// This allows for a more declarative UI construction where events and styles are treated as first-class nodes.
+	var finalChildren []Node
+	for _, child := range children {
+		if eventNode, ok := child.(*EventNode); ok {
+			// Register the event callback with this node
+			n.eventCallbacks[eventNode.eventType] = eventNode.callback
+		} else if styleNode, ok := child.(*BaseNode); ok && styleNode.nodeType == "style_handler" {
+			// Incorporate the style changes into this node's styles
+			if stateStyles := styleNode.styles.GetStateStyle("all"); stateStyles != nil {
+				n.styles.AddStateStyle("all", stateStyles)
+			}
+		} else {
+			child.SetParent(n)
+			child.MarkStyleDirty()
+			finalChildren = append(finalChildren, child)
+		}
+	}
+	n.children = append(n.children, finalChildren...)
+	n.MarkStyleDirty()
+	n.MarkLayoutDirty()
+}
+
+func (n *BaseNode) Children() []Node {
+	return n.children
+}
+
+func (n *BaseNode) GetType() string {
+	return n.nodeType
+}
+
+// GetStyles returns a pointer to the node's styles
+func (n *BaseNode) GetStyles() *style.Styles {
+	return &n.styles
+}
+
+// ResolveStyles resolves the styles of the nodes marked for restyling. Restyling a node
+// restyles its children too, as they inherit and compute lengths from it.
+func (n *BaseNode) ResolveStyles(parentStyles style.Styles) style.Styles {
+	if n.stylesValid && !n.descendantStyleDirty {
+		return n.styles
+	}
+	if n.stylesValid {
+		for _, child := range n.children {
+			child.ResolveStyles(n.styles)
+		}
+		n.descendantStyleDirty = false
+		return n.styles
+	}
+
+	// Start with this node's styles
+	resolvedStyles := *n.GetStyles()
+	inherited := inheritedValues(&resolvedStyles)
+
+	// A node that is laid out anyway does not need to know whether its layout changed
+	var layout map[string]interface{}
+	if !n.needsLayout() {
+		layout = resolvedStyles.LayoutValues()
+	}
+
+	// Styles for the window size apply over the node's own
+	n.applyBreakpoints(&resolvedStyles, parentStyles.Viewport())
+
+	// For inheritable properties, check if they're set in this node
+	// If not, inherit from parent
+	for _, prop := range inheritableProps {
+		// Only inherit if:
+		// 1. Parent has the property set (explicitly or inherited)
+		// 2. This node doesn't have it explicitly set
+		_, parentHasIt := parentStyles.Get(prop)
+		selfHasIt := resolvedStyles.IsExplicit(prop)
+
+		if parentHasIt && !selfHasIt {
+			if value, ok := parentStyles.Get(prop); ok {
+				resolvedStyles.Set(prop, value)
+			}
+		}
+	}
+
+	// Direction is inherited like text styles, but has a default that does not count as set
+	if value, ok := resolvedStyles.GetValue("direction"); !ok || value.Source != style.Explicit {
+		if direction, ok := parentStyles.GetString("direction"); ok {
+			resolvedStyles.Set("direction", style.StyleValue{Type: style.PIXEL, Value: direction, Source: style.Inherited})
+		}
+	}
+
+	// Restore original styles if no states are active
+	if !n.state.IsHovered && !n.state.IsActive && !n.state.IsFocused && !n.state.IsDisabled {
+		resolvedStyles.RestoreOriginalStyles()
+	} else {
+		// Apply state-based styles
+		if n.state.IsHovered {
+			if hoverStyle := resolvedStyles.GetStateStyle("hover"); hoverStyle != nil {
+				applyStateStyle(&resolvedStyles, hoverStyle)
+			}
+		}
+		if n.state.IsActive {
+			if activeStyle := resolvedStyles.GetStateStyle("active"); activeStyle != nil {
+				applyStateStyle(&resolvedStyles, activeStyle)
+			}
+		}
+		if n.state.IsFocused {
+			if focusStyle := resolvedStyles.GetStateStyle("focus"); focusStyle != nil {
+				applyStateStyle(&resolvedStyles, focusStyle)
+			}
+		}
+		if n.state.IsDisabled {
+			if disabledStyle := resolvedStyles.GetStateStyle("disabled"); disabledStyle != nil {
+				applyStateStyle(&resolvedStyles, disabledStyle)
+			}
+		}
+	}
+
+	// Relative lengths get their computed values before layout
+	resolvedStyles.ComputeLengths(&parentStyles)
+
+	// Calculate final opacity
+	if opacity, ok := resolvedStyles.GetFloat("opacity"); ok {
+		if parentOpacity, ok := parentStyles.GetFloat("opacity"); ok {
+			resolvedStyles.SetFinalOpacity(opacity * parentOpacity)
+		} else {
+			resolvedStyles.SetFinalOpacity(opacity)
+		}
+	} else {
+		resolvedStyles.SetFinalOpacity(parentStyles.GetFinalOpacity())
+	}
+	n.finalOpacity = resolvedStyles.GetFinalOpacity()
+
+	// Paint-only changes such as a hover background are repainted without a layout
+	*n.GetStyles() = resolvedStyles
+	n.stylesValid = true
+	if layout != nil && !reflect.DeepEqual(layout, resolvedStyles.LayoutValues()) {
+		n.MarkLayoutDirty()
+	}
+	n.MarkPaintDirty()
+
+	// Children only need restyling if what they inherit or compute from this node changed
+	restyleChildren := !reflect.DeepEqual(inherited, inheritedValues(&resolvedStyles))
+	for _, child := range n.children {
+		if restyleChildren {
+			child.MarkStyleDirty()
+		}
+		child.ResolveStyles(resolvedStyles)
+	}
+	n.descendantStyleDirty = false
+	return resolvedStyles
+}
+
+// inheritableProps are the properties a node takes from its parent when it does not set them
+var inheritableProps = []string{
+	"fontFamily", "fontSize", "color", "lineHeight", "background", "opacity",
+}
+
+// inheritedValues returns the values children inherit or compute their lengths from
+func inheritedValues(styles *style.Styles) []interface{} {
+	values := make([]interface{}, 0, len(inheritableProps)+3)
+	for _, prop := range inheritableProps {
+		value, _ := styles.Get(prop)
+		values = append(values, value)
+	}
+	direction, _ := styles.GetString("direction")
+	return append(values, styles.GetFinalOpacity(), styles.RemSize(), direction)
+}
+
+// applyStateStyle applies a state style variation to the base styles
+func applyStateStyle(base *style.Styles, state *style.Styles) {
+	// Apply all explicitly set properties from the state style
+	inheritableProps := []string{
+		"fontFamily", "fontSize", "color", "lineHeight", "background", "opacity",
+	}
+	for _, prop := range inheritableProps {
+		if state.IsExplicit(prop) {
+			// Store original value if not already stored
+			if value, ok := base.Get(prop); ok {
+				base.StoreOriginalValue(prop, value)
+			}
+			// Apply new value
+			if value, ok := state.Get(prop); ok {
+				base.Set(prop, value)
+			}
+		}
+	}
+}
+
+func (n *BaseNode) Layout(ctx RenderContext, constraints Constraints) style.Size {
+	// Start with preferred size
+	preferredSize := n.preferredSize
+
+	// The root is laid out in the window, which fixed descendants are placed against
+	if n.parent == nil {
+		n.viewport = style.Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+		n.styles.ResolvePercentages(constraints.MaxWidth)
+	}
+
+	// Auto sizes fit the content, shrinking to the constraints but not below the min-content size
+	finalSize := style.Size{
+		Width:  math.Max(fitContent(n.minContent.Width, preferredSize.Width, constraints.MaxWidth), constraints.MinWidth),
+		Height: math.Max(fitContent(n.minContent.Height, preferredSize.Height, constraints.MaxHeight), constraints.MinHeight),
+	}
+
+	// Explicit pixel and percentage sizes take precedence over the preferred size
+	width, definiteWidth := resolveLength(&n.styles, "width", constraints.MaxWidth)
+	if definiteWidth {
+		finalSize.Width = width
+	}
+
+	height, definiteHeight := resolveLength(&n.styles, "height", constraints.MaxHeight)
+	if definiteHeight {
+		finalSize.Height = height
+	}
+
+	// An aspect ratio sizes an auto axis from the other one, including a size the parent fixed to stretch the node
+	fixedWidth := definiteWidth || constraints.MinWidth == constraints.MaxWidth
+	fixedHeight := definiteHeight || constraints.MinHeight == constraints.MaxHeight
+	if _, ok := aspectRatio(&n.styles); ok {
+		finalSize, definiteWidth, definiteHeight = transferAspectRatio(&n.styles, finalSize, fixedWidth, fixedHeight)
+	}
+
+	// The node's own min and max sizes win over its content, its explicit size and the constraints
+	minWidth, maxWidth := minMaxLengths(&n.styles, true, constraints.MaxWidth)
+	finalSize.Width = clamp(finalSize.Width, minWidth, maxWidth)
+	minHeight, maxHeight := minMaxLengths(&n.styles, false, constraints.MaxHeight)
+	finalSize.Height = clamp(finalSize.Height, minHeight, maxHeight)
+
+	n.layoutAtSize(ctx, finalSize, definiteWidth, definiteHeight)
+	return n.finalSize
+}
+
+// layoutAtSize gives the node its final size and lays out its children inside it.
+// A definite axis has a size that does not depend on the children, so they can be
+// flexed to fill it. Parents call this directly when they decide a child's size.
+func (n *BaseNode) layoutAtSize(ctx RenderContext, size style.Size, definiteWidth, definiteHeight bool) {
+	// A clean subtree laid out at the same size comes out the same
+	key := n.layoutKey(size, definiteWidth, definiteHeight)
+	if result, ok := n.cachedLayout(key); ok {
+		n.finalSize = result
+		return
+	}
+	defer n.storeLayout(key)
+
+	// A size of the node's own is definite whichever way the parent sized it
+	definiteWidth = definiteWidth || !isAutoLength(&n.styles, "width")
+	definiteHeight = definiteHeight || !isAutoLength(&n.styles, "height")
+	size, definiteWidth, definiteHeight = transferAspectRatio(&n.styles, size, definiteWidth, definiteHeight)
+	n.finalSize = size
+
+	// If we have children, layout them now
+	if len(n.children) > 0 {
+		// Calculate available space for children (minus padding)
+		available := size
+		if padding, ok := n.styles.GetEdgeInsets("padding"); ok {
+			available.Width -= padding.Left + padding.Right
+			available.Height -= padding.Top + padding.Bottom
+		}
+
+		// Percentages in the children's padding and margins are of this node's content width
+		for _, child := range n.children {
+			child.GetStyles().ResolvePercentages(available.Width)
+		}
+		n.layoutIndependent(ctx, available)
+
+		if n.isGrid() {
+			n.layoutGrid(ctx, available, definiteWidth, definiteHeight)
+		} else if n.isTable() {
+			n.layoutTable(ctx, available, definiteWidth, definiteHeight)
+		} else if n.isStack() {
+			n.layoutStack(ctx, available)
+		} else if n.isConstraintLayout() {
+			n.layoutConstraints(ctx, available)
+		} else {
+			n.layoutFlex(ctx, available, definiteWidth, definiteHeight)
+		}
+
+		// An auto height fits the children at the final width, where they may have wrapped onto more lines.
+		// Clipped content scrolls instead of growing the node.
+		if !definiteHeight && !clipsContent(n) {
+			height := n.contentExtent(available).Height + size.Height - available.Height
+			if maxHeight, ok := intrinsicLength(&n.styles, "maxHeight"); ok {
+				height = math.Min(height, maxHeight)
+			}
+			n.finalSize.Height = math.Max(size.Height, height)
+		}
+
+		// Absolutely positioned and fixed children are sized against their containing block
+		for _, child := range n.children {
+			if isOutOfFlow(child) {
+				layoutOutOfFlow(ctx, child)
+			}
+		}
+	}
+}
+
+func (n *BaseNode) ArrangeChildren(ctx RenderContext, bounds style.Rect) {
+	bounds.Position = n.relativeOffset(bounds.Position)
+	n.stuck = n.stickyOffset(bounds)
+	bounds.Position.Y += n.stuck
+
+	// Children of a node that kept its layout and bounds are already in place
+	n.restoreLayout(ctx)
+	if n.arrangeValid && bounds == n.finalBounds {
+		return
+	}
+	defer func() { n.arrangeValid = true }()
+
+	// Moving or resizing a node damages both its old and new area
+	if !n.painted || bounds != n.finalBounds {
+		n.MarkPaintDirty()
+	}
+
+	// Set this node's bounds
+	n.finalBounds = bounds
+	n.styles.ResolveRadius(bounds.Size)
+	n.arrangeContent(ctx)
+}
+
+// arrangeContent positions the children within the node's final bounds
+func (n *BaseNode) arrangeContent(ctx RenderContext) {
+	// For leaf nodes, we're done
+	if len(n.children) == 0 {
+		return
+	}
+
+	// Calculate content area (bounds minus padding)
+	contentArea := n.finalBounds
+
+	// Apply padding if present
+	if padding, ok := n.styles.GetEdgeInsets("padding"); ok {
+		contentArea.Position.X += padding.Left
+		contentArea.Position.Y += padding.Top
+		contentArea.Size.Width -= padding.Left + padding.Right
+		contentArea.Size.Height -= padding.Top + padding.Bottom
+	}
+
+	// Scrolled content is arranged at its offset from the start, which is mirrored with the
+	// content when it runs from right to left
+	if clipsContent(n) {
+		n.updateScrollExtent(contentArea.Size)
+		if n.mirrorsFlow() {
+			contentArea.Position.X += n.scrollOffset.X
+		} else {
+			contentArea.Position.X -= n.scrollOffset.X
+		}
+		contentArea.Position.Y -= n.scrollOffset.Y
+	}
+
+	// Position children in their grid areas, on top of each other or along the flex direction.
+	// Table rows place their cells in the areas the table gave them.
+	if n.isGrid() || n.isTableRow() {
+		n.arrangeGrid(ctx, contentArea)
+	} else if n.isTable() {
+		n.arrangeTable(ctx, contentArea)
+	} else if n.isStack() {
+		n.arrangeStack(ctx, contentArea)
+	} else if n.isConstraintLayout() {
+		n.arrangeConstraints(ctx, contentArea)
+	} else {
+		n.arrangeFlex(ctx, contentArea)
+	}
+
+	// Out of flow children are placed by their insets once the flow is in place
+	for _, child := range n.children {
+		if isOutOfFlow(child) {
+			arrangeOutOfFlow(ctx, child, contentArea)
+		}
+	}
+}
+
+// mirrorsFlow returns true if the node's flex or grid content runs from right to left.
+// Stacks and constraint layouts place their children by their physical sides.
+func (n *BaseNode) mirrorsFlow() bool {
+	return n.styles.IsRTL() && !n.isStack() && !n.isConstraintLayout()
+}
+
+// mirrorPlacements mirrors the bounds of children arranged from left to right within the
+// content box, when the node's flow runs from right to left
+func (n *BaseNode) mirrorPlacements(content style.Rect, placements []placement) {
+	if !n.mirrorsFlow() {
+		return
+	}
+	for i := range placements {
+		bounds := &placements[i].bounds
+		bounds.Position.X = 2*content.Position.X + content.Size.Width - bounds.Position.X - bounds.Size.Width
+	}
+}
+
+// flowMargin returns a child's margin as the node arranges it. Right-to-left flow is
+// arranged from left to right and then mirrored, so the left and right sides are swapped
+// for the mirroring to put them back.
+func (n *BaseNode) flowMargin(margin style.EdgeInsets) style.EdgeInsets {
+	if n.mirrorsFlow() {
+		margin.Left, margin.Right = margin.Right, margin.Left
+	}
+	return margin
+}
+
+func (n *BaseNode) GetFinalSize() style.Size {
+	return n.finalSize
+}
+
+func (n *BaseNode) GetFinalBounds() style.Rect {
+	return n.finalBounds
+}
+
+func (n *BaseNode) Paint(ctx RenderContext) {
+	n.paintWithEffects(ctx, n.paintContents)
+}
+
+// paintWithEffects paints the node with contents, going through an offscreen layer
+// when the node is a retained layer or has filters or a blend mode to apply
+func (n *BaseNode) paintWithEffects(ctx RenderContext, contents func(RenderContext)) {
+	filters := n.filters()
+	blendMode, _ := n.styles.GetString("mixBlendMode")
+	hasEffects := len(filters) > 0 || (blendMode != "" && blendMode != "normal")
+	layer, _ := n.styles.Get("layer")
+
+	layerCtx, ok := ctx.(LayerRenderContext)
+	if !ok || (layer != true && !hasEffects) {
+		// Contexts without layers draw the node without its effects
+		contents(ctx)
+		return
+	}
+
+	if n.layerKey == "" {
+		n.layerKey = newLayerKey()
+	}
+
+	// Filters can draw outside the node, so the layer is grown to fit them
+	bounds := n.visualBounds()
+	draw := func() bool {
+		if effectCtx, ok := layerCtx.(EffectRenderContext); ok && hasEffects {
+			return effectCtx.DrawLayerEffects(n.layerKey, bounds, filters, blendMode)
+		}
+		return layerCtx.DrawLayer(n.layerKey, bounds)
+	}
+
+	// Composite the cached subtree when nothing in it changed
+	if !n.NeedsPaint() && draw() {
+		return
+	}
+
+	layerCtx.BeginLayer(n.layerKey, bounds, true)
+	contents(layerCtx)
+	layerCtx.EndLayer()
+	draw()
+}
+
+// filters returns the node's filter list
+func (n *BaseNode) filters() []style.Filter {
+	return parseFilters(&n.styles)
+}
+
+// parseFilters returns the filter list in styles, parsing it if it was set as a string
+func parseFilters(styles *style.Styles) []style.Filter {
+	value, ok := styles.Get("filter")
+	if !ok {
+		return nil
+	}
+	switch v := value.(type) {
+	case []style.Filter:
+		return v
+	case style.Filter:
+		return []style.Filter{v}
+	case string:
+		filters, _ := parseFilterString(v)
+		return filters
+	}
+	return nil
+}
+
+// paintContents draws the node itself followed by its children
+func (n *BaseNode) paintContents(ctx RenderContext) {
+	// Children can overflow their parent, so only this node's own drawing is culled
+	clip := ctx.ClipRect()
+	clipped := clipsContent(n)
+	if !n.finalBounds.Intersects(clip) {
+		if !clipped {
+			n.paintChildren(ctx)
+		}
+		return
+	}
+
+	// Apply opacity if set
+	opacity, _ := n.styles.GetFloat("opacity")
+	if opacity < 1.0 {
+		ctx.Save()
+		ctx.SetOpacity(opacity)
+		defer ctx.Restore()
+	}
+
+	// Apply scale if set
+	scale, _ := n.styles.GetFloat("scale")
+	if scale != 1.0 {
+		ctx.Save()
+		ctx.Scale(scale, scale)
+		defer ctx.Restore()
+	}
+
+	// Draw background if set
+	if bgColor, ok := n.styles.GetColor("background"); ok {
+		ctx.SetFillColor(bgColor)
+		ctx.DrawBackground(n.finalBounds, n.styles, opacity)
+	}
+
+	// Draw border if set
+	if border, ok := n.styles.Get("border"); ok {
+		if borderStyle, ok := border.(style.BorderStyle); ok && borderStyle.CanDisplay() {
+			ctx.DrawBorders(n.finalBounds, n.styles, opacity)
+		}
+	}
+
+	// Draw text if set
+	if text, ok := n.styles.GetString("text"); ok {
+		if fontSize, ok := n.styles.GetFloat("fontSize"); ok {
+			if textColor, ok := n.styles.GetColor("color"); ok {
+				ctx.SetFontSize(fontSize)
+				ctx.SetFillColor(textColor)
+				ctx.DrawText(text, n.finalBounds, n.styles, opacity)
+			}
+		}
+	}
+
+	// Paint children, with stacked ones in zIndex order
+	if !clipped {
+		n.paintChildren(ctx)
+		return
+	}
+
+	// Clipped content is only drawn within the node, with the scrollbars over it
+	ctx.SetClipRect(clip.Intersection(n.finalBounds))
+	n.paintChildren(ctx)
+	ctx.SetClipRect(clip)
+	n.paintScrollbars(ctx, opacity)
+}
+
+// TextNode is a specialized node for text content
+type TextNode struct {
+	BaseNode
+	text string
+}
+
+func NewTextNode(baseNode BaseNode, text string) Node {
+	return &TextNode{
+		BaseNode: baseNode,
+		text:     text,
+	}
+}
+
+// Specialized implementation for TextNode
+func (n *TextNode) MeasurePreferred(ctx RenderContext) style.Size {
+	fontSize, _ := n.styles.GetFloat("fontSize")
+	textWidth := float64(rl.MeasureText(n.text, int32(fontSize)))
+	textHeight := fontSize * 1.2 // Add some line height
+
+	// Text is drawn on a single line, so it cannot get any narrower than its max-content width
+	content := style.Size{Width: textWidth, Height: textHeight}
+	n.preferredSize = n.intrinsicSize(content)
+	n.minContent = n.withPadding(content)
+	return n.preferredSize
+}
+
+func (n *TextNode) Paint(ctx RenderContext) {
+	n.paintWithEffects(ctx, n.paintText)
+}
+
+// paintText draws the text node's background, border and text
+func (n *TextNode) paintText(ctx RenderContext) {
+	// Skip text outside the area being repainted
+	clip := ctx.ClipRect()
+	if !n.finalBounds.Intersects(clip) {
+		return
+	}
+
+	// Draw background and border first
+	ctx.DrawBackground(n.finalBounds, n.styles, n.finalOpacity)
+
+	// Draw borders if needed
+	if border, ok := n.styles.Get("border"); ok {
+		if borderStyle, ok := border.(style.BorderStyle); ok && borderStyle.CanDisplay() {
+			ctx.DrawBorders(n.finalBounds, n.styles, n.finalOpacity)
+		}
+	}
+
+	// Then draw the text
+	ctx.DrawText(n.text, n.finalBounds, n.styles, n.finalOpacity)
+}
+
+type ImageNode struct {
+	BaseNode
+	sourceURL string
+}
+
+func NewImageNode(baseNode Node, sourceURL string) Node {
+	if baseNode == nil {
+		return nil
+	}
+	// Convert the Node to BaseNode
+	base, ok := baseNode.(*BaseNode)
+	if !ok {
+		// If we can't convert, create an error node
+		errorNode := NewBaseNodeWithProps("error", map[string]interface{}{
+			"background": style.Red,
+			"color":      style.White,
+			"padding":    style.EdgeInsets{10, 10, 10, 10},
+			"width":      400,
+			"height":     100,
+		})
+		if errorText, ok := errorNode.(*TextNode); ok {
+			errorText.text = "Invalid node type for ImageNode"
+		}
+		return errorNode
+	}
+	imageNode := ImageNode{
+		BaseNode:  *base,
+		sourceURL: sourceURL,
+	}
+	return &imageNode
+}
+
+type EventNode struct {
+	BaseNode
+	callback  func(UIEvent)
+	eventType UIEventType
+}
+
+func NewEventNode(baseNode Node, eventType UIEventType, callback func(UIEvent)) Node {
+	if baseNode == nil {
+		return nil
+	}
+	// Convert the Node to BaseNode
+	base, ok := baseNode.(*BaseNode)
+	if !ok {
+		// If we can't convert, create an error node
+		errorNode := NewBaseNodeWithProps("error", map[string]interface{}{
+			"background": style.Red,
+			"color":      style.White,
+			"padding":    style.EdgeInsets{10, 10, 10, 10},
+			"width":      400,
+			"height":     100,
+		})
+		if errorText, ok := errorNode.(*TextNode); ok {
+			errorText.text = "Invalid node type for EventNode"
+		}
+		return errorNode
+	}
+	eventNode := EventNode{
+		BaseNode:  *base,
+		callback:  callback,
+		eventType: eventType,
+	}
+	return &eventNode
+}
+
+func (n *ImageNode) Paint(ctx RenderContext) {
+	n.paintWithEffects(ctx, n.paintImage)
+}
+
+// paintImage draws the image node's background, border and image
+func (n *ImageNode) paintImage(ctx RenderContext) {
+	// Skip images outside the area being repainted
+	clip := ctx.ClipRect()
+	if !n.finalBounds.Intersects(clip) {
+		return
+	}
+
+	// Draw background and border first
+	ctx.DrawBackground(n.finalBounds, n.styles, n.finalOpacity)
+
+	// Draw borders if needed
+	if border, ok := n.styles.Get("border"); ok {
+		if borderStyle, ok := border.(style.BorderStyle); ok && borderStyle.CanDisplay() {
+			ctx.DrawBorders(n.finalBounds, n.styles, n.finalOpacity)
+		}
+	}
+
+	// Then draw the image
+	ctx.DrawTexture(n.sourceURL, n.finalBounds, n.styles, n.finalOpacity)
+}
+
+func (n *ImageNode) MeasurePreferred(ctx RenderContext) style.Size {
+	texture := ctx.LoadTexture(n.sourceURL)
+	n.preferredSize = style.Size{
+		Width:  float64(texture.Width),
+		Height: float64(texture.Height),
+	}
+	n.minContent = n.preferredSize
+	return n.preferredSize
+}
+
+// Helper function to clamp a value between min and max
+func clamp(value, min, max float64) float64 {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+func (b *BaseNode) Intersects(node Node) bool {
+	return b.finalBounds.Intersects(node.GetFinalBounds())
+}
+
+// RemoveEventHandler removes an event handler for the given event type
+func (n *BaseNode) RemoveEventHandler(eventType UIEventType) {
+	delete(n.eventCallbacks, eventType)
+}
+
+// DispatchEvent dispatches an event to this node and its parent chain
+func (n *BaseNode) DispatchEvent(event UIEvent) {
+	fmt.Printf("[DEBUG] Dispatching event %s to node %s\n", event.Type, n.nodeType)
+
+	// Set the target if not already set
+	if event.Target == nil {
+		event.Target = n
+	}
+
+	// Handle built-in events
+	switch event.Type {
+	case UIEnter:
+		fmt.Printf("[DEBUG] Setting hover state to true for node %s\n", n.nodeType)
+		n.SetState("hover", true)
+	case UILeave:
+		fmt.Printf("[DEBUG] Setting hover state to false for node %s\n", n.nodeType)
+		n.SetState("hover", false)
+	case UIPress:
+		fmt.Printf("[DEBUG] Setting active state to true for node %s\n", n.nodeType)
+		n.SetState("active", true)
+	case UIRelease:
+		fmt.Printf("[DEBUG] Setting active state to false for node %s\n", n.nodeType)
+		n.SetState("active", false)
+	case UIFocus:
+		n.SetState("focus", true)
+	case UIBlur:
+		n.SetState("focus", false)
+	}
+
+	// Call the handler if one exists
+	if handler, has := n.eventCallbacks[event.Type]; has {
+		fmt.Printf("[DEBUG] Calling event handler for %s on node %s\n", event.Type, n.nodeType)
+		handler(event)
+		if event.StopPropagation {
+			return
+		}
+	}
+
+	// Scrolling is the default action of wheel, scrollbar and paging key events
+	if n.handleScrollEvent(event) {
+		return
+	}
+
+	// Propagate to parent
+	if parent := n.parent; parent != nil {
+		parent.DispatchEvent(event)
+	}
+}
+
+// State management implementations for BaseNode
+func (n *BaseNode) ID() string {
+	return n.id
+}
+
+func (n *BaseNode) SetID(id string) Node {
+	n.id = id
+	return n
+}
+
+func (n *BaseNode) GetState() NodeState {
+	return n.state
+}
+
+func (n *BaseNode) SetState(state string, value bool) Node {
+	fmt.Printf("[DEBUG] Setting state %s=%v for node %s\n", state, value, n.nodeType)
+
+	// Handle built-in states
+	switch state {
+	case "hover":
+		n.state.IsHovered = value
+		fmt.Printf("[DEBUG] Hover state is now %v for node %s\n", n.state.IsHovered, n.nodeType)
+	case "active":
+		n.state.IsActive = value
+		fmt.Printf("[DEBUG] Active state is now %v for node %s\n", n.state.IsActive, n.nodeType)
+	case "focus":
+		n.state.IsFocused = value
+	case "disabled":
+		n.state.IsDisabled = value
+	default:
+		// Handle custom states
+		if n.state.CustomStates == nil {
+			n.state.CustomStates = make(map[string]bool)
+		}
+		n.state.CustomStates[state] = value
+	}
+
+	// Notify listeners
+	n.NotifyStateChange(state, value)
+
+	// State styles are applied when the node's styles are resolved again
+	n.MarkStyleDirty()
+	n.MarkPaintDirty()
+
+	return n
+}
+
+func (n *BaseNode) OnStateChange(state string, callback func(StateChange)) Node {
+	if n.stateListeners == nil {
+		n.stateListeners = make(map[string][]func(StateChange))
+	}
+	n.stateListeners[state] = append(n.stateListeners[state], callback)
+	return n
+}
+
+func (n *BaseNode) NotifyStateChange(state string, value bool) {
+	if n.stateListeners == nil {
+		return
+	}
+
+	change := StateChange{
+		NodeID: n.id,
+		State:  state,
+		Value:  value,
+	}
+
+	// Notify all listeners for this state
+	if listeners, ok := n.stateListeners[state]; ok {
+		for _, listener := range listeners {
+			listener(change)
+		}
+	}
+
+	// Also notify listeners for "all" state changes
+	if listeners, ok := n.stateListeners["all"]; ok {
+		for _, listener := range listeners {
+			listener(change)
+		}
+	}
+}
+
+// Debug logging function
+func debugLog(format string, args ...interface{}) {
+	fmt.Printf("[DEBUG] "+format+"\n", args...)
+}
+
+// MeasurePreferred measures the children and fits the node around them and its padding.
+// The preferred size is the max-content size, and the min-content size is recorded alongside it.
+func (n *BaseNode) MeasurePreferred(ctx RenderContext) style.Size {
+	// The content of a clean subtree measures the same
+	if !n.needsLayout() && n.layoutCache.valid {
+		return n.preferredSize
+	}
+
+	minContent, maxContent := n.measureContent(ctx)
+	n.preferredSize = n.intrinsicSize(maxContent)
+
+	// Clipped content can scroll, so it does not keep the node from shrinking
+	if clipsContent(n) {
+		minContent = style.Size{}
+	}
+	n.minContent = n.withPadding(minContent)
+	return n.preferredSize
+}
+
+// withPadding grows a content size by the node's padding
+func (n *BaseNode) withPadding(content style.Size) style.Size {
+	padding, _ := n.styles.GetEdgeInsets("padding")
+	return style.Size{
+		Width:  content.Width + padding.Left + padding.Right,
+		Height: content.Height + padding.Top + padding.Bottom,
+	}
+}
+
+// intrinsicSize adds the padding to a content size. Explicit pixel and em sizes replace it,
+// and an aspect ratio sizes the axis without one from the other.
+func (n *BaseNode) intrinsicSize(content style.Size) style.Size {
+	size := n.withPadding(content)
+	width, hasWidth := intrinsicLength(&n.styles, "width")
+	if hasWidth {
+		size.Width = width
+	}
+	height, hasHeight := intrinsicLength(&n.styles, "height")
+	if hasHeight {
+		size.Height = height
+	}
+	size, _, _ = transferAspectRatio(&n.styles, size, hasWidth, hasHeight)
+	return clampIntrinsic(&n.styles, size)
+}
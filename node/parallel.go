@@ -0,0 +1,129 @@
+package node
+
+import (
+	"math"
+	"sync"
+
+	"github.com/noahdw/goui/node/style"
+)
+
+// ParallelLayout is a render context that lays out and arranges independent subtrees on up
+// to Workers goroutines. A subtree is independent when its root is in the flow with a fixed
+// width and height in pixels, so nothing inside it depends on its siblings. Styles are
+// resolved and sizes measured before layout, so the subtrees only touch their own nodes
+// and come out the same as when laid out one after another.
+type ParallelLayout struct {
+	RenderContext
+	Workers int
+}
+
+// placement is a child and the bounds its parent arranges it at
+type placement struct {
+	node   Node
+	bounds style.Rect
+}
+
+// parallelLayout returns the context to lay out independent subtrees with, if ctx asks for it
+func parallelLayout(ctx RenderContext) (ParallelLayout, bool) {
+	parallel, ok := ctx.(ParallelLayout)
+	return parallel, ok && parallel.Workers > 1
+}
+
+// isIndependent returns true if the node's layout does not depend on its siblings or on
+// how its parent sizes it. Virtual lists measure the rows they bring into view while they
+// are arranged, which has to happen on the render thread, so subtrees with one are not.
+func isIndependent(node Node) bool {
+	if isOutOfFlow(node) {
+		return false
+	}
+	styles := node.GetStyles()
+	for _, key := range []string{"width", "height"} {
+		value, ok := styles.GetValue(key)
+		if !ok || value.Type != style.PIXEL {
+			return false
+		}
+	}
+	return !containsVirtual(node)
+}
+
+// containsVirtual returns true if the subtree has a virtual list in it
+func containsVirtual(node Node) bool {
+	if _, ok := node.(*VirtualNode); ok {
+		return true
+	}
+	for _, child := range node.Children() {
+		if containsVirtual(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// layoutIndependent lays out the independent children that need it concurrently at their
+// fixed sizes. The layout that follows finds their results in the layout cache.
+func (n *BaseNode) layoutIndependent(ctx RenderContext, available style.Size) {
+	parallel, ok := parallelLayout(ctx)
+	if !ok || n.isTable() {
+		return
+	}
+
+	var independent []Node
+	for _, child := range n.children {
+		if child.NeedsLayout() && isIndependent(child) {
+			independent = append(independent, child)
+		}
+	}
+	if len(independent) < 2 {
+		return
+	}
+
+	constraints := Constraints{MaxWidth: math.Max(0, available.Width), MaxHeight: math.Max(0, available.Height)}
+	runParallel(parallel.Workers, len(independent), func(i int) {
+		independent[i].Layout(parallel.RenderContext, constraints)
+	})
+}
+
+// arrangePlacements arranges the children at their bounds, the independent ones concurrently
+// when ctx asks for it
+func arrangePlacements(ctx RenderContext, placements []placement) {
+	parallel, ok := parallelLayout(ctx)
+	var independent []placement
+	for _, p := range placements {
+		if ok && isIndependent(p.node) {
+			independent = append(independent, p)
+			continue
+		}
+		p.node.ArrangeChildren(ctx, p.bounds)
+	}
+
+	if len(independent) < 2 {
+		for _, p := range independent {
+			p.node.ArrangeChildren(ctx, p.bounds)
+		}
+		return
+	}
+	runParallel(parallel.Workers, len(independent), func(i int) {
+		independent[i].node.ArrangeChildren(parallel.RenderContext, independent[i].bounds)
+	})
+}
+
+// runParallel calls task for each index below count on up to workers goroutines and waits
+// for all of them to finish
+func runParallel(workers, count int, task func(i int)) {
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, count); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				task(i)
+			}
+		}()
+	}
+	for i := 0; i < count; i++ {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+}
@@ -0,0 +1,115 @@
+package node
+
+import (
+	"runtime"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/noahdw/goui/node/style"
+)
+
+// layoutOnlyContext is a render context for laying out without a window. Nothing is drawn.
+type layoutOnlyContext struct{}
+
+func (layoutOnlyContext) LoadTexture(string) rl.Texture2D                       { return rl.Texture2D{} }
+func (layoutOnlyContext) Present()                                              {}
+func (layoutOnlyContext) Save()                                                 {}
+func (layoutOnlyContext) Restore()                                              {}
+func (layoutOnlyContext) SetOpacity(float64)                                    {}
+func (layoutOnlyContext) SetFillColor(style.Color)                              {}
+func (layoutOnlyContext) SetStrokeColor(style.Color)                            {}
+func (layoutOnlyContext) SetLineWidth(float64)                                  {}
+func (layoutOnlyContext) StrokeLine(style.Point, style.Point)                   {}
+func (layoutOnlyContext) SetFontSize(float64)                                   {}
+func (layoutOnlyContext) DrawText(string, style.Rect, style.Styles, float64)    {}
+func (layoutOnlyContext) DrawBackground(style.Rect, style.Styles, float64)      {}
+func (layoutOnlyContext) DrawBorders(style.Rect, style.Styles, float64)         {}
+func (layoutOnlyContext) DrawTexture(string, style.Rect, style.Styles, float64) {}
+func (layoutOnlyContext) FillRect(style.Rect)                                   {}
+func (layoutOnlyContext) Scale(float64, float64)                                {}
+func (layoutOnlyContext) Clear()                                                {}
+func (layoutOnlyContext) ClipRect() style.Rect                                  { return style.Rect{} }
+func (layoutOnlyContext) SetClipRect(style.Rect)                                {}
+
+// dashboard builds a wrapping row of fixed-size panels, each a column of rows of cells
+func dashboard(panels int) Node {
+	root := NewBaseNodeWithProps("dashboard", map[string]interface{}{"flexWrap": "wrap", "width": 1920.0})
+	for p := 0; p < panels; p++ {
+		panel := NewBaseNodeWithProps("panel", map[string]interface{}{
+			"width": 300.0, "height": 200.0, "flexDirection": "column", "padding": 4.0,
+		})
+		for r := 0; r < 20; r++ {
+			row := NewBaseNodeWithProps("row", map[string]interface{}{"flexGrow": 1.0, "columnGap": 2.0})
+			for c := 0; c < 6; c++ {
+				row.AddChildren(NewBaseNodeWithProps("cell", map[string]interface{}{"flexGrow": 1.0, "padding": 2.0}))
+			}
+			panel.AddChildren(row)
+		}
+		root.AddChildren(panel)
+	}
+	return root
+}
+
+// markTreeLayoutDirty makes the next layout pass lay out every node again
+func markTreeLayoutDirty(n Node) {
+	n.MarkLayoutDirty()
+	for _, child := range n.Children() {
+		markTreeLayoutDirty(child)
+	}
+}
+
+// benchmarkDashboard runs full layout passes over a dashboard of 48 panels on workers goroutines
+func benchmarkDashboard(b *testing.B, workers int) {
+	root := dashboard(48)
+	var ctx RenderContext = layoutOnlyContext{}
+	if workers > 1 {
+		ctx = ParallelLayout{RenderContext: ctx, Workers: workers}
+	}
+	root.ResolveStyles(style.NewStyles(map[string]interface{}{}))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		markTreeLayoutDirty(root)
+		root.MeasurePreferred(ctx)
+		size := root.Layout(ctx, Constraints{MaxWidth: 1920, MaxHeight: 1080})
+		root.ArrangeChildren(ctx, style.Rect{Size: size})
+	}
+}
+
+// layoutDashboard lays out a dashboard and returns the bounds of every node in tree order
+func layoutDashboard(ctx RenderContext) []style.Rect {
+	root := dashboard(12)
+	root.ResolveStyles(style.NewStyles(map[string]interface{}{}))
+	root.MeasurePreferred(ctx)
+	size := root.Layout(ctx, Constraints{MaxWidth: 1920, MaxHeight: 1080})
+	root.ArrangeChildren(ctx, style.Rect{Size: size})
+
+	var bounds []style.Rect
+	var collect func(n Node)
+	collect = func(n Node) {
+		bounds = append(bounds, n.GetFinalBounds())
+		for _, child := range n.Children() {
+			collect(child)
+		}
+	}
+	collect(root)
+	return bounds
+}
+
+func TestParallelLayoutMatchesSerial(t *testing.T) {
+	serial := layoutDashboard(layoutOnlyContext{})
+	parallel := layoutDashboard(ParallelLayout{RenderContext: layoutOnlyContext{}, Workers: 4})
+	for i := range serial {
+		if serial[i] != parallel[i] {
+			t.Fatalf("node %d: serial bounds %v, parallel bounds %v", i, serial[i], parallel[i])
+		}
+	}
+}
+
+func BenchmarkLayoutSerial(b *testing.B) {
+	benchmarkDashboard(b, 1)
+}
+
+func BenchmarkLayoutParallel(b *testing.B) {
+	benchmarkDashboard(b, max(2, runtime.GOMAXPROCS(0)))
+}
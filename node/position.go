@@ -0,0 +1,225 @@
+package node
+
+import (
+	"math"
+	"sort"
+
+	"github.com/noahdw/goui/node/style"
+)
+
+// viewportNode is implemented by root nodes, which remember the window they were laid out in
+type viewportNode interface {
+	viewportSize() style.Size
+}
+
+func (n *BaseNode) viewportSize() style.Size {
+	return n.viewport
+}
+
+// isPositioned returns true if the node is a containing block for absolutely positioned descendants
+func isPositioned(n Node) bool {
+	position, _ := n.GetStyles().GetString("position")
+	return position != "" && position != "static"
+}
+
+// isOutOfFlow returns true if the node is placed by its insets instead of by flex or grid layout
+func isOutOfFlow(n Node) bool {
+	position, _ := n.GetStyles().GetString("position")
+	return position == "absolute" || position == "fixed"
+}
+
+// flowChildren returns the children that take part in flex or grid layout, in their order
+func (n *BaseNode) flowChildren() []Node {
+	flow := make([]Node, 0, len(n.children))
+	for _, child := range n.children {
+		if !isOutOfFlow(child) {
+			flow = append(flow, child)
+		}
+	}
+	return inOrder(flow)
+}
+
+// inOrder sorts children by their order style, keeping children with the same order
+// in the order they were added
+func inOrder(children []Node) []Node {
+	reordered := false
+	for _, child := range children {
+		if order, _ := child.GetStyles().GetFloat("order"); order != 0 {
+			reordered = true
+			break
+		}
+	}
+	if !reordered {
+		return children
+	}
+
+	sorted := append([]Node(nil), children...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, _ := sorted[i].GetStyles().GetFloat("order")
+		b, _ := sorted[j].GetStyles().GetFloat("order")
+		return a < b
+	})
+	return sorted
+}
+
+// containingBlock returns the area an out of flow node is placed against. Fixed nodes use
+// the window, absolute nodes their nearest positioned ancestor, or the window if there is none.
+// During layout only the size is meaningful, the position is set once the ancestor is arranged.
+func containingBlock(n Node) style.Rect {
+	position, _ := n.GetStyles().GetString("position")
+	root := n
+	for ancestor := n.Parent(); ancestor != nil; ancestor = ancestor.Parent() {
+		if position == "absolute" && isPositioned(ancestor) {
+			// The content of a scrolled ancestor moves with its offset
+			origin := ancestor.GetFinalBounds().Position
+			offset := ancestor.ScrollOffset()
+			origin.X -= offset.X
+			origin.Y -= offset.Y
+			return style.Rect{Position: origin, Size: ancestor.GetFinalSize()}
+		}
+		root = ancestor
+	}
+
+	if viewport, ok := root.(viewportNode); ok {
+		return style.Rect{Size: viewport.viewportSize()}
+	}
+	return style.Rect{Size: root.GetFinalSize()}
+}
+
+// insets resolves the top, right, bottom and left styles against the containing block.
+// Unset or auto insets are reported as missing.
+func insets(styles *style.Styles, block style.Size) (offsets style.EdgeInsets, top, right, bottom, left bool) {
+	offsets.Top, top = resolveLength(styles, "top", block.Height)
+	offsets.Right, right = resolveLength(styles, "right", block.Width)
+	offsets.Bottom, bottom = resolveLength(styles, "bottom", block.Height)
+	offsets.Left, left = resolveLength(styles, "left", block.Width)
+	return offsets, top, right, bottom, left
+}
+
+// layoutOutOfFlow sizes an absolutely positioned or fixed node against its containing block.
+// Without a size of its own the node stretches between its insets when both sides of an
+// axis are set, and otherwise keeps its natural size.
+func layoutOutOfFlow(ctx RenderContext, child Node) {
+	block := containingBlock(child).Size
+	styles := child.GetStyles()
+	styles.ResolvePercentages(block.Width)
+	margin, _ := styles.GetEdgeInsets("margin")
+	offsets, top, right, bottom, left := insets(styles, block)
+
+	size := child.Layout(ctx, Constraints{
+		MaxWidth:  math.Max(0, block.Width),
+		MaxHeight: math.Max(0, block.Height),
+	})
+
+	stretchWidth := left && right && isAutoLength(styles, "width")
+	stretchHeight := top && bottom && isAutoLength(styles, "height")
+	if !stretchWidth && !stretchHeight {
+		return
+	}
+
+	if stretchWidth {
+		minWidth, maxWidth := minMaxLengths(styles, true, block.Width)
+		width := block.Width - offsets.Left - offsets.Right - margin.Left - margin.Right
+		size.Width = clamp(math.Max(0, width), minWidth, maxWidth)
+	}
+	if stretchHeight {
+		minHeight, maxHeight := minMaxLengths(styles, false, block.Height)
+		height := block.Height - offsets.Top - offsets.Bottom - margin.Top - margin.Bottom
+		size.Height = clamp(math.Max(0, height), minHeight, maxHeight)
+	}
+	resizeChild(ctx, child, size, stretchWidth || !isAutoLength(styles, "width"), stretchHeight || !isAutoLength(styles, "height"))
+}
+
+// arrangeOutOfFlow positions an absolutely positioned or fixed node by its insets.
+// The left and top insets win over right and bottom. An axis without insets keeps
+// the node where it would start in the flow, at the parent's content edge.
+func arrangeOutOfFlow(ctx RenderContext, child Node, content style.Rect) {
+	block := containingBlock(child)
+	styles := child.GetStyles()
+	margin, _ := styles.GetEdgeInsets("margin")
+	offsets, top, right, bottom, left := insets(styles, block.Size)
+	size := child.GetFinalSize()
+
+	position := style.Point{
+		X: content.Position.X + margin.Left,
+		Y: content.Position.Y + margin.Top,
+	}
+	switch {
+	case left:
+		position.X = block.Position.X + offsets.Left + margin.Left
+	case right:
+		position.X = block.Position.X + block.Size.Width - offsets.Right - margin.Right - size.Width
+	}
+	switch {
+	case top:
+		position.Y = block.Position.Y + offsets.Top + margin.Top
+	case bottom:
+		position.Y = block.Position.Y + block.Size.Height - offsets.Bottom - margin.Bottom - size.Height
+	}
+
+	child.ArrangeChildren(ctx, style.Rect{Position: position, Size: size})
+}
+
+// relativeOffset shifts a relatively positioned node from where the flow placed it.
+// Percentages resolve against the parent's size.
+func (n *BaseNode) relativeOffset(position style.Point) style.Point {
+	if p, _ := n.styles.GetString("position"); p != "relative" || n.parent == nil {
+		return position
+	}
+
+	offsets, top, right, bottom, left := insets(&n.styles, n.parent.GetFinalSize())
+	switch {
+	case left:
+		position.X += offsets.Left
+	case right:
+		position.X -= offsets.Right
+	}
+	switch {
+	case top:
+		position.Y += offsets.Top
+	case bottom:
+		position.Y -= offsets.Bottom
+	}
+	return position
+}
+
+// isSticky returns true if the node stays in view within its nearest scrolling ancestor
+func isSticky(n Node) bool {
+	position, _ := n.GetStyles().GetString("position")
+	return position == "sticky"
+}
+
+// stickyOffset returns how far a sticky node at bounds moves down to keep its top inset
+// from the top of its nearest clipping ancestor, without leaving its parent's content box
+func (n *BaseNode) stickyOffset(bounds style.Rect) float64 {
+	if !isSticky(n) || n.parent == nil {
+		return 0
+	}
+	offsets, top, _, _, _ := insets(&n.styles, n.parent.GetFinalSize())
+	if !top {
+		return 0
+	}
+
+	var scroller Node
+	for ancestor := n.parent; ancestor != nil; ancestor = ancestor.Parent() {
+		if clipsContent(ancestor) {
+			scroller = ancestor
+			break
+		}
+	}
+	if scroller == nil {
+		return 0
+	}
+
+	target := scroller.GetFinalBounds().Position.Y + offsets.Top
+
+	// The parent's content scrolls within a scrolling parent, so it never runs out
+	if scroller != n.parent {
+		parent := n.parent.GetFinalBounds()
+		padding, _ := n.parent.GetStyles().GetEdgeInsets("padding")
+		margin, _ := n.styles.GetEdgeInsets("margin")
+		limit := parent.Position.Y + parent.Size.Height - padding.Bottom - margin.Bottom - bounds.Size.Height
+		target = math.Min(target, limit)
+	}
+	return math.Max(0, target-bounds.Position.Y)
+}
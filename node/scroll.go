@@ -0,0 +1,399 @@
+package node
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/noahdw/goui/node/style"
+)
+
+const (
+	// wheelStep is the distance one notch of the mouse wheel scrolls
+	wheelStep = 40.0
+	// lineStep is the distance the arrow keys scroll
+	lineStep = 40.0
+	// pageOverlap is the part of the view kept in sight when paging
+	pageOverlap = 40.0
+	// minThumbLength keeps scrollbar thumbs large enough to grab
+	minThumbLength = 20.0
+)
+
+// scrollDrag tracks a scrollbar thumb being dragged
+type scrollDrag struct {
+	active   bool
+	vertical bool
+	grab     float64 // distance from the start of the thumb to where it was grabbed
+}
+
+// scrollNode is implemented by nodes whose scrollbars take part in hit testing
+type scrollNode interface {
+	scrollbarAt(point style.Point) (vertical, onThumb, ok bool)
+}
+
+// shiftableNode is implemented by nodes that can be moved with the content they are scrolled in
+type shiftableNode interface {
+	shiftBounds(dx, dy float64, contained bool)
+}
+
+// clipsContent returns true if the node's overflow hides content outside its bounds
+func clipsContent(n Node) bool {
+	overflow, _ := n.GetStyles().GetString("overflow")
+	return overflow == "hidden" || overflow == "scroll" || overflow == "auto"
+}
+
+// isScrollable returns true if the user can scroll the node with the wheel, keyboard and scrollbars.
+// Hidden overflow can only be scrolled programmatically.
+func isScrollable(n Node) bool {
+	overflow, _ := n.GetStyles().GetString("overflow")
+	return overflow == "scroll" || overflow == "auto"
+}
+
+// ScrollOffset returns how far the content is scrolled from its start
+func (n *BaseNode) ScrollOffset() style.Point {
+	return n.scrollOffset
+}
+
+// ScrollTo scrolls the content so that the point x, y of it is at the top left of the node.
+// The offset is kept within the content, and nodes without clipped overflow do not scroll.
+func (n *BaseNode) ScrollTo(x, y float64) Node {
+	if !clipsContent(n) {
+		return n
+	}
+
+	target := style.Point{X: math.Max(0, x), Y: math.Max(0, y)}
+	if n.hasScrollExtent {
+		limit := n.maxScroll()
+		target.X = math.Min(target.X, limit.X)
+		target.Y = math.Min(target.Y, limit.Y)
+	}
+	if target == n.scrollOffset {
+		return n
+	}
+
+	// The arranged content moves with the offset, so no new layout is needed
+	dx, dy := n.scrollOffset.X-target.X, n.scrollOffset.Y-target.Y
+	if n.mirrorsFlow() {
+		dx = -dx
+	}
+	n.scrollOffset = target
+	n.shiftChildren(dx, dy, isPositioned(n))
+	n.MarkPaintDirty()
+	if n.scrolled != nil {
+		n.scrolled()
+	}
+	return n
+}
+
+// ScrollBy scrolls the content by dx, dy
+func (n *BaseNode) ScrollBy(dx, dy float64) Node {
+	return n.ScrollTo(n.scrollOffset.X+dx, n.scrollOffset.Y+dy)
+}
+
+// ScrollIntoView scrolls the node's clipping ancestors, innermost first, until the node is in
+// view. A node larger than a view is aligned with its start.
+func (n *BaseNode) ScrollIntoView() Node {
+	target := n.finalBounds
+	for ancestor := n.parent; ancestor != nil; ancestor = ancestor.Parent() {
+		if !clipsContent(ancestor) {
+			continue
+		}
+
+		view := ancestor.GetFinalBounds()
+		before := ancestor.ScrollOffset()
+		ancestor.ScrollTo(
+			before.X+revealDelta(target.Position.X, target.Size.Width, view.Position.X, view.Size.Width),
+			before.Y+revealDelta(target.Position.Y, target.Size.Height, view.Position.Y, view.Size.Height),
+		)
+
+		// The target moved with the content of the ancestor
+		after := ancestor.ScrollOffset()
+		target.Position.X -= after.X - before.X
+		target.Position.Y -= after.Y - before.Y
+	}
+	return n
+}
+
+// revealDelta returns how far a view must scroll on one axis to show a span
+func revealDelta(start, length, viewStart, viewLength float64) float64 {
+	if start < viewStart {
+		return start - viewStart
+	}
+	if end, viewEnd := start+length, viewStart+viewLength; end > viewEnd {
+		return math.Min(end-viewEnd, start-viewStart)
+	}
+	return 0
+}
+
+// maxScroll returns the largest offset on each axis, where the end of the content is in view
+func (n *BaseNode) maxScroll() style.Point {
+	return style.Point{
+		X: math.Max(0, n.scrollExtent.Width-n.finalBounds.Size.Width),
+		Y: math.Max(0, n.scrollExtent.Height-n.finalBounds.Size.Height),
+	}
+}
+
+// updateScrollExtent records the size of the laid out content including the padding
+// around it, and keeps the offset within it after the content or the node resized
+func (n *BaseNode) updateScrollExtent(content style.Size) {
+	extent := n.contentExtent(content)
+	padding, _ := n.styles.GetEdgeInsets("padding")
+	n.scrollExtent = style.Size{
+		Width:  extent.Width + padding.Left + padding.Right,
+		Height: extent.Height + padding.Top + padding.Bottom,
+	}
+	n.hasScrollExtent = true
+
+	limit := n.maxScroll()
+	n.scrollOffset.X = clamp(n.scrollOffset.X, 0, limit.X)
+	n.scrollOffset.Y = clamp(n.scrollOffset.Y, 0, limit.Y)
+}
+
+// shiftChildren moves the arranged children by dx, dy. Out of flow children placed against
+// a containing block outside the scrolled content stay where they are.
+func (n *BaseNode) shiftChildren(dx, dy float64, contained bool) {
+	for _, child := range n.children {
+		if position, _ := child.GetStyles().GetString("position"); position == "fixed" || (position == "absolute" && !contained) {
+			continue
+		}
+		if shiftable, ok := child.(shiftableNode); ok {
+			shiftable.shiftBounds(dx, dy, contained)
+		}
+	}
+}
+
+func (n *BaseNode) shiftBounds(dx, dy float64, contained bool) {
+	// A sticky node moves back to where the flow put it before it sticks again
+	if isSticky(n) {
+		bounds := n.finalBounds
+		bounds.Position.Y += dy - n.stuck
+		stuck := n.stickyOffset(bounds)
+		dy += stuck - n.stuck
+		n.stuck = stuck
+	}
+	n.finalBounds.Position.X += dx
+	n.finalBounds.Position.Y += dy
+	n.shiftChildren(dx, dy, contained || isPositioned(n))
+}
+
+// visibleScrollbars returns which scrollbars are shown. Scroll overflow always shows both,
+// auto overflow only those of the axes the content overflows on.
+func (n *BaseNode) visibleScrollbars() (vertical, horizontal bool) {
+	overflow, _ := n.styles.GetString("overflow")
+	switch overflow {
+	case "scroll":
+		return true, true
+	case "auto":
+		limit := n.maxScroll()
+		return limit.Y > 0, limit.X > 0
+	}
+	return false, false
+}
+
+// scrollbarRects returns the track and thumb of the vertical or horizontal scrollbar.
+// Scrollbars are drawn over the content along the right and bottom edges.
+func (n *BaseNode) scrollbarRects(vertical bool) (track, thumb style.Rect, ok bool) {
+	showVertical, showHorizontal := n.visibleScrollbars()
+	if (vertical && !showVertical) || (!vertical && !showHorizontal) {
+		return style.Rect{}, style.Rect{}, false
+	}
+
+	thickness, _ := n.styles.GetFloat("scrollbarWidth")
+	bounds := n.finalBounds
+	if vertical {
+		track = style.Rect{
+			Position: style.Point{X: bounds.Position.X + bounds.Size.Width - thickness, Y: bounds.Position.Y},
+			Size:     style.Size{Width: thickness, Height: bounds.Size.Height},
+		}
+		if showHorizontal {
+			track.Size.Height -= thickness
+		}
+		start, length := thumbSpan(track.Size.Height, bounds.Size.Height, n.scrollExtent.Height, n.scrollOffset.Y)
+		thumb = style.Rect{
+			Position: style.Point{X: track.Position.X, Y: track.Position.Y + start},
+			Size:     style.Size{Width: thickness, Height: length},
+		}
+		return track, thumb, true
+	}
+
+	track = style.Rect{
+		Position: style.Point{X: bounds.Position.X, Y: bounds.Position.Y + bounds.Size.Height - thickness},
+		Size:     style.Size{Width: bounds.Size.Width, Height: thickness},
+	}
+	if showVertical {
+		track.Size.Width -= thickness
+	}
+	start, length := thumbSpan(track.Size.Width, bounds.Size.Width, n.scrollExtent.Width, n.scrollOffset.X)
+	thumb = style.Rect{
+		Position: style.Point{X: track.Position.X + start, Y: track.Position.Y},
+		Size:     style.Size{Width: length, Height: thickness},
+	}
+	return track, thumb, true
+}
+
+// thumbSpan returns where a thumb starts in its track and how long it is. The thumb is as
+// much of the track as the view is of the content, and moves through it with the offset.
+func thumbSpan(trackLength, viewLength, extent, offset float64) (start, length float64) {
+	extent = math.Max(extent, viewLength)
+	length = trackLength
+	if extent > 0 {
+		length = math.Min(trackLength, math.Max(minThumbLength, trackLength*viewLength/extent))
+	}
+	if maxOffset := extent - viewLength; maxOffset > 0 {
+		start = (trackLength - length) * offset / maxOffset
+	}
+	return start, length
+}
+
+// scrollbarAt returns the scrollbar under a point and whether the point is on its thumb
+func (n *BaseNode) scrollbarAt(point style.Point) (vertical, onThumb, ok bool) {
+	for _, vertical := range []bool{true, false} {
+		track, thumb, shown := n.scrollbarRects(vertical)
+		if shown && contains(track, point) {
+			return vertical, contains(thumb, point), true
+		}
+	}
+	return false, false, false
+}
+
+// contains returns true if the point lies within the rectangle
+func contains(r style.Rect, p style.Point) bool {
+	return p.X >= r.Position.X && p.X < r.Position.X+r.Size.Width &&
+		p.Y >= r.Position.Y && p.Y < r.Position.Y+r.Size.Height
+}
+
+// paintScrollbars draws the tracks and thumbs over the content
+func (n *BaseNode) paintScrollbars(ctx RenderContext, opacity float64) {
+	trackColor, _ := n.styles.GetColor("scrollbarTrackColor")
+	thumbColor, _ := n.styles.GetColor("scrollbarColor")
+	for _, vertical := range []bool{true, false} {
+		track, thumb, ok := n.scrollbarRects(vertical)
+		if !ok {
+			continue
+		}
+		fillTranslucent(ctx, track, trackColor, opacity)
+		fillTranslucent(ctx, thumb, thumbColor, opacity)
+	}
+}
+
+// fillTranslucent fills a rectangle with a color whose alpha is applied as opacity
+func fillTranslucent(ctx RenderContext, rect style.Rect, color style.Color, opacity float64) {
+	if color.A == 0 {
+		return
+	}
+	ctx.Save()
+	ctx.SetOpacity(opacity * float64(color.A) / 255)
+	ctx.SetFillColor(color)
+	ctx.FillRect(rect)
+	ctx.Restore()
+}
+
+// handleScrollEvent scrolls a scrollable node in response to the wheel, its scrollbars and
+// the paging keys. It returns true if the event was used, which stops it from bubbling to
+// scrollable ancestors, so a node already at its end leaves the wheel to the node around it.
+func (n *BaseNode) handleScrollEvent(event UIEvent) bool {
+	if !isScrollable(n) {
+		return false
+	}
+
+	switch event.Type {
+	case UIWheel:
+		return n.scrollChanged(-event.DeltaX*wheelStep, -event.DeltaY*wheelStep)
+
+	case UIPress:
+		n.scrollDrag = scrollDrag{}
+		vertical, onThumb, ok := n.scrollbarAt(style.Point{X: event.MouseX, Y: event.MouseY})
+		if !ok {
+			return false
+		}
+
+		// Pressing the track pages towards the cursor, then the thumb can be dragged from there
+		_, thumb, _ := n.scrollbarRects(vertical)
+		thumbStart, cursor := thumb.Position.X, event.MouseX
+		if vertical {
+			thumbStart, cursor = thumb.Position.Y, event.MouseY
+		}
+		if !onThumb {
+			page := n.pageSize(vertical)
+			if cursor < thumbStart {
+				page = -page
+			}
+			if vertical {
+				n.ScrollBy(0, page)
+			} else {
+				n.ScrollBy(page, 0)
+			}
+		}
+		n.scrollDrag = scrollDrag{active: onThumb, vertical: vertical, grab: cursor - thumbStart}
+		return true
+
+	case UIDrag:
+		if !n.scrollDrag.active {
+			return false
+		}
+		n.dragThumb(event.MouseX, event.MouseY)
+		return true
+
+	case UIKeyPress:
+		switch int32(event.KeyCode) {
+		case rl.KeyDown:
+			return n.scrollChanged(0, lineStep)
+		case rl.KeyUp:
+			return n.scrollChanged(0, -lineStep)
+		case rl.KeyRight:
+			return n.scrollChanged(lineStep, 0)
+		case rl.KeyLeft:
+			return n.scrollChanged(-lineStep, 0)
+		case rl.KeyPageDown:
+			return n.scrollChanged(0, n.pageSize(true))
+		case rl.KeyPageUp:
+			return n.scrollChanged(0, -n.pageSize(true))
+		case rl.KeyHome:
+			return n.scrollChanged(0, -n.scrollOffset.Y)
+		case rl.KeyEnd:
+			return n.scrollChanged(0, n.maxScroll().Y-n.scrollOffset.Y)
+		}
+	}
+	return false
+}
+
+// scrollChanged scrolls by dx, dy and returns true if the offset moved
+func (n *BaseNode) scrollChanged(dx, dy float64) bool {
+	before := n.scrollOffset
+	n.ScrollBy(dx, dy)
+	return n.scrollOffset != before
+}
+
+// pageSize returns the distance a page scrolls, which keeps a little of the old view in sight
+func (n *BaseNode) pageSize(vertical bool) float64 {
+	view := n.finalBounds.Size.Width
+	if vertical {
+		view = n.finalBounds.Size.Height
+	}
+	return math.Max(view-pageOverlap, view/2)
+}
+
+// dragThumb moves the dragged thumb to follow the cursor, scrolling the content in proportion
+func (n *BaseNode) dragThumb(x, y float64) {
+	vertical := n.scrollDrag.vertical
+	track, thumb, ok := n.scrollbarRects(vertical)
+	if !ok {
+		return
+	}
+
+	trackStart, trackLength, thumbLength, cursor := track.Position.X, track.Size.Width, thumb.Size.Width, x
+	if vertical {
+		trackStart, trackLength, thumbLength, cursor = track.Position.Y, track.Size.Height, thumb.Size.Height, y
+	}
+	free := trackLength - thumbLength
+	if free <= 0 {
+		return
+	}
+
+	fraction := clamp((cursor-n.scrollDrag.grab-trackStart)/free, 0, 1)
+	limit := n.maxScroll()
+	if vertical {
+		n.ScrollTo(n.scrollOffset.X, fraction*limit.Y)
+	} else {
+		n.ScrollTo(fraction*limit.X, n.scrollOffset.Y)
+	}
+}
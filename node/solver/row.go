@@ -0,0 +1,110 @@
+package solver
+
+import (
+	"math"
+	"sort"
+)
+
+// symbolKind tells what a symbol in the tableau stands for
+type symbolKind int
+
+const (
+	invalidSymbol  symbolKind = iota
+	externalSymbol            // A variable of the caller
+	slackSymbol               // Turns an inequality into an equation
+	errorSymbol               // How far a non-required constraint is from being met
+	dummySymbol               // Marks a required equation, and is never pivoted on
+)
+
+// symbol is a variable of the tableau
+type symbol struct {
+	id   int
+	kind symbolKind
+}
+
+// row is the equation basic = constant + sum of cells, for the symbol the row is solved for
+type row struct {
+	constant float64
+	cells    map[symbol]float64
+}
+
+// epsilon is how close to 0 a coefficient has to be to be dropped
+const epsilon = 1e-8
+
+func nearZero(value float64) bool {
+	return math.Abs(value) < epsilon
+}
+
+func newRow(constant float64) *row {
+	return &row{constant: constant, cells: make(map[symbol]float64)}
+}
+
+// copy returns a row that can be changed without changing r
+func (r *row) copy() *row {
+	c := newRow(r.constant)
+	for s, coefficient := range r.cells {
+		c.cells[s] = coefficient
+	}
+	return c
+}
+
+// symbols returns the symbols of the row in the order they were created, so that the
+// solver makes the same choices every time
+func (r *row) symbols() []symbol {
+	symbols := make([]symbol, 0, len(r.cells))
+	for s := range r.cells {
+		symbols = append(symbols, s)
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i].id < symbols[j].id })
+	return symbols
+}
+
+// insertSymbol adds coefficient times s to the row, dropping s if it cancels out
+func (r *row) insertSymbol(s symbol, coefficient float64) {
+	value := r.cells[s] + coefficient
+	if nearZero(value) {
+		delete(r.cells, s)
+		return
+	}
+	r.cells[s] = value
+}
+
+// insertRow adds coefficient times other to the row
+func (r *row) insertRow(other *row, coefficient float64) {
+	r.constant += other.constant * coefficient
+	for s, c := range other.cells {
+		r.insertSymbol(s, c*coefficient)
+	}
+}
+
+// reverseSign negates the constant and every coefficient
+func (r *row) reverseSign() {
+	r.constant = -r.constant
+	for s, c := range r.cells {
+		r.cells[s] = -c
+	}
+}
+
+// solveFor rearranges the row, which equals 0, to be solved for s, which it must contain
+func (r *row) solveFor(s symbol) {
+	coefficient := -1 / r.cells[s]
+	delete(r.cells, s)
+	r.constant *= coefficient
+	for other, c := range r.cells {
+		r.cells[other] = c * coefficient
+	}
+}
+
+// solveForPair rearranges the row, which is solved for lhs, to be solved for rhs instead
+func (r *row) solveForPair(lhs, rhs symbol) {
+	r.insertSymbol(lhs, -1)
+	r.solveFor(rhs)
+}
+
+// substitute replaces s in the row with the row it is solved by
+func (r *row) substitute(s symbol, other *row) {
+	if coefficient, ok := r.cells[s]; ok {
+		delete(r.cells, s)
+		r.insertRow(other, coefficient)
+	}
+}
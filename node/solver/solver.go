@@ -0,0 +1,354 @@
+// Package solver implements the Cassowary linear constraint solver, as described in
+// "The Cassowary Linear Arithmetic Constraint Solving Algorithm" by Badros, Borning and
+// Stuckey, and following the Kiwi implementation.
+//
+// Constraints are linear equations and inequalities over variables. Required constraints
+// always hold, and the solver fails to add one that contradicts them. Weaker constraints
+// are met as closely as possible, stronger ones taking precedence over weaker ones.
+package solver
+
+import (
+	"errors"
+	"sort"
+)
+
+// Strength is how much a constraint matters compared to the others
+type Strength float64
+
+const (
+	Weak     Strength = 1
+	Medium   Strength = 1e3
+	Strong   Strength = 1e6
+	Required Strength = 1001001000 // Strong, Medium and Weak together count for less
+)
+
+// Operator relates the expression of a constraint to 0
+type Operator int
+
+const (
+	LessOrEqual Operator = iota
+	Equal
+	GreaterOrEqual
+)
+
+var (
+	ErrDuplicateConstraint = errors.New("solver: duplicate constraint")
+	ErrUnsatisfiable       = errors.New("solver: required constraint cannot be satisfied")
+	ErrUnbounded           = errors.New("solver: objective is unbounded")
+)
+
+// Variable is a value the solver works out. Value holds the solution after UpdateVariables.
+type Variable struct {
+	Name  string
+	Value float64
+}
+
+// NewVariable creates a variable, named for debugging
+func NewVariable(name string) *Variable {
+	return &Variable{Name: name}
+}
+
+// Term is a variable times a coefficient
+type Term struct {
+	Variable    *Variable
+	Coefficient float64
+}
+
+// Expression is a sum of terms plus a constant
+type Expression struct {
+	Terms    []Term
+	Constant float64
+}
+
+// Constraint holds when Expression compares to 0 as Operator says, e.g. x - y + 10 <= 0
+type Constraint struct {
+	Expression Expression
+	Operator   Operator
+	Strength   Strength
+}
+
+// NewConstraint creates a constraint, with strengths above Required counting as Required
+func NewConstraint(expression Expression, operator Operator, strength Strength) *Constraint {
+	return &Constraint{Expression: expression, Operator: operator, Strength: min(strength, Required)}
+}
+
+// tag holds the symbols a constraint added to the tableau
+type tag struct {
+	marker symbol
+	other  symbol
+}
+
+// Solver finds values for the variables of its constraints
+type Solver struct {
+	constraints map[*Constraint]tag
+	rows        map[symbol]*row
+	variables   map[*Variable]symbol
+	objective   *row
+	artificial  *row
+	nextID      int
+}
+
+// NewSolver creates a solver without constraints
+func NewSolver() *Solver {
+	return &Solver{
+		constraints: make(map[*Constraint]tag),
+		rows:        make(map[symbol]*row),
+		variables:   make(map[*Variable]symbol),
+		objective:   newRow(0),
+	}
+}
+
+// AddConstraint adds a constraint and solves the system again. A required constraint that
+// contradicts the required constraints already added returns ErrUnsatisfiable, after which
+// the solver should be discarded.
+func (s *Solver) AddConstraint(c *Constraint) error {
+	if _, ok := s.constraints[c]; ok {
+		return ErrDuplicateConstraint
+	}
+
+	var t tag
+	r := s.createRow(c, &t)
+	subject := s.chooseSubject(r, t)
+
+	// A row of only dummies is satisfied already or never
+	if subject.kind == invalidSymbol && allDummies(r) {
+		if !nearZero(r.constant) {
+			return ErrUnsatisfiable
+		}
+		subject = t.marker
+	}
+
+	if subject.kind == invalidSymbol {
+		if !s.addWithArtificialVariable(r) {
+			return ErrUnsatisfiable
+		}
+	} else {
+		r.solveFor(subject)
+		s.substitute(subject, r)
+		s.rows[subject] = r
+	}
+
+	s.constraints[c] = t
+	return s.optimize(s.objective)
+}
+
+// UpdateVariables stores the current solution in the variables
+func (s *Solver) UpdateVariables() {
+	for v, sym := range s.variables {
+		if r, ok := s.rows[sym]; ok {
+			v.Value = r.constant
+		} else {
+			v.Value = 0
+		}
+	}
+}
+
+func (s *Solver) newSymbol(kind symbolKind) symbol {
+	s.nextID++
+	return symbol{id: s.nextID, kind: kind}
+}
+
+// variableSymbol returns the symbol of a variable, creating one the first time
+func (s *Solver) variableSymbol(v *Variable) symbol {
+	if sym, ok := s.variables[v]; ok {
+		return sym
+	}
+	sym := s.newSymbol(externalSymbol)
+	s.variables[v] = sym
+	return sym
+}
+
+// createRow turns a constraint into a row with the current basic variables substituted.
+// Inequalities get a slack symbol, and constraints that are not required get error symbols
+// that the objective minimizes.
+func (s *Solver) createRow(c *Constraint, t *tag) *row {
+	r := newRow(c.Expression.Constant)
+	for _, term := range c.Expression.Terms {
+		if nearZero(term.Coefficient) {
+			continue
+		}
+		sym := s.variableSymbol(term.Variable)
+		if basic, ok := s.rows[sym]; ok {
+			r.insertRow(basic, term.Coefficient)
+		} else {
+			r.insertSymbol(sym, term.Coefficient)
+		}
+	}
+
+	strength := float64(c.Strength)
+	switch c.Operator {
+	case LessOrEqual, GreaterOrEqual:
+		coefficient := 1.0
+		if c.Operator == GreaterOrEqual {
+			coefficient = -1
+		}
+		slack := s.newSymbol(slackSymbol)
+		t.marker = slack
+		r.insertSymbol(slack, coefficient)
+		if c.Strength < Required {
+			errorSym := s.newSymbol(errorSymbol)
+			t.other = errorSym
+			r.insertSymbol(errorSym, -coefficient)
+			s.objective.insertSymbol(errorSym, strength)
+		}
+	case Equal:
+		if c.Strength < Required {
+			plus := s.newSymbol(errorSymbol)
+			minus := s.newSymbol(errorSymbol)
+			t.marker = plus
+			t.other = minus
+			r.insertSymbol(plus, -1)
+			r.insertSymbol(minus, 1)
+			s.objective.insertSymbol(plus, strength)
+			s.objective.insertSymbol(minus, strength)
+		} else {
+			dummy := s.newSymbol(dummySymbol)
+			t.marker = dummy
+			r.insertSymbol(dummy, 1)
+		}
+	}
+
+	// The constant of a row is kept positive
+	if r.constant < 0 {
+		r.reverseSign()
+	}
+	return r
+}
+
+// chooseSubject picks the symbol to solve a new row for: a variable of the caller, or else
+// a slack or error symbol with a negative coefficient, which keeps the row feasible
+func (s *Solver) chooseSubject(r *row, t tag) symbol {
+	for _, sym := range r.symbols() {
+		if sym.kind == externalSymbol {
+			return sym
+		}
+	}
+	for _, sym := range []symbol{t.marker, t.other} {
+		if (sym.kind == slackSymbol || sym.kind == errorSymbol) && r.cells[sym] < 0 {
+			return sym
+		}
+	}
+	return symbol{}
+}
+
+// allDummies returns true if the row only has dummy symbols
+func allDummies(r *row) bool {
+	for sym := range r.cells {
+		if sym.kind != dummySymbol {
+			return false
+		}
+	}
+	return true
+}
+
+// addWithArtificialVariable adds a row that has no subject by solving it for an artificial
+// variable and minimizing that variable. The row can be satisfied if it reaches 0.
+func (s *Solver) addWithArtificialVariable(r *row) bool {
+	art := s.newSymbol(slackSymbol)
+	s.rows[art] = r.copy()
+	s.artificial = r.copy()
+	if err := s.optimize(s.artificial); err != nil {
+		s.artificial = nil
+		return false
+	}
+	success := nearZero(s.artificial.constant)
+	s.artificial = nil
+
+	// The artificial variable has to leave the basis before it is removed
+	if basic, ok := s.rows[art]; ok {
+		delete(s.rows, art)
+		if len(basic.cells) == 0 {
+			return success
+		}
+		entering := anyPivotableSymbol(basic)
+		if entering.kind == invalidSymbol {
+			return false
+		}
+		basic.solveForPair(art, entering)
+		s.substitute(entering, basic)
+		s.rows[entering] = basic
+	}
+
+	for _, other := range s.rows {
+		delete(other.cells, art)
+	}
+	delete(s.objective.cells, art)
+	return success
+}
+
+// anyPivotableSymbol returns the first slack or error symbol of the row
+func anyPivotableSymbol(r *row) symbol {
+	for _, sym := range r.symbols() {
+		if sym.kind == slackSymbol || sym.kind == errorSymbol {
+			return sym
+		}
+	}
+	return symbol{}
+}
+
+// substitute replaces sym with the row it is now solved by everywhere in the tableau
+func (s *Solver) substitute(sym symbol, r *row) {
+	for _, other := range s.rows {
+		other.substitute(sym, r)
+	}
+	s.objective.substitute(sym, r)
+	if s.artificial != nil {
+		s.artificial.substitute(sym, r)
+	}
+}
+
+// optimize pivots with the primal simplex method until the objective is minimal
+func (s *Solver) optimize(objective *row) error {
+	for {
+		entering := enteringSymbol(objective)
+		if entering.kind == invalidSymbol {
+			return nil
+		}
+		leaving, ok := s.leavingSymbol(entering)
+		if !ok {
+			return ErrUnbounded
+		}
+		r := s.rows[leaving]
+		delete(s.rows, leaving)
+		r.solveForPair(leaving, entering)
+		s.substitute(entering, r)
+		s.rows[entering] = r
+	}
+}
+
+// enteringSymbol returns a symbol that lowers the objective as it grows, if there is one
+func enteringSymbol(objective *row) symbol {
+	for _, sym := range objective.symbols() {
+		if sym.kind != dummySymbol && objective.cells[sym] < 0 {
+			return sym
+		}
+	}
+	return symbol{}
+}
+
+// leavingSymbol returns the basic symbol whose row limits how far entering can grow the most
+func (s *Solver) leavingSymbol(entering symbol) (symbol, bool) {
+	basics := make([]symbol, 0, len(s.rows))
+	for sym := range s.rows {
+		basics = append(basics, sym)
+	}
+	sort.Slice(basics, func(i, j int) bool { return basics[i].id < basics[j].id })
+
+	var leaving symbol
+	found := false
+	ratio := 0.0
+	for _, sym := range basics {
+		if sym.kind == externalSymbol {
+			continue
+		}
+		r := s.rows[sym]
+		coefficient, ok := r.cells[entering]
+		if !ok || coefficient >= 0 {
+			continue
+		}
+		if limit := -r.constant / coefficient; !found || limit < ratio {
+			leaving, ratio, found = sym, limit, true
+		}
+	}
+	return leaving, found
+}
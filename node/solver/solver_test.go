@@ -0,0 +1,185 @@
+package solver
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// term returns coefficient times v
+func term(v *Variable, coefficient float64) Term {
+	return Term{Variable: v, Coefficient: coefficient}
+}
+
+// equals returns a constraint holding v at value
+func equals(v *Variable, value float64, strength Strength) *Constraint {
+	return NewConstraint(Expression{Terms: []Term{term(v, 1)}, Constant: -value}, Equal, strength)
+}
+
+// mustAdd adds the constraints, failing the test if one cannot be added
+func mustAdd(t *testing.T, s *Solver, constraints ...*Constraint) {
+	t.Helper()
+	for _, c := range constraints {
+		if err := s.AddConstraint(c); err != nil {
+			t.Fatalf("AddConstraint: %v", err)
+		}
+	}
+}
+
+// expectValue fails the test if v was not solved to want
+func expectValue(t *testing.T, v *Variable, want float64) {
+	t.Helper()
+	if math.Abs(v.Value-want) > 1e-6 {
+		t.Errorf("%s = %v, want %v", v.Name, v.Value, want)
+	}
+}
+
+func TestRequiredEquality(t *testing.T) {
+	x, y := NewVariable("x"), NewVariable("y")
+	s := NewSolver()
+	// x + y == 30, x == 10
+	mustAdd(t, s,
+		NewConstraint(Expression{Terms: []Term{term(x, 1), term(y, 1)}, Constant: -30}, Equal, Required),
+		equals(x, 10, Required),
+	)
+	s.UpdateVariables()
+	expectValue(t, x, 10)
+	expectValue(t, y, 20)
+}
+
+func TestStrengths(t *testing.T) {
+	tests := []struct {
+		name    string
+		first   Strength
+		second  Strength
+		wantX   float64
+		reverse bool
+	}{
+		{"required beats weak", Required, Weak, 10, false},
+		{"strong beats weak", Strong, Weak, 10, false},
+		{"strong beats medium", Strong, Medium, 10, false},
+		{"order does not matter", Strong, Weak, 10, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := NewVariable("x")
+			constraints := []*Constraint{equals(x, 10, tt.first), equals(x, 20, tt.second)}
+			if tt.reverse {
+				constraints[0], constraints[1] = constraints[1], constraints[0]
+			}
+			s := NewSolver()
+			mustAdd(t, s, constraints...)
+			s.UpdateVariables()
+			expectValue(t, x, tt.wantX)
+		})
+	}
+}
+
+func TestWeakConstraintsMeetHalfway(t *testing.T) {
+	x := NewVariable("x")
+	s := NewSolver()
+	// Two conflicting constraints of equal strength settle on one of them or between them
+	mustAdd(t, s, equals(x, 10, Weak), equals(x, 20, Weak))
+	s.UpdateVariables()
+	if x.Value < 10-1e-6 || x.Value > 20+1e-6 {
+		t.Errorf("x = %v, want between 10 and 20", x.Value)
+	}
+}
+
+func TestUnsatisfiableRequired(t *testing.T) {
+	x := NewVariable("x")
+	s := NewSolver()
+	mustAdd(t, s, equals(x, 10, Required))
+
+	if err := s.AddConstraint(equals(x, 20, Required)); !errors.Is(err, ErrUnsatisfiable) {
+		t.Fatalf("AddConstraint = %v, want %v", err, ErrUnsatisfiable)
+	}
+	// The solver keeps the constraints it had
+	s.UpdateVariables()
+	expectValue(t, x, 10)
+}
+
+func TestDuplicateConstraint(t *testing.T) {
+	x := NewVariable("x")
+	c := equals(x, 10, Required)
+	s := NewSolver()
+	mustAdd(t, s, c)
+	if err := s.AddConstraint(c); !errors.Is(err, ErrDuplicateConstraint) {
+		t.Errorf("AddConstraint = %v, want %v", err, ErrDuplicateConstraint)
+	}
+}
+
+func TestInequalities(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator Operator
+		bound    float64
+		prefer   float64
+		want     float64
+	}{
+		{"upper bound holds", LessOrEqual, 50, 80, 50},
+		{"upper bound is slack", LessOrEqual, 50, 30, 30},
+		{"lower bound holds", GreaterOrEqual, 50, 30, 50},
+		{"lower bound is slack", GreaterOrEqual, 50, 80, 80},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := NewVariable("x")
+			s := NewSolver()
+			// x - bound compares to 0, while x prefers another value
+			mustAdd(t, s,
+				NewConstraint(Expression{Terms: []Term{term(x, 1)}, Constant: -tt.bound}, tt.operator, Required),
+				equals(x, tt.prefer, Strong),
+			)
+			s.UpdateVariables()
+			expectValue(t, x, tt.want)
+		})
+	}
+}
+
+func TestUnsatisfiableInequalities(t *testing.T) {
+	x := NewVariable("x")
+	s := NewSolver()
+	mustAdd(t, s, NewConstraint(Expression{Terms: []Term{term(x, 1)}, Constant: -50}, GreaterOrEqual, Required))
+	err := s.AddConstraint(NewConstraint(Expression{Terms: []Term{term(x, 1)}, Constant: -40}, LessOrEqual, Required))
+	if !errors.Is(err, ErrUnsatisfiable) {
+		t.Errorf("AddConstraint = %v, want %v", err, ErrUnsatisfiable)
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	// solve lays out three boxes in a row that would all like to be wider than they fit
+	solve := func() []float64 {
+		s := NewSolver()
+		widths := []*Variable{NewVariable("a"), NewVariable("b"), NewVariable("c")}
+		sum := Expression{Constant: -100}
+		for _, w := range widths {
+			sum.Terms = append(sum.Terms, term(w, 1))
+			mustAdd(t, s,
+				NewConstraint(Expression{Terms: []Term{term(w, 1)}}, GreaterOrEqual, Required),
+				equals(w, 60, Weak),
+			)
+		}
+		mustAdd(t, s, NewConstraint(sum, Equal, Required))
+		s.UpdateVariables()
+
+		values := make([]float64, len(widths))
+		for i, w := range widths {
+			values[i] = w.Value
+		}
+		return values
+	}
+
+	want := solve()
+	if total := want[0] + want[1] + want[2]; math.Abs(total-100) > 1e-6 {
+		t.Fatalf("widths %v add up to %v, want 100", want, total)
+	}
+	for run := 0; run < 20; run++ {
+		got := solve()
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("run %d: widths %v, want %v", run, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package node
+
+import (
+	"math"
+	"strings"
+
+	"github.com/noahdw/goui/node/style"
+)
+
+// isStack returns true if the children are layered on top of each other in the content box
+func (n *BaseNode) isStack() bool {
+	display, _ := n.styles.GetString("display")
+	return display == "stack"
+}
+
+// measureStack takes the largest child on each axis, since the children overlap
+func measureStack(children []Node, preferred []style.Size) (minContent, maxContent style.Size) {
+	for i, child := range children {
+		margin, _ := child.GetStyles().GetEdgeInsets("margin")
+		maxContent = largestSize(maxContent, outerSize(preferred[i], margin))
+		minContent = largestSize(minContent, outerSize(minContentContribution(child, preferred[i]), margin))
+	}
+	return minContent, maxContent
+}
+
+// layoutStack lays out each child in the whole content box
+func (n *BaseNode) layoutStack(ctx RenderContext, available style.Size) {
+	for _, child := range n.flowChildren() {
+		margin, _ := child.GetStyles().GetEdgeInsets("margin")
+		child.Layout(ctx, Constraints{
+			MaxWidth:  math.Max(0, available.Width-margin.Left-margin.Right),
+			MaxHeight: math.Max(0, available.Height-margin.Top-margin.Bottom),
+		})
+	}
+}
+
+// stackExtent returns the size of the largest child, which an auto sized stack fits
+func (n *BaseNode) stackExtent() style.Size {
+	var extent style.Size
+	for _, child := range n.flowChildren() {
+		margin, _ := child.GetStyles().GetEdgeInsets("margin")
+		extent = largestSize(extent, outerSize(child.GetFinalSize(), margin))
+	}
+	return extent
+}
+
+// arrangeStack aligns each child within the content box and moves it by its offset.
+// Later children paint over and are hit before earlier ones, as in the flow.
+func (n *BaseNode) arrangeStack(ctx RenderContext, content style.Rect) {
+	children := n.flowChildren()
+	placements := make([]placement, 0, len(children))
+	for _, child := range children {
+		styles := child.GetStyles()
+		size := child.GetFinalSize()
+		margin, _ := styles.GetEdgeInsets("margin")
+		align, _ := styles.GetString("stackAlign")
+		offsetX, _ := styles.GetFloat("stackOffsetX")
+		offsetY, _ := styles.GetFloat("stackOffsetY")
+
+		x, y := stackAlignment(align)
+		freeX := content.Size.Width - margin.Left - size.Width - margin.Right
+		freeY := content.Size.Height - margin.Top - size.Height - margin.Bottom
+		placements = append(placements, placement{node: child, bounds: style.Rect{
+			Position: style.Point{
+				X: content.Position.X + margin.Left + freeX*x + offsetX,
+				Y: content.Position.Y + margin.Top + freeY*y + offsetY,
+			},
+			Size: size,
+		}})
+	}
+	arrangePlacements(ctx, placements)
+}
+
+// stackAlignment returns how far across the free space a child is placed on each axis,
+// from 0 at the top or left to 1 at the bottom or right. A single side such as "top"
+// centers the child along that side.
+func stackAlignment(align string) (x, y float64) {
+	x, y = 0.5, 0.5
+	if align == "" {
+		return 0, 0
+	}
+	for _, part := range strings.Split(align, "-") {
+		switch part {
+		case "top":
+			y = 0
+		case "bottom":
+			y = 1
+		case "left":
+			x = 0
+		case "right":
+			x = 1
+		}
+	}
+	return x, y
+}
+
+// largestSize returns the larger of two sizes on each axis
+func largestSize(a, b style.Size) style.Size {
+	return style.Size{Width: math.Max(a.Width, b.Width), Height: math.Max(a.Height, b.Height)}
+}
@@ -0,0 +1,251 @@
+package node
+
+import (
+	"sort"
+
+	"github.com/noahdw/goui/node/style"
+)
+
+// stackingNode is implemented by nodes that record when they are painting a stacking context
+type stackingNode interface {
+	paintingStack() bool
+}
+
+func (n *BaseNode) paintingStack() bool {
+	return n.paintingLayers
+}
+
+// zIndex returns the node's stack level, with auto treated as 0
+func zIndex(n Node) int {
+	z, _ := n.GetStyles().GetFloat("zIndex")
+	return int(z)
+}
+
+// hasZIndex returns true if the node has a zIndex other than auto
+func hasZIndex(n Node) bool {
+	_, ok := n.GetStyles().GetFloat("zIndex")
+	return ok
+}
+
+// isStacked returns true if the node is painted in zIndex order instead of in the normal flow.
+// As every container is a flex or grid container, a zIndex stacks a node even when it is static.
+// Sticky nodes stay in the flow so that they are clipped with the content they scroll over.
+func isStacked(n Node) bool {
+	return (isPositioned(n) && !isSticky(n)) || hasZIndex(n)
+}
+
+// paintOrder returns the normal flow children in the order they are painted, which follows
+// their order style, with sticky children after their siblings so that the content scrolls
+// under them
+func paintOrder(children []Node) []Node {
+	ordered := make([]Node, 0, len(children))
+	var sticky []Node
+	for _, child := range inOrder(children) {
+		switch {
+		case isStacked(child):
+		case isSticky(child):
+			sticky = append(sticky, child)
+		default:
+			ordered = append(ordered, child)
+		}
+	}
+	return append(ordered, sticky...)
+}
+
+// isStackingContext returns true if the node orders its stacked descendants itself instead of
+// leaving them to an ancestor. Nodes painted through an offscreen layer always do, as the
+// layer is composited as a whole.
+func isStackingContext(n Node) bool {
+	if n.Parent() == nil || hasZIndex(n) {
+		return true
+	}
+
+	styles := n.GetStyles()
+	if position, _ := styles.GetString("position"); position == "fixed" {
+		return true
+	}
+	if opacity, ok := styles.GetFloat("opacity"); ok && opacity < 1.0 {
+		return true
+	}
+	if scale, ok := styles.GetFloat("scale"); ok && scale != 1.0 {
+		return true
+	}
+	if blendMode, _ := styles.GetString("mixBlendMode"); blendMode != "" && blendMode != "normal" {
+		return true
+	}
+	if layer, _ := styles.Get("layer"); layer == true {
+		return true
+	}
+	return len(parseFilters(styles)) > 0
+}
+
+// stackingLayers returns the stacked descendants ordered by a stacking context, lowest first.
+// Nodes with the same zIndex keep their tree order. Descendants of nested stacking contexts
+// are left to those contexts.
+func stackingLayers(n Node) []Node {
+	var layers []Node
+	var collect func(parent Node)
+	collect = func(parent Node) {
+		for _, child := range parent.Children() {
+			if isStacked(child) {
+				layers = append(layers, child)
+			}
+			if !isStackingContext(child) {
+				collect(child)
+			}
+		}
+	}
+	collect(n)
+
+	sort.SliceStable(layers, func(i, j int) bool {
+		return zIndex(layers[i]) < zIndex(layers[j])
+	})
+	return layers
+}
+
+// splitLayers returns the index of the first layer painted above the normal flow
+func splitLayers(layers []Node) int {
+	return sort.Search(len(layers), func(i int) bool {
+		return zIndex(layers[i]) >= 0
+	})
+}
+
+// layerClip returns the area a stacked descendant of root is clipped to by the ancestors in
+// between. Like CSS, an ancestor only clips it if the ancestor is on its chain of containing
+// blocks, so fixed nodes and absolute nodes placed against a block outside a scroller are
+// not clipped by it.
+func layerClip(layer, root Node) (clip style.Rect, clipped bool) {
+	position, _ := layer.GetStyles().GetString("position")
+	for ancestor := layer.Parent(); ancestor != nil && ancestor != root; ancestor = ancestor.Parent() {
+		if position == "fixed" {
+			break
+		}
+		if position == "absolute" && !isPositioned(ancestor) {
+			continue
+		}
+		if clipsContent(ancestor) {
+			bounds := ancestor.GetFinalBounds()
+			if clipped {
+				bounds = clip.Intersection(bounds)
+			}
+			clip, clipped = bounds, true
+		}
+		position, _ = ancestor.GetStyles().GetString("position")
+	}
+	return clip, clipped
+}
+
+// paintLayer paints a stacked descendant within the ancestors that clip it
+func (n *BaseNode) paintLayer(ctx RenderContext, layer Node) {
+	clip, clipped := layerClip(layer, n)
+	if !clipped {
+		layer.Paint(ctx)
+		return
+	}
+	saved := ctx.ClipRect()
+	ctx.SetClipRect(saved.Intersection(clip))
+	layer.Paint(ctx)
+	ctx.SetClipRect(saved)
+}
+
+// isStackingRoot returns true if the node paints its stacked descendants itself. Besides
+// stacking contexts this is the case when a subtree is painted on its own, without the
+// enclosing stacking context that would otherwise paint them.
+func (n *BaseNode) isStackingRoot() bool {
+	if isStackingContext(n) {
+		return true
+	}
+	for ancestor := n.parent; ancestor != nil; ancestor = ancestor.Parent() {
+		if painter, ok := ancestor.(stackingNode); ok && painter.paintingStack() {
+			return false
+		}
+		if isStackingContext(ancestor) {
+			return true
+		}
+	}
+	return true
+}
+
+// paintChildren paints the children in stacking order. A stacking root paints its stacked
+// descendants with a negative zIndex below its normal flow children and the rest above them,
+// other nodes only paint their normal flow children.
+func (n *BaseNode) paintChildren(ctx RenderContext) {
+	if !n.isStackingRoot() {
+		for _, child := range paintOrder(n.children) {
+			child.Paint(ctx)
+		}
+		return
+	}
+
+	layers := stackingLayers(n)
+	split := splitLayers(layers)
+	n.paintingLayers = true
+	defer func() { n.paintingLayers = false }()
+
+	for _, layer := range layers[:split] {
+		n.paintLayer(ctx, layer)
+	}
+	for _, child := range paintOrder(n.children) {
+		child.Paint(ctx)
+	}
+	for _, layer := range layers[split:] {
+		n.paintLayer(ctx, layer)
+	}
+}
+
+// HitTest returns the topmost node under the cursor, or nil if there is none.
+// Nodes are tested in the reverse of the order they are painted in, so overlays
+// with a higher zIndex receive input before the nodes beneath them.
+func HitTest(root Node, cursor style.Rect) Node {
+	return hitTest(root, cursor, true)
+}
+
+func hitTest(n Node, cursor style.Rect, root bool) Node {
+	var layers []Node
+	if root || isStackingContext(n) {
+		layers = stackingLayers(n)
+	}
+	split := splitLayers(layers)
+
+	for i := len(layers) - 1; i >= split; i-- {
+		if hit := hitTestLayer(layers[i], n, cursor); hit != nil {
+			return hit
+		}
+	}
+
+	// Normal flow children are only tested inside their parent, and not under its scrollbars
+	bounds := n.GetFinalBounds()
+	inside := bounds.Intersects(cursor)
+	if scroller, ok := n.(scrollNode); ok && inside {
+		if _, _, onScrollbar := scroller.scrollbarAt(cursor.Position); onScrollbar {
+			return n
+		}
+	}
+	if inside {
+		children := paintOrder(n.Children())
+		for i := len(children) - 1; i >= 0; i-- {
+			if hit := hitTest(children[i], cursor, false); hit != nil {
+				return hit
+			}
+		}
+	}
+
+	for i := split - 1; i >= 0; i-- {
+		if hit := hitTestLayer(layers[i], n, cursor); hit != nil {
+			return hit
+		}
+	}
+
+	if inside {
+		return n
+	}
+	return nil
+}
+
+// hitTestLayer tests a stacked descendant of root where the ancestors that clip it show it
+func hitTestLayer(layer, root Node, cursor style.Rect) Node {
+	if clip, clipped := layerClip(layer, root); clipped && !clip.Intersects(cursor) {
+		return nil
+	}
+	return hitTest(layer, cursor, false)
+}
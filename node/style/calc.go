@@ -0,0 +1,241 @@
+package style
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// calcLength is the value of a calc() expression, kept as a sum of pixels, percentages,
+// em and rem so that the parts can be resolved once their bases are known
+type calcLength struct {
+	Pixels  float64
+	Percent float64
+	Em      float64
+	Rem     float64
+}
+
+// calcTerm is an operand of a calc() expression. Plain numbers are scalars, which can
+// multiply and divide lengths and are treated as pixels when added to them.
+type calcTerm struct {
+	length calcLength
+	scalar bool
+}
+
+// calcParser parses calc() expressions with a recursive descent over the text
+type calcParser struct {
+	text string
+	pos  int
+}
+
+// parseCalc parses an expression such as calc(100% - 2 * 1.5em) mixing px, %, em and rem
+// with + - * / and parentheses
+func parseCalc(value string) (styleValue, error) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "calc(") || !strings.HasSuffix(value, ")") {
+		return styleValue{}, styleError{Property: "calc", Value: value, Message: "Expected calc(...)"}
+	}
+
+	p := &calcParser{text: value[len("calc(") : len(value)-1]}
+	term, err := p.sum()
+	if err == nil {
+		p.skipSpace()
+		if p.pos < len(p.text) {
+			err = p.error("Unexpected " + strconv.Quote(p.text[p.pos:]))
+		}
+	}
+	if err != nil {
+		return styleValue{}, styleError{Property: "calc", Value: value, Message: err.Error()}
+	}
+	return styleValue{Type: calc, Value: term.length, Source: explicit}, nil
+}
+
+// sum parses terms joined by + and -
+func (p *calcParser) sum() (calcTerm, error) {
+	left, err := p.product()
+	if err != nil {
+		return calcTerm{}, err
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.text) || (p.text[p.pos] != '+' && p.text[p.pos] != '-') {
+			return left, nil
+		}
+		op := p.text[p.pos]
+		p.pos++
+		right, err := p.product()
+		if err != nil {
+			return calcTerm{}, err
+		}
+		if op == '-' {
+			right.length = right.length.scale(-1)
+		}
+		left = calcTerm{length: left.length.add(right.length), scalar: left.scalar && right.scalar}
+	}
+}
+
+// product parses operands joined by * and /. One side of a multiplication and the
+// right side of a division must be a plain number.
+func (p *calcParser) product() (calcTerm, error) {
+	left, err := p.operand()
+	if err != nil {
+		return calcTerm{}, err
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.text) || (p.text[p.pos] != '*' && p.text[p.pos] != '/') {
+			return left, nil
+		}
+		op := p.text[p.pos]
+		p.pos++
+		right, err := p.operand()
+		if err != nil {
+			return calcTerm{}, err
+		}
+
+		switch {
+		case op == '/':
+			if !right.scalar || right.length.Pixels == 0 {
+				return calcTerm{}, p.error("Can only divide by a non-zero number")
+			}
+			left.length = left.length.scale(1 / right.length.Pixels)
+		case left.scalar:
+			left = calcTerm{length: right.length.scale(left.length.Pixels), scalar: right.scalar}
+		case right.scalar:
+			left.length = left.length.scale(right.length.Pixels)
+		default:
+			return calcTerm{}, p.error("Cannot multiply two lengths")
+		}
+	}
+}
+
+// operand parses a number with an optional unit or a parenthesised expression
+func (p *calcParser) operand() (calcTerm, error) {
+	p.skipSpace()
+	rest := p.text[p.pos:]
+	for _, open := range []string{"(", "calc("} {
+		if strings.HasPrefix(rest, open) {
+			p.pos += len(open)
+			term, err := p.sum()
+			if err != nil {
+				return calcTerm{}, err
+			}
+			p.skipSpace()
+			if p.pos >= len(p.text) || p.text[p.pos] != ')' {
+				return calcTerm{}, p.error("Missing )")
+			}
+			p.pos++
+			return term, nil
+		}
+	}
+
+	start := p.pos
+	if p.pos < len(p.text) && (p.text[p.pos] == '+' || p.text[p.pos] == '-') {
+		p.pos++
+	}
+	for p.pos < len(p.text) && (p.text[p.pos] == '.' || (p.text[p.pos] >= '0' && p.text[p.pos] <= '9')) {
+		p.pos++
+	}
+	number, err := strconv.ParseFloat(p.text[start:p.pos], 64)
+	if err != nil {
+		return calcTerm{}, p.error("Expected a number at " + strconv.Quote(rest))
+	}
+
+	unitStart := p.pos
+	for p.pos < len(p.text) && (p.text[p.pos] == '%' || (p.text[p.pos] >= 'a' && p.text[p.pos] <= 'z')) {
+		p.pos++
+	}
+	switch p.text[unitStart:p.pos] {
+	case "":
+		return calcTerm{length: calcLength{Pixels: number}, scalar: true}, nil
+	case "px":
+		return calcTerm{length: calcLength{Pixels: number}}, nil
+	case "%":
+		return calcTerm{length: calcLength{Percent: number}}, nil
+	case "em":
+		return calcTerm{length: calcLength{Em: number}}, nil
+	case "rem":
+		return calcTerm{length: calcLength{Rem: number}}, nil
+	}
+	return calcTerm{}, p.error("Unknown unit " + strconv.Quote(p.text[unitStart:p.pos]))
+}
+
+func (p *calcParser) skipSpace() {
+	for p.pos < len(p.text) && p.text[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *calcParser) error(message string) error {
+	return errors.New(message)
+}
+
+func (c calcLength) add(other calcLength) calcLength {
+	return calcLength{
+		Pixels:  c.Pixels + other.Pixels,
+		Percent: c.Percent + other.Percent,
+		Em:      c.Em + other.Em,
+		Rem:     c.Rem + other.Rem,
+	}
+}
+
+func (c calcLength) scale(factor float64) calcLength {
+	return calcLength{
+		Pixels:  c.Pixels * factor,
+		Percent: c.Percent * factor,
+		Em:      c.Em * factor,
+		Rem:     c.Rem * factor,
+	}
+}
+
+// resolve returns the length in pixels, with percentages of percentBasis
+func (c calcLength) resolve(fontSize, rootFontSize, percentBasis float64) float64 {
+	return c.Pixels + c.Em*fontSize + c.Rem*rootFontSize + c.Percent*percentBasis/100
+}
+
+// computed folds the em and rem parts into pixels, leaving a pixel length
+// or a calc() that still has a percentage for the layout to resolve
+func (c calcLength) computed(fontSize, rootFontSize float64, source styleSource) styleValue {
+	pixels := c.Pixels + c.Em*fontSize + c.Rem*rootFontSize
+	if c.Percent == 0 {
+		return styleValue{Type: pixel, Value: pixels, Source: source}
+	}
+	return styleValue{Type: calc, Value: calcLength{Pixels: pixels, Percent: c.Percent}, Source: source}
+}
+
+// isPercentageBased returns true if a length depends on the size of its containing block
+func isPercentageBased(value styleValue) bool {
+	if c, ok := value.Value.(calcLength); ok {
+		return c.Percent != 0
+	}
+	return value.Type == percentage
+}
+
+// splitLengths splits space separated lengths, keeping the spaces inside calc() together
+func splitLengths(value string) []string {
+	var tokens []string
+	depth, start := 0, -1
+	for i, r := range value {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ' ' || r == '\t' || r == '\n':
+			if depth == 0 {
+				if start >= 0 {
+					tokens = append(tokens, value[start:i])
+				}
+				start = -1
+				continue
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, value[start:])
+	}
+	return tokens
+}
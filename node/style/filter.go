@@ -0,0 +1,63 @@
+package style
+
+import "math"
+
+// filter is a single filter function applied to a node and its subtree,
+// like an entry of the CSS filter property
+type filter struct {
+	// Function is one of "blur", "brightness", "contrast", "grayscale" or "drop-shadow"
+	Function string
+	// Amount is the blur radius in pixels, or the factor of the color functions
+	Amount float64
+	// Shadow holds the offset, blur radius and color of a drop-shadow
+	Shadow shadowStyle
+}
+
+// Blend modes supported by the mixBlendMode property
+var blendModes = map[string]bool{
+	"normal":       true,
+	"multiply":     true,
+	"screen":       true,
+	"overlay":      true,
+	"darken":       true,
+	"lighten":      true,
+	"color-dodge":  true,
+	"color-burn":   true,
+	"hard-light":   true,
+	"soft-light":   true,
+	"difference":   true,
+	"exclusion":    true,
+	"plus-lighter": true,
+}
+
+// isBlendMode returns true if mode is a supported mixBlendMode value
+func isBlendMode(mode string) bool {
+	return blendModes[mode]
+}
+
+// filterOutset returns how far the filters can draw outside the node's bounds
+func filterOutset(filters []filter) edgeInsets {
+	var outset edgeInsets
+	for _, f := range filters {
+		switch f.Function {
+		case "blur":
+			// A gaussian blur is visually gone after three standard deviations
+			spread := 3 * f.Amount
+			outset = edgeInsets{
+				Top:    outset.Top + spread,
+				Right:  outset.Right + spread,
+				Bottom: outset.Bottom + spread,
+				Left:   outset.Left + spread,
+			}
+		case "drop-shadow":
+			spread := 1.5*f.Shadow.BlurRadius + f.Shadow.SpreadRadius
+			outset = edgeInsets{
+				Top:    outset.Top + math.Max(0, spread-f.Shadow.OffsetY),
+				Right:  outset.Right + math.Max(0, spread+f.Shadow.OffsetX),
+				Bottom: outset.Bottom + math.Max(0, spread+f.Shadow.OffsetY),
+				Left:   outset.Left + math.Max(0, spread-f.Shadow.OffsetX),
+			}
+		}
+	}
+	return outset
+}
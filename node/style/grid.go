@@ -0,0 +1,198 @@
+package style
+
+import (
+	"strconv"
+	"strings"
+)
+
+// gridTrack is a column or row of a grid template. Its size lies between
+// Min and Max, which are pixel, percentage, fraction or auto values.
+type gridTrack struct {
+	Min styleValue
+	Max styleValue
+}
+
+// gridPlacement is where an item sits on one axis of a grid
+type gridPlacement struct {
+	// Start is the 1-based line the item starts at, or 0 to place it automatically
+	Start int
+	// Span is the number of tracks the item covers
+	Span int
+}
+
+// parseGridTemplate parses a track list such as "200px 1fr repeat(2, minmax(100px, 1fr)) auto"
+func parseGridTemplate(template string) ([]gridTrack, error) {
+	var tracks []gridTrack
+	for _, token := range splitGridTokens(template) {
+		if strings.HasPrefix(token, "repeat(") && strings.HasSuffix(token, ")") {
+			args := strings.SplitN(token[len("repeat("):len(token)-1], ",", 2)
+			if len(args) != 2 {
+				return nil, gridError(template, "repeat() needs a count and a track list")
+			}
+			count, err := strconv.Atoi(strings.TrimSpace(args[0]))
+			if err != nil || count < 1 {
+				return nil, gridError(template, "repeat() count must be a positive integer")
+			}
+			repeated, err := parseGridTemplate(args[1])
+			if err != nil {
+				return nil, err
+			}
+			for i := 0; i < count; i++ {
+				tracks = append(tracks, repeated...)
+			}
+			continue
+		}
+
+		track, err := parseGridTrack(token)
+		if err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, track)
+	}
+	return tracks, nil
+}
+
+// parseGridTrack parses a single track size, which may be a minmax() pair
+func parseGridTrack(token string) (gridTrack, error) {
+	if strings.HasPrefix(token, "minmax(") && strings.HasSuffix(token, ")") {
+		args := strings.Split(token[len("minmax("):len(token)-1], ",")
+		if len(args) != 2 {
+			return gridTrack{}, gridError(token, "minmax() needs a minimum and a maximum")
+		}
+		min, err := parseTrackSize(strings.TrimSpace(args[0]))
+		if err != nil {
+			return gridTrack{}, err
+		}
+		max, err := parseTrackSize(strings.TrimSpace(args[1]))
+		if err != nil {
+			return gridTrack{}, err
+		}
+		// Fractions only distribute leftover space, so they cannot be a minimum
+		if min.Type == fraction {
+			return gridTrack{}, gridError(token, "minmax() minimum cannot be a fraction")
+		}
+		return gridTrack{Min: min, Max: max}, nil
+	}
+
+	size, err := parseTrackSize(token)
+	if err != nil {
+		return gridTrack{}, err
+	}
+	// A bare fraction is minmax(auto, fr) so tracks never shrink below their content
+	if size.Type == fraction {
+		return gridTrack{Min: styleValue{Type: auto, Value: 0.0}, Max: size}, nil
+	}
+	return gridTrack{Min: size, Max: size}, nil
+}
+
+// parseTrackSize parses a track size of the form 100, 100px, 50%, 1fr or auto
+func parseTrackSize(token string) (styleValue, error) {
+	valueType := pixel
+	number := token
+	switch {
+	case token == "auto":
+		return styleValue{Type: auto, Value: 0.0}, nil
+	case strings.HasSuffix(token, "fr"):
+		valueType = fraction
+		number = strings.TrimSuffix(token, "fr")
+	case strings.HasSuffix(token, "%"):
+		valueType = percentage
+		number = strings.TrimSuffix(token, "%")
+	case strings.HasSuffix(token, "px"):
+		number = strings.TrimSuffix(token, "px")
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value < 0 {
+		return styleValue{}, gridError(token, "invalid track size")
+	}
+	return styleValue{Type: valueType, Value: value}, nil
+}
+
+// splitGridTokens splits a track list on spaces outside of parentheses
+func splitGridTokens(template string) []string {
+	var tokens []string
+	depth, start := 0, 0
+	flush := func(end int) {
+		if token := strings.TrimSpace(template[start:end]); token != "" {
+			tokens = append(tokens, token)
+		}
+		start = end + 1
+	}
+	for i, r := range template {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ' ' && depth == 0:
+			flush(i)
+		}
+	}
+	flush(len(template))
+	return tokens
+}
+
+// parseGridPlacement parses a placement such as "2", "1 / 3", "span 2", "2 / span 3" or "auto"
+func parseGridPlacement(placement string) (gridPlacement, error) {
+	parts := strings.Split(placement, "/")
+	if len(parts) > 2 {
+		return gridPlacement{}, gridError(placement, "placement has more than one '/'")
+	}
+
+	result := gridPlacement{Span: 1}
+	start, startSpan, err := parseGridLine(parts[0])
+	if err != nil {
+		return gridPlacement{}, err
+	}
+	if startSpan > 0 {
+		result.Span = startSpan
+	} else {
+		result.Start = start
+	}
+
+	if len(parts) == 2 {
+		end, endSpan, err := parseGridLine(parts[1])
+		if err != nil {
+			return gridPlacement{}, err
+		}
+		switch {
+		case endSpan > 0:
+			result.Span = endSpan
+		case end > 0 && result.Start > 0 && end > result.Start:
+			result.Span = end - result.Start
+		case end > 0 && result.Start == 0:
+			// "span 2 / 4" ends at line 4
+			result.Start = end - result.Span
+			if result.Start < 1 {
+				result.Start = 1
+			}
+		}
+	}
+	return result, nil
+}
+
+// parseGridLine parses one side of a placement, returning either a line number or a span
+func parseGridLine(token string) (line, span int, err error) {
+	token = strings.TrimSpace(token)
+	if token == "auto" || token == "" {
+		return 0, 0, nil
+	}
+	if strings.HasPrefix(token, "span") {
+		span, err = strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(token, "span")))
+		if err != nil || span < 1 {
+			return 0, 0, gridError(token, "span must be a positive integer")
+		}
+		return 0, span, nil
+	}
+	line, err = strconv.Atoi(token)
+	if err != nil || line < 1 {
+		return 0, 0, gridError(token, "line must be a positive integer")
+	}
+	return line, 0, nil
+}
+
+// gridError reports an invalid grid value
+func gridError(value string, message string) error {
+	return styleError{Property: "grid", Value: value, Message: message}
+}
@@ -1,63 +1,107 @@
-package style
-
-// size represents width and height dimensions
-type size struct {
-	Width, Height float64
-}
-
-// point represents x and y coordinates
-type point struct {
-	X, Y float64
-}
-
-// rect represents a rectangle with position and size
-type rect struct {
-	Position point
-	Size     size
-}
-
-// Intersects checks if this rectangle intersects with another rectangle
-func (r *rect) Intersects(other rect) bool {
-	aMaxX := r.Position.X + r.Size.Width
-	aMaxY := r.Position.Y + r.Size.Height
-	bMaxX := other.Position.X + other.Size.Width
-	bMaxY := other.Position.Y + other.Size.Height
-
-	// a is left of b
-	if aMaxX < other.Position.X {
-		return false
-	}
-
-	// a is right of b
-	if r.Position.X > bMaxX {
-		return false
-	}
-
-	// a is above b
-	if aMaxY < other.Position.Y {
-		return false
-	}
-
-	// a is below b
-	if r.Position.Y > bMaxY {
-		return false
-	}
-
-	// The two overlap
-	return true
-}
-
-// NewSize creates a new size with the given dimensions
-func NewSize(width, height float64) size {
-	return size{Width: width, Height: height}
-}
-
-// NewPoint creates a new point with the given coordinates
-func NewPoint(x, y float64) point {
-	return point{X: x, Y: y}
-}
-
-// NewRect creates a new rect with the given position and size
-func NewRect(position point, size size) rect {
-	return rect{Position: position, Size: size}
-}
+package style
+
+import "math"
+
+// size represents width and height dimensions
+type size struct {
+	Width, Height float64
+}
+
+// point represents x and y coordinates
+type point struct {
+	X, Y float64
+}
+
+// rect represents a rectangle with position and size
+type rect struct {
+	Position point
+	Size     size
+}
+
+// Intersects checks if this rectangle intersects with another rectangle
+func (r *rect) Intersects(other rect) bool {
+	aMaxX := r.Position.X + r.Size.Width
+	aMaxY := r.Position.Y + r.Size.Height
+	bMaxX := other.Position.X + other.Size.Width
+	bMaxY := other.Position.Y + other.Size.Height
+
+	// a is left of b
+	if aMaxX < other.Position.X {
+		return false
+	}
+
+	// a is right of b
+	if r.Position.X > bMaxX {
+		return false
+	}
+
+	// a is above b
+	if aMaxY < other.Position.Y {
+		return false
+	}
+
+	// a is below b
+	if r.Position.Y > bMaxY {
+		return false
+	}
+
+	// The two overlap
+	return true
+}
+
+// IsEmpty returns true if the rectangle has no area
+func (r *rect) IsEmpty() bool {
+	return r.Size.Width <= 0 || r.Size.Height <= 0
+}
+
+// Union returns the smallest rectangle containing both rectangles.
+// Empty rectangles do not contribute to the result.
+func (r *rect) Union(other rect) rect {
+	if r.IsEmpty() {
+		return other
+	}
+	if other.IsEmpty() {
+		return *r
+	}
+
+	minX := math.Min(r.Position.X, other.Position.X)
+	minY := math.Min(r.Position.Y, other.Position.Y)
+	maxX := math.Max(r.Position.X+r.Size.Width, other.Position.X+other.Size.Width)
+	maxY := math.Max(r.Position.Y+r.Size.Height, other.Position.Y+other.Size.Height)
+
+	return rect{
+		Position: point{X: minX, Y: minY},
+		Size:     size{Width: maxX - minX, Height: maxY - minY},
+	}
+}
+
+// Intersection returns the overlapping area of both rectangles, which is empty if they do not overlap
+func (r *rect) Intersection(other rect) rect {
+	minX := math.Max(r.Position.X, other.Position.X)
+	minY := math.Max(r.Position.Y, other.Position.Y)
+	maxX := math.Min(r.Position.X+r.Size.Width, other.Position.X+other.Size.Width)
+	maxY := math.Min(r.Position.Y+r.Size.Height, other.Position.Y+other.Size.Height)
+
+	if maxX <= minX || maxY <= minY {
+		return rect{Position: point{X: minX, Y: minY}}
+	}
+	return rect{
+		Position: point{X: minX, Y: minY},
+		Size:     size{Width: maxX - minX, Height: maxY - minY},
+	}
+}
+
+// NewSize creates a new size with the given dimensions
+func NewSize(width, height float64) size {
+	return size{Width: width, Height: height}
+}
+
+// NewPoint creates a new point with the given coordinates
+func NewPoint(x, y float64) point {
+	return point{X: x, Y: y}
+}
+
+// NewRect creates a new rect with the given position and size
+func NewRect(position point, size size) rect {
+	return rect{Position: position, Size: size}
+}
@@ -1,217 +1,327 @@
-package style
-
-import (
-	"fmt"
-	"strconv"
-	"strings"
-)
-
-// styles holds all styling information for a node
-type styles struct {
-	// Core style properties
-	properties map[string]styleValue
-
-	// State-based style variations
-	stateStyles map[string]*styles
-
-	// Track which properties were explicitly set
-	setProperties map[string]styleSource
-
-	// Final computed opacity
-	finalOpacity float64
-
-	// Store original values for state-based style changes
-	originalValues map[string]interface{}
-}
-
-// newStyles creates a new styles instance with the given properties
-func newStyles(props map[string]interface{}) styles {
-	s := styles{
-		properties:     make(map[string]styleValue),
-		stateStyles:    make(map[string]*styles),
-		setProperties:  make(map[string]styleSource),
-		originalValues: make(map[string]interface{}),
-		finalOpacity:   1.0,
-	}
-
-	// Set default values
-	for key, value := range defaultStyleValues {
-		if err := s.set(key, value); err != nil {
-			fmt.Printf("[STYLE ERROR] Error setting default style %s: %v\n", key, err)
-		}
-	}
-
-	// Apply any custom properties
-	for key, value := range props {
-		if err := s.set(key, value); err != nil {
-			fmt.Printf("[STYLE ERROR] Error setting style %s: %v\n", key, err)
-		}
-	}
-
-	return s
-}
-
-// set sets a style property value
-func (s *styles) set(key string, value interface{}) error {
-	// Convert value to styleValue if needed
-	var sv styleValue
-
-	switch v := value.(type) {
-	case styleValue:
-		sv = v
-	case int:
-		sv = styleValue{Type: pixel, Value: float64(v), Source: explicit}
-	case float64:
-		sv = styleValue{Type: pixel, Value: v, Source: explicit}
-	case string:
-		if v == "auto" {
-			sv = styleValue{Type: auto, Value: 0, Source: explicit}
-		} else if strings.HasSuffix(v, "%") {
-			pctVal, err := strconv.ParseFloat(v[:len(v)-1], 64)
-			if err != nil {
-				return styleError{Property: key, Value: v, Message: "Invalid percentage value"}
-			}
-			sv = styleValue{Type: percentage, Value: pctVal, Source: explicit}
-		} else if strings.HasSuffix(v, "em") {
-			emVal, err := strconv.ParseFloat(v[:len(v)-2], 64)
-			if err != nil {
-				return styleError{Property: key, Value: v, Message: "Invalid em value"}
-			}
-			sv = styleValue{Type: em, Value: emVal, Source: explicit}
-		} else if strings.HasSuffix(v, "rem") {
-			remVal, err := strconv.ParseFloat(v[:len(v)-3], 64)
-			if err != nil {
-				return styleError{Property: key, Value: v, Message: "Invalid rem value"}
-			}
-			sv = styleValue{Type: rem, Value: remVal, Source: explicit}
-		} else {
-			// For numeric properties (width, height, etc.), require explicit numeric values
-			if isNumericProperty(key) {
-				if num, err := strconv.ParseFloat(v, 64); err == nil {
-					sv = styleValue{Type: pixel, Value: num, Source: explicit}
-				} else {
-					return styleError{
-						Property: key,
-						Value:    v,
-						Message:  "Numeric properties require explicit numeric values (e.g., 100 instead of \"100\")",
-					}
-				}
-			} else {
-				// For non-numeric properties, try to parse as number first
-				if num, err := strconv.ParseFloat(v, 64); err == nil {
-					sv = styleValue{Type: pixel, Value: num, Source: explicit}
-				} else {
-					// Treat as a string value
-					sv = styleValue{Type: pixel, Value: v, Source: explicit}
-				}
-			}
-		}
-	default:
-		// For other types (color, edgeInsets, etc.), store as is
-		sv = styleValue{Type: pixel, Value: v, Source: explicit}
-	}
-
-	s.properties[key] = sv
-	s.setProperties[key] = explicit
-	return nil
-}
-
-// get gets a style property value
-func (s *styles) get(key string) (interface{}, bool) {
-	if value, ok := s.properties[key]; ok {
-		return value.Value, true
-	}
-	return nil, false
-}
-
-// getFloat gets a style property as a float64
-func (s *styles) getFloat(key string) (float64, bool) {
-	if value, ok := s.properties[key]; ok {
-		switch v := value.Value.(type) {
-		case float64:
-			return v, true
-		case int:
-			return float64(v), true
-		case styleValue:
-			if f, ok := v.Value.(float64); ok {
-				return f, true
-			}
-		}
-	}
-	return 0, false
-}
-
-// getString gets a style property as a string
-func (s *styles) getString(key string) (string, bool) {
-	if value, ok := s.properties[key]; ok {
-		switch v := value.Value.(type) {
-		case string:
-			return v, true
-		case styleValue:
-			if str, ok := v.Value.(string); ok {
-				return str, true
-			}
-		}
-	}
-	return "", false
-}
-
-// getColor gets a style property as a color
-func (s *styles) getColor(key string) (color, bool) {
-	if value, ok := s.properties[key]; ok {
-		switch v := value.Value.(type) {
-		case color:
-			return v, true
-		case styleValue:
-			if c, ok := v.Value.(color); ok {
-				return c, true
-			}
-		}
-	}
-	return color{}, false
-}
-
-// getEdgeInsets gets a style property as edgeInsets
-func (s *styles) getEdgeInsets(key string) (edgeInsets, bool) {
-	if value, ok := s.properties[key]; ok {
-		switch v := value.Value.(type) {
-		case edgeInsets:
-			return v, true
-		case styleValue:
-			if e, ok := v.Value.(edgeInsets); ok {
-				return e, true
-			}
-		}
-	}
-	return edgeInsets{}, false
-}
-
-// addStateStyle adds a style variation for a specific state
-func (s *styles) addStateStyle(state string, style *styles) {
-	s.stateStyles[state] = style
-}
-
-// getStateStyle returns the style variation for a specific state
-func (s *styles) getStateStyle(state string) *styles {
-	return s.stateStyles[state]
-}
-
-// restoreOriginalStyles restores the original styles when a state is removed
-func (s *styles) restoreOriginalStyles() {
-	if s.originalValues == nil {
-		return
-	}
-
-	for key, value := range s.originalValues {
-		s.set(key, value)
-	}
-	s.originalValues = nil
-}
-
-// markPropertyExplicit marks a property as explicitly set
-func (s *styles) markPropertyExplicit(prop styleProperty) {
-	if s.setProperties == nil {
-		s.setProperties = make(map[string]styleSource)
-	}
-	s.setProperties[string(prop)] = explicit
-}
+package style
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// styles holds all styling information for a node
+type styles struct {
+	// Core style properties
+	properties map[string]styleValue
+
+	// State-based style variations
+	stateStyles map[string]*styles
+
+	// Track which properties were explicitly set
+	setProperties map[string]styleSource
+
+	// Final computed opacity
+	finalOpacity float64
+
+	// Store original values for state-based style changes
+	originalValues map[string]interface{}
+
+	// Pixel values of relative lengths, which take precedence over the specified values
+	computed map[string]styleValue
+
+	// Font size of the root node, which rem lengths are relative to
+	rootFontSize float64
+
+	// Size of the window the tree is laid out in, which breakpoints are evaluated against
+	viewport size
+}
+
+// newStyles creates a new styles instance with the given properties
+func newStyles(props map[string]interface{}) styles {
+	s := styles{
+		properties:     make(map[string]styleValue),
+		stateStyles:    make(map[string]*styles),
+		setProperties:  make(map[string]styleSource),
+		originalValues: make(map[string]interface{}),
+		finalOpacity:   1.0,
+	}
+
+	// Set default values
+	for key, value := range defaultStyleValues {
+		if err := s.set(key, value); err != nil {
+			fmt.Printf("[STYLE ERROR] Error setting default style %s: %v\n", key, err)
+		}
+	}
+
+	// Apply any custom properties
+	for key, value := range props {
+		if err := s.set(key, value); err != nil {
+			fmt.Printf("[STYLE ERROR] Error setting style %s: %v\n", key, err)
+		}
+	}
+
+	return s
+}
+
+// set sets a style property value
+func (s *styles) set(key string, value interface{}) error {
+	// Convert value to styleValue if needed
+	var sv styleValue
+
+	switch v := value.(type) {
+	case styleValue:
+		sv = v
+	case int:
+		sv = styleValue{Type: pixel, Value: float64(v), Source: explicit}
+	case float64:
+		sv = styleValue{Type: pixel, Value: v, Source: explicit}
+	case string:
+		if isKeywordProperty(key) {
+			sv = styleValue{Type: pixel, Value: v, Source: explicit}
+		} else if v == "auto" {
+			sv = styleValue{Type: auto, Value: 0, Source: explicit}
+		} else if strings.HasPrefix(v, "calc(") {
+			calcVal, err := parseCalc(v)
+			if err != nil {
+				return styleError{Property: key, Value: v, Message: "Invalid calc() expression"}
+			}
+			sv = calcVal
+		} else if strings.HasSuffix(v, "%") {
+			pctVal, err := strconv.ParseFloat(v[:len(v)-1], 64)
+			if err != nil {
+				return styleError{Property: key, Value: v, Message: "Invalid percentage value"}
+			}
+			sv = styleValue{Type: percentage, Value: pctVal, Source: explicit}
+		} else if strings.HasSuffix(v, "rem") {
+			remVal, err := strconv.ParseFloat(v[:len(v)-3], 64)
+			if err != nil {
+				return styleError{Property: key, Value: v, Message: "Invalid rem value"}
+			}
+			sv = styleValue{Type: rem, Value: remVal, Source: explicit}
+		} else if strings.HasSuffix(v, "em") {
+			emVal, err := strconv.ParseFloat(v[:len(v)-2], 64)
+			if err != nil {
+				return styleError{Property: key, Value: v, Message: "Invalid em value"}
+			}
+			sv = styleValue{Type: em, Value: emVal, Source: explicit}
+		} else if pxVal, err := strconv.ParseFloat(strings.TrimSuffix(v, "px"), 64); err == nil && strings.HasSuffix(v, "px") {
+			sv = styleValue{Type: pixel, Value: pxVal, Source: explicit}
+		} else {
+			// For numeric properties (width, height, etc.), require explicit numeric values
+			if isNumericProperty(key) {
+				if num, err := strconv.ParseFloat(v, 64); err == nil {
+					sv = styleValue{Type: pixel, Value: num, Source: explicit}
+				} else {
+					return styleError{
+						Property: key,
+						Value:    v,
+						Message:  "Numeric properties require explicit numeric values (e.g., 100 instead of \"100\")",
+					}
+				}
+			} else {
+				// For non-numeric properties, try to parse as number first
+				if num, err := strconv.ParseFloat(v, 64); err == nil {
+					sv = styleValue{Type: pixel, Value: num, Source: explicit}
+				} else {
+					// Treat as a string value
+					sv = styleValue{Type: pixel, Value: v, Source: explicit}
+				}
+			}
+		}
+	default:
+		// For other types (color, edgeInsets, etc.), store as is
+		sv = styleValue{Type: pixel, Value: v, Source: explicit}
+	}
+
+	s.properties[key] = sv
+	s.setProperties[key] = explicit
+
+	// A new value replaces what was computed from the old one until lengths are computed again
+	delete(s.computed, key)
+	return nil
+}
+
+// unset removes a property, as if it had never been set
+func (s *styles) unset(key string) {
+	delete(s.properties, key)
+	delete(s.setProperties, key)
+	delete(s.computed, key)
+}
+
+// lookup returns the computed value of a property if it has one, or else its specified value
+func (s *styles) lookup(key string) (styleValue, bool) {
+	if value, ok := s.computed[key]; ok {
+		return value, true
+	}
+	value, ok := s.properties[key]
+	return value, ok
+}
+
+// setViewport sets the size of the window, which descendants take from their parent
+func (s *styles) setViewport(viewport size) {
+	s.viewport = viewport
+}
+
+// specified returns the value a property was set to, before relative lengths are computed
+func (s *styles) specified(key string) (styleValue, bool) {
+	value, ok := s.properties[key]
+	if !ok {
+		return styleValue{}, false
+	}
+	if inner, ok := value.Value.(styleValue); ok {
+		return inner, true
+	}
+	return value, true
+}
+
+// get gets a style property value
+func (s *styles) get(key string) (interface{}, bool) {
+	if value, ok := s.lookup(key); ok {
+		return value.Value, true
+	}
+	return nil, false
+}
+
+// getFloat gets a style property as a float64
+func (s *styles) getFloat(key string) (float64, bool) {
+	if value, ok := s.lookup(key); ok {
+		switch v := value.Value.(type) {
+		case float64:
+			return v, true
+		case int:
+			return float64(v), true
+		case styleValue:
+			if f, ok := v.Value.(float64); ok {
+				return f, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// getString gets a style property as a string
+func (s *styles) getString(key string) (string, bool) {
+	if value, ok := s.lookup(key); ok {
+		switch v := value.Value.(type) {
+		case string:
+			return v, true
+		case styleValue:
+			if str, ok := v.Value.(string); ok {
+				return str, true
+			}
+		}
+	}
+	return "", false
+}
+
+// getColor gets a style property as a color
+func (s *styles) getColor(key string) (color, bool) {
+	if value, ok := s.lookup(key); ok {
+		switch v := value.Value.(type) {
+		case color:
+			return v, true
+		case styleValue:
+			if c, ok := v.Value.(color); ok {
+				return c, true
+			}
+		}
+	}
+	return color{}, false
+}
+
+// getEdgeInsets gets a style property as edgeInsets
+func (s *styles) getEdgeInsets(key string) (edgeInsets, bool) {
+	if value, ok := s.lookup(key); ok {
+		switch v := value.Value.(type) {
+		case edgeInsets:
+			return v, true
+		case styleValue:
+			if e, ok := v.Value.(edgeInsets); ok {
+				return e, true
+			}
+		}
+
+		// Relative lengths that have not been resolved by layout count percentages as 0
+		if lengths, ok := toEdgeLengths(value); ok {
+			return lengths.resolve(s.fontSize(), s.remSize(), 0), true
+		}
+	}
+	return edgeInsets{}, false
+}
+
+// isRTL returns true if the node lays out its content and text from right to left
+func (s *styles) isRTL() bool {
+	direction, _ := s.getString("direction")
+	return direction == "rtl"
+}
+
+// textAlign returns the side text is aligned to, "left", "right" or "center". Start and end
+// are the left and right, or the right and left in right-to-left text.
+func (s *styles) textAlign() string {
+	align, _ := s.getString("textAlign")
+	start, end := "left", "right"
+	if s.isRTL() {
+		start, end = end, start
+	}
+	switch align {
+	case "left", "right", "center":
+		return align
+	case "end":
+		return end
+	}
+	return start
+}
+
+// layoutValues returns the values of the properties that can change the layout, for telling
+// whether a restyle has to be laid out again. Only the widths of a border take up space.
+func (s *styles) layoutValues() map[string]interface{} {
+	values := make(map[string]interface{}, len(s.properties))
+	for key := range s.properties {
+		if !isLayoutProperty(key) {
+			continue
+		}
+		value, _ := s.lookup(key)
+		if border, ok := value.Value.(BorderStyle); ok {
+			values[key] = border.Width
+			continue
+		}
+		values[key] = value
+	}
+	return values
+}
+
+// getValue gets a style property along with its value type, so lengths
+// in pixels can be told apart from percentages and auto
+func (s *styles) getValue(key string) (styleValue, bool) {
+	if value, ok := s.computed[key]; ok {
+		return value, true
+	}
+	return s.specified(key)
+}
+
+// addStateStyle adds a style variation for a specific state
+func (s *styles) addStateStyle(state string, style *styles) {
+	s.stateStyles[state] = style
+}
+
+// getStateStyle returns the style variation for a specific state
+func (s *styles) getStateStyle(state string) *styles {
+	return s.stateStyles[state]
+}
+
+// restoreOriginalStyles restores the original styles when a state is removed
+func (s *styles) restoreOriginalStyles() {
+	if s.originalValues == nil {
+		return
+	}
+
+	for key, value := range s.originalValues {
+		s.set(key, value)
+	}
+	s.originalValues = nil
+}
+
+// markPropertyExplicit marks a property as explicitly set
+func (s *styles) markPropertyExplicit(prop styleProperty) {
+	if s.setProperties == nil {
+		s.setProperties = make(map[string]styleSource)
+	}
+	s.setProperties[string(prop)] = explicit
+}
@@ -1,196 +1,330 @@
-// Package style provides a comprehensive styling system for UI nodes.
-// It includes support for:
-// - Layout properties (width, height, margins, etc.)
-// - Visual styling (colors, borders, shadows, etc.)
-// - Typography (font family, size, weight, etc.)
-// - Flex layout (direction, alignment, etc.)
-// - State-based styling
-//
-// The package is organized into several files:
-// - types.go: Core type definitions and constants
-// - props.go: Style properties and default values
-// - manager.go: Style management and computation
-// - utils.go: Debugging and utility functions
-package style
-
-// Re-export commonly used types and functions
-type (
-	ValueType     = valueType
-	StyleSource   = styleSource
-	StyleProperty = styleProperty
-	StyleValue    = styleValue
-	Color         = color
-	EdgeInsets    = edgeInsets
-	ShadowStyle   = shadowStyle
-	StyleError    = styleError
-	Styles        = styles
-	StyleProps    = styleProps
-	Size          = size
-	Point         = point
-	Rect          = rect
-)
-
-// Re-export commonly used constants
-const (
-	// Value types
-	PIXEL      = pixel
-	PERCENTAGE = percentage
-	AUTO       = auto
-	EM         = em
-	REM        = rem
-
-	// Style sources
-	Unset     = unset
-	Default   = default_
-	Inherited = inherited
-	Explicit  = explicit
-
-	// Layout properties
-	WidthProp     = widthProp
-	HeightProp    = heightProp
-	MinWidthProp  = minWidthProp
-	MaxWidthProp  = maxWidthProp
-	MinHeightProp = minHeightProp
-	MaxHeightProp = maxHeightProp
-
-	// Spacing
-	MarginProp  = marginProp
-	PaddingProp = paddingProp
-
-	// Positioning
-	PositionProp = positionProp
-	TopProp      = topProp
-	RightProp    = rightProp
-	BottomProp   = bottomProp
-	LeftProp     = leftProp
-	ZIndexProp   = zIndexProp
-
-	// Flex Layout
-	FlexDirectionProp  = flexDirectionProp
-	JustifyContentProp = justifyContentProp
-	AlignItemsProp     = alignItemsProp
-	FlexWrapProp       = flexWrapProp
-
-	// Typography
-	FontFamilyProp = fontFamilyProp
-	FontSizeProp   = fontSizeProp
-	FontWeightProp = fontWeightProp
-	LineHeightProp = lineHeightProp
-	TextAlignProp  = textAlignProp
-	ColorProp      = colorProp
-
-	// Visual styling
-	BackgroundProp   = backgroundProp
-	BorderProp       = borderProp
-	BorderRadiusProp = borderRadiusProp
-	ShadowProp       = shadowProp
-	OpacityProp      = opacityProp
-	ScaleProp        = scaleProp
-)
-
-// Re-export commonly used variables
-var (
-	// Basic colors
-	Black       = black
-	White       = white
-	Red         = red
-	Green       = green
-	Blue        = blue
-	Yellow      = yellow
-	Cyan        = cyan
-	Magenta     = magenta
-	Gray        = gray
-	Transparent = transparent
-
-	// Extended colors
-	LightGray   = lightGray
-	DarkGray    = darkGray
-	Silver      = silver
-	DarkRed     = darkRed
-	Navy        = navy
-	ForestGreen = forestGreen
-	Orange      = orange
-	Purple      = purple
-)
-
-// Re-export commonly used functions
-var (
-	NewStyles = newStyles
-)
-
-// Re-export methods
-func (s *Styles) Set(key string, value interface{}) error {
-	return s.set(key, value)
-}
-
-func (s *Styles) Get(key string) (interface{}, bool) {
-	return s.get(key)
-}
-
-func (s *Styles) GetFloat(key string) (float64, bool) {
-	return s.getFloat(key)
-}
-
-func (s *Styles) GetString(key string) (string, bool) {
-	return s.getString(key)
-}
-
-func (s *Styles) GetColor(key string) (Color, bool) {
-	return s.getColor(key)
-}
-
-func (s *Styles) GetEdgeInsets(key string) (EdgeInsets, bool) {
-	return s.getEdgeInsets(key)
-}
-
-func (s *Styles) AddStateStyle(state string, style *Styles) {
-	s.addStateStyle(state, style)
-}
-
-func (s *Styles) GetStateStyle(state string) *Styles {
-	return s.getStateStyle(state)
-}
-
-func (s *Styles) RestoreOriginalStyles() {
-	s.restoreOriginalStyles()
-}
-
-func (s *Styles) MarkPropertyExplicit(prop StyleProperty) {
-	s.markPropertyExplicit(prop)
-}
-
-func (s *Styles) DumpStyles() {
-	s.dumpStyles()
-}
-
-// IsExplicit returns true if the property was explicitly set
-func (s *Styles) IsExplicit(key string) bool {
-	if s == nil {
-		return false
-	}
-	if s.setProperties == nil {
-		return false
-	}
-	source, ok := s.setProperties[key]
-	return ok && source == Explicit
-}
-
-// GetFinalOpacity returns the final computed opacity
-func (s *Styles) GetFinalOpacity() float64 {
-	return s.finalOpacity
-}
-
-// SetFinalOpacity sets the final computed opacity
-func (s *Styles) SetFinalOpacity(opacity float64) {
-	s.finalOpacity = opacity
-}
-
-// StoreOriginalValue stores the original value for a property (for state style changes)
-func (s *Styles) StoreOriginalValue(key string, value interface{}) {
-	if s.originalValues == nil {
-		s.originalValues = make(map[string]interface{})
-	}
-	if _, exists := s.originalValues[key]; !exists {
-		s.originalValues[key] = value
-	}
-}
+// Package style provides a comprehensive styling system for UI nodes.
+// It includes support for:
+// - Layout properties (width, height, margins, etc.)
+// - Visual styling (colors, borders, shadows, etc.)
+// - Typography (font family, size, weight, etc.)
+// - Flex layout (direction, alignment, etc.)
+// - State-based styling
+//
+// The package is organized into several files:
+// - types.go: Core type definitions and constants
+// - props.go: Style properties and default values
+// - manager.go: Style management and computation
+// - utils.go: Debugging and utility functions
+// - filter.go: Filter functions and blend modes for layer effects
+// - grid.go: Grid track lists and item placement
+// - units.go: Relative lengths and their computed values
+// - calc.go: calc() expressions
+package style
+
+// Re-export commonly used types and functions
+type (
+	ValueType     = valueType
+	StyleSource   = styleSource
+	StyleProperty = styleProperty
+	StyleValue    = styleValue
+	Color         = color
+	EdgeInsets    = edgeInsets
+	EdgeLengths   = edgeLengths
+	ShadowStyle   = shadowStyle
+	StyleError    = styleError
+	Styles        = styles
+	StyleProps    = styleProps
+	Filter        = filter
+	GridTrack     = gridTrack
+	GridPlacement = gridPlacement
+	Size          = size
+	Point         = point
+	Rect          = rect
+)
+
+// Re-export commonly used constants
+const (
+	// Value types
+	PIXEL      = pixel
+	PERCENTAGE = percentage
+	AUTO       = auto
+	EM         = em
+	REM        = rem
+	FRACTION   = fraction
+	CALC       = calc
+
+	// Style sources
+	Unset     = unset
+	Default   = default_
+	Inherited = inherited
+	Explicit  = explicit
+
+	// Layout properties
+	WidthProp       = widthProp
+	HeightProp      = heightProp
+	MinWidthProp    = minWidthProp
+	MaxWidthProp    = maxWidthProp
+	MinHeightProp   = minHeightProp
+	MaxHeightProp   = maxHeightProp
+	AspectRatioProp = aspectRatioProp
+
+	// Spacing
+	MarginProp  = marginProp
+	PaddingProp = paddingProp
+
+	// Positioning
+	PositionProp = positionProp
+	TopProp      = topProp
+	RightProp    = rightProp
+	BottomProp   = bottomProp
+	LeftProp     = leftProp
+	ZIndexProp   = zIndexProp
+
+	// Flex Layout
+	FlexDirectionProp  = flexDirectionProp
+	JustifyContentProp = justifyContentProp
+	AlignItemsProp     = alignItemsProp
+	FlexWrapProp       = flexWrapProp
+	FlexGrowProp       = flexGrowProp
+	FlexShrinkProp     = flexShrinkProp
+	FlexBasisProp      = flexBasisProp
+	AlignContentProp   = alignContentProp
+	RowGapProp         = rowGapProp
+	ColumnGapProp      = columnGapProp
+	AlignSelfProp      = alignSelfProp
+	OrderProp          = orderProp
+
+	// Grid Layout
+	DisplayProp             = displayProp
+	GridTemplateColumnsProp = gridTemplateColumnsProp
+	GridTemplateRowsProp    = gridTemplateRowsProp
+	GridColumnProp          = gridColumnProp
+	GridRowProp             = gridRowProp
+
+	// Typography
+	FontFamilyProp = fontFamilyProp
+	FontSizeProp   = fontSizeProp
+	FontWeightProp = fontWeightProp
+	LineHeightProp = lineHeightProp
+	TextAlignProp  = textAlignProp
+	DirectionProp  = directionProp
+	ColorProp      = colorProp
+
+	// Visual styling
+	BackgroundProp   = backgroundProp
+	BorderProp       = borderProp
+	BorderRadiusProp = borderRadiusProp
+	ShadowProp       = shadowProp
+	OpacityProp      = opacityProp
+	ScaleProp        = scaleProp
+	FilterProp       = filterProp
+	MixBlendModeProp = mixBlendModeProp
+
+	// Rendering
+	LayerProp = layerProp
+
+	// Scrolling
+	OverflowProp            = overflowProp
+	ScrollbarWidthProp      = scrollbarWidthProp
+	ScrollbarColorProp      = scrollbarColorProp
+	ScrollbarTrackColorProp = scrollbarTrackColorProp
+
+	// Table layout
+	BorderCollapseProp = borderCollapseProp
+	ColumnAlignProp    = columnAlignProp
+	ColSpanProp        = colSpanProp
+	RowSpanProp        = rowSpanProp
+
+	// Stack layout
+	StackAlignProp   = stackAlignProp
+	StackOffsetXProp = stackOffsetXProp
+	StackOffsetYProp = stackOffsetYProp
+)
+
+// Re-export commonly used variables
+var (
+	// Basic colors
+	Black       = black
+	White       = white
+	Red         = red
+	Green       = green
+	Blue        = blue
+	Yellow      = yellow
+	Cyan        = cyan
+	Magenta     = magenta
+	Gray        = gray
+	Transparent = transparent
+
+	// Extended colors
+	LightGray   = lightGray
+	DarkGray    = darkGray
+	Silver      = silver
+	DarkRed     = darkRed
+	Navy        = navy
+	ForestGreen = forestGreen
+	Orange      = orange
+	Purple      = purple
+)
+
+// Re-export commonly used functions
+var (
+	NewStyles          = newStyles
+	IsBlendMode        = isBlendMode
+	IsLayoutProperty   = isLayoutProperty
+	FilterOutset       = filterOutset
+	ParseGridTemplate  = parseGridTemplate
+	ParseGridPlacement = parseGridPlacement
+	ParseLength        = parseLength
+	ParseEdgeLengths   = parseEdgeLengths
+	ParseCalc          = parseCalc
+	IsPercentageBased  = isPercentageBased
+	ParseAspectRatio   = parseAspectRatio
+)
+
+// Re-export methods
+func (s *Styles) Set(key string, value interface{}) error {
+	return s.set(key, value)
+}
+
+// Unset removes a property, as if it had never been set
+func (s *Styles) Unset(key string) {
+	s.unset(key)
+}
+
+func (s *Styles) Get(key string) (interface{}, bool) {
+	return s.get(key)
+}
+
+func (s *Styles) GetFloat(key string) (float64, bool) {
+	return s.getFloat(key)
+}
+
+func (s *Styles) GetString(key string) (string, bool) {
+	return s.getString(key)
+}
+
+func (s *Styles) GetColor(key string) (Color, bool) {
+	return s.getColor(key)
+}
+
+func (s *Styles) GetEdgeInsets(key string) (EdgeInsets, bool) {
+	return s.getEdgeInsets(key)
+}
+
+// GetValue returns a property with its value type, e.g. to resolve percentage lengths
+func (s *Styles) GetValue(key string) (StyleValue, bool) {
+	return s.getValue(key)
+}
+
+// Specified returns the value a property was set to, before relative lengths are computed
+func (s *Styles) Specified(key string) (StyleValue, bool) {
+	return s.specified(key)
+}
+
+// SetViewport sets the size of the window, which the node's descendants take from it
+func (s *Styles) SetViewport(viewport Size) {
+	s.setViewport(viewport)
+}
+
+// Viewport returns the size of the window the node is laid out in
+func (s *Styles) Viewport() Size {
+	return s.viewport
+}
+
+// LayoutValues returns the values of the properties that can change the layout
+func (s *Styles) LayoutValues() map[string]interface{} {
+	return s.layoutValues()
+}
+
+// AutoEdges returns which sides of a padding or margin value are auto, e.g. "0 0 0 auto"
+func (s *Styles) AutoEdges(key string) (top, right, bottom, left bool) {
+	return s.autoEdges(key)
+}
+
+// WithEdge returns the sides of a padding or margin value with one of them replaced
+func (s *Styles) WithEdge(key, side string, length StyleValue) EdgeLengths {
+	return s.withEdge(key, side, length)
+}
+
+// IsRTL returns true if the node lays out its content and text from right to left
+func (s *Styles) IsRTL() bool {
+	return s.isRTL()
+}
+
+// TextAlign returns the side text is aligned to, with start and end resolved for the direction
+func (s *Styles) TextAlign() string {
+	return s.textAlign()
+}
+
+// ResolveLength converts a length to pixels, with percentages of percentBasis
+func (s *Styles) ResolveLength(key string, percentBasis float64) (float64, bool) {
+	return s.resolveLength(key, percentBasis)
+}
+
+// ComputeLengths converts em and rem lengths to pixels against the parent's computed styles
+func (s *Styles) ComputeLengths(parent *Styles) {
+	s.computeLengths(parent)
+}
+
+// RemSize returns the root font size that rem lengths are relative to
+func (s *Styles) RemSize() float64 {
+	return s.remSize()
+}
+
+// ResolvePercentages converts padding and margin percentages to pixels once the containing block's width is known
+func (s *Styles) ResolvePercentages(containingWidth float64) {
+	s.resolvePercentages(containingWidth)
+}
+
+// ResolveRadius converts border radius percentages to pixels once the box's size is known
+func (s *Styles) ResolveRadius(box Size) {
+	s.resolveRadius(box)
+}
+
+func (s *Styles) AddStateStyle(state string, style *Styles) {
+	s.addStateStyle(state, style)
+}
+
+func (s *Styles) GetStateStyle(state string) *Styles {
+	return s.getStateStyle(state)
+}
+
+func (s *Styles) RestoreOriginalStyles() {
+	s.restoreOriginalStyles()
+}
+
+func (s *Styles) MarkPropertyExplicit(prop StyleProperty) {
+	s.markPropertyExplicit(prop)
+}
+
+func (s *Styles) DumpStyles() {
+	s.dumpStyles()
+}
+
+// IsExplicit returns true if the property was explicitly set
+func (s *Styles) IsExplicit(key string) bool {
+	if s == nil {
+		return false
+	}
+	if s.setProperties == nil {
+		return false
+	}
+	source, ok := s.setProperties[key]
+	return ok && source == Explicit
+}
+
+// GetFinalOpacity returns the final computed opacity
+func (s *Styles) GetFinalOpacity() float64 {
+	return s.finalOpacity
+}
+
+// SetFinalOpacity sets the final computed opacity
+func (s *Styles) SetFinalOpacity(opacity float64) {
+	s.finalOpacity = opacity
+}
+
+// StoreOriginalValue stores the original value for a property (for state style changes)
+func (s *Styles) StoreOriginalValue(key string, value interface{}) {
+	if s.originalValues == nil {
+		s.originalValues = make(map[string]interface{})
+	}
+	if _, exists := s.originalValues[key]; !exists {
+		s.originalValues[key] = value
+	}
+}
@@ -0,0 +1,333 @@
+package style
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// defaultFontSize is the font size rem lengths are relative to until the root has one of its own
+const defaultFontSize = 16.0
+
+// lengthProperties hold a single length. Their em and rem values are computed before layout,
+// while their percentages depend on the layout and are resolved by it.
+var lengthProperties = []string{
+	"width", "height", "minWidth", "maxWidth", "minHeight", "maxHeight",
+	"flexBasis", "top", "right", "bottom", "left", "rowGap", "columnGap",
+}
+
+// edgeLengths holds a length for each side of a box, for padding, margins and
+// border radii given in relative units
+type edgeLengths struct {
+	Top    styleValue
+	Right  styleValue
+	Bottom styleValue
+	Left   styleValue
+}
+
+// parseLength parses a length of the form 10, 10px, 50%, 1.5em, 2rem, calc(...) or auto
+func parseLength(token string) (styleValue, error) {
+	token = strings.TrimSpace(token)
+	valueType := pixel
+	number := token
+	switch {
+	case token == "auto":
+		return styleValue{Type: auto, Value: 0.0, Source: explicit}, nil
+	case strings.HasPrefix(token, "calc("):
+		return parseCalc(token)
+	case strings.HasSuffix(token, "%"):
+		valueType = percentage
+		number = strings.TrimSuffix(token, "%")
+	case strings.HasSuffix(token, "rem"):
+		valueType = rem
+		number = strings.TrimSuffix(token, "rem")
+	case strings.HasSuffix(token, "em"):
+		valueType = em
+		number = strings.TrimSuffix(token, "em")
+	case strings.HasSuffix(token, "px"):
+		number = strings.TrimSuffix(token, "px")
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return styleValue{}, styleError{Property: "length", Value: token, Message: "Invalid length value"}
+	}
+	return styleValue{Type: valueType, Value: value, Source: explicit}, nil
+}
+
+// parseEdgeLengths parses one to four lengths in CSS shorthand order: all sides,
+// vertical and horizontal, top, horizontal and bottom, or top, right, bottom and left
+func parseEdgeLengths(value string) (edgeLengths, error) {
+	tokens := splitLengths(value)
+	lengths := make([]styleValue, len(tokens))
+	for i, token := range tokens {
+		length, err := parseLength(token)
+		if err != nil {
+			return edgeLengths{}, err
+		}
+		lengths[i] = length
+	}
+
+	switch len(lengths) {
+	case 1:
+		return edgeLengths{lengths[0], lengths[0], lengths[0], lengths[0]}, nil
+	case 2:
+		return edgeLengths{lengths[0], lengths[1], lengths[0], lengths[1]}, nil
+	case 3:
+		return edgeLengths{lengths[0], lengths[1], lengths[2], lengths[1]}, nil
+	case 4:
+		return edgeLengths{lengths[0], lengths[1], lengths[2], lengths[3]}, nil
+	}
+	return edgeLengths{}, styleError{Property: "edges", Value: value, Message: "Expected one to four lengths"}
+}
+
+// toEdgeLengths returns the per side lengths of a padding, margin or border radius value
+func toEdgeLengths(value styleValue) (edgeLengths, bool) {
+	switch v := value.Value.(type) {
+	case edgeLengths:
+		return v, true
+	case calcLength:
+		return edgeLengths{value, value, value, value}, true
+	case edgeInsets:
+		return edgeLengths{
+			Top:    styleValue{Type: pixel, Value: v.Top},
+			Right:  styleValue{Type: pixel, Value: v.Right},
+			Bottom: styleValue{Type: pixel, Value: v.Bottom},
+			Left:   styleValue{Type: pixel, Value: v.Left},
+		}, true
+	case float64, int:
+		if value.Type == auto || value.Type == fraction {
+			return edgeLengths{}, false
+		}
+		return edgeLengths{value, value, value, value}, true
+	}
+	return edgeLengths{}, false
+}
+
+// isAbsolute returns true if every side is already in pixels
+func (e edgeLengths) isAbsolute() bool {
+	return e.Top.Type == pixel && e.Right.Type == pixel && e.Bottom.Type == pixel && e.Left.Type == pixel
+}
+
+// resolve converts the lengths to pixels, with percentages of percentBasis
+func (e edgeLengths) resolve(fontSize, rootFontSize, percentBasis float64) edgeInsets {
+	side := func(value styleValue) float64 {
+		length, _ := absoluteLength(value, fontSize, rootFontSize, percentBasis)
+		return length
+	}
+	return edgeInsets{
+		Top:    side(e.Top),
+		Right:  side(e.Right),
+		Bottom: side(e.Bottom),
+		Left:   side(e.Left),
+	}
+}
+
+// autoEdges returns which sides of a padding or margin value are auto, which count as 0
+// until the layout gives them the free space
+func (s *styles) autoEdges(key string) (top, right, bottom, left bool) {
+	lengths, _, _, ok := s.specifiedEdges(key)
+	if !ok {
+		return false, false, false, false
+	}
+	return lengths.Top.Type == auto, lengths.Right.Type == auto, lengths.Bottom.Type == auto, lengths.Left.Type == auto
+}
+
+// withEdge returns the sides of a padding or margin value with one of them, "top", "right",
+// "bottom" or "left", replaced by length
+func (s *styles) withEdge(key, side string, length styleValue) edgeLengths {
+	value, _ := s.specified(key)
+	lengths, _ := toEdgeLengths(value)
+	switch side {
+	case "top":
+		lengths.Top = length
+	case "right":
+		lengths.Right = length
+	case "bottom":
+		lengths.Bottom = length
+	case "left":
+		lengths.Left = length
+	}
+	return lengths
+}
+
+// logicalEdges are the properties setting the start and end sides of padding and margins
+// along a line, which are the left and right sides, or the right and left ones in
+// right-to-left content
+var logicalEdges = map[string][2]string{
+	"padding": {"paddingInlineStart", "paddingInlineEnd"},
+	"margin":  {"marginInlineStart", "marginInlineEnd"},
+}
+
+// specifiedEdges returns the sides a padding or margin value was set to, with the logical
+// sides set on the node in place of the physical ones they resolve to for its direction.
+// It also returns whether any logical side was set.
+func (s *styles) specifiedEdges(key string) (lengths edgeLengths, source styleSource, logical, ok bool) {
+	value, ok := s.specified(key)
+	if ok {
+		lengths, ok = toEdgeLengths(value)
+		source = value.Source
+	}
+	for i, prop := range logicalEdges[key] {
+		side, set := s.specified(prop)
+		if !set {
+			continue
+		}
+		if !ok {
+			lengths, _ = toEdgeLengths(styleValue{Type: pixel, Value: edgeInsets{}})
+			source, ok = side.Source, true
+		}
+		if start := i == 0; start != s.isRTL() {
+			lengths.Left = side
+		} else {
+			lengths.Right = side
+		}
+		logical = true
+	}
+	return lengths, source, logical, ok
+}
+
+// absoluteLength converts a length to pixels, with percentages of percentBasis.
+// It returns false for auto and values that are not lengths.
+func absoluteLength(value styleValue, fontSize, rootFontSize, percentBasis float64) (float64, bool) {
+	var number float64
+	switch v := value.Value.(type) {
+	case float64:
+		number = v
+	case int:
+		number = float64(v)
+	case calcLength:
+		return v.resolve(fontSize, rootFontSize, percentBasis), true
+	default:
+		return 0, false
+	}
+
+	switch value.Type {
+	case pixel:
+		return number, true
+	case em:
+		return number * fontSize, true
+	case rem:
+		return number * rootFontSize, true
+	case percentage:
+		return number * percentBasis / 100, true
+	}
+	return 0, false
+}
+
+// computeLengths works out the computed value of every em, rem and calc() length before
+// layout. A font size in em or percent is relative to the parent's font size, other em
+// lengths to the node's own font size, and rem lengths to the root's font size. Percentages
+// in padding, margins and border radii count as 0 until the layout resolves them.
+func (s *styles) computeLengths(parent *styles) {
+	rootFontSize := parent.rootFontSize
+	isRoot := rootFontSize == 0
+	if isRoot {
+		rootFontSize = defaultFontSize
+	}
+	parentFontSize, ok := parent.getFloat("fontSize")
+	if !ok {
+		parentFontSize = defaultFontSize
+	}
+
+	s.computed = make(map[string]styleValue)
+	if value, ok := s.specified("fontSize"); ok && value.Type != pixel && value.Type != auto {
+		if size, ok := absoluteLength(value, parentFontSize, rootFontSize, parentFontSize); ok {
+			s.computed["fontSize"] = styleValue{Type: pixel, Value: size, Source: value.Source}
+		}
+	}
+
+	// The root's font size is what rem lengths below it are relative to
+	if isRoot {
+		if size, ok := s.getFloat("fontSize"); ok {
+			rootFontSize = size
+		}
+	}
+	s.rootFontSize = rootFontSize
+	s.viewport = parent.viewport
+
+	fontSize := s.fontSize()
+	for _, key := range lengthProperties {
+		value, ok := s.specified(key)
+		if !ok || (value.Type != em && value.Type != rem && value.Type != calc) {
+			continue
+		}
+		if c, ok := value.Value.(calcLength); ok {
+			s.computed[key] = c.computed(fontSize, rootFontSize, value.Source)
+			continue
+		}
+		if length, ok := absoluteLength(value, fontSize, rootFontSize, 0); ok {
+			s.computed[key] = styleValue{Type: pixel, Value: length, Source: value.Source}
+		}
+	}
+
+	s.computeEdges([]string{"padding", "margin", "borderRadius"}, 0)
+}
+
+// resolvePercentages resolves padding and margin percentages against the width of the
+// containing block, as CSS does for both the horizontal and vertical sides
+func (s *styles) resolvePercentages(containingWidth float64) {
+	s.computeEdges([]string{"padding", "margin"}, containingWidth)
+}
+
+// resolveRadius resolves border radius percentages against the shorter side of the box
+func (s *styles) resolveRadius(box size) {
+	s.computeEdges([]string{"borderRadius"}, math.Min(box.Width, box.Height))
+}
+
+// computeEdges stores the pixel values of edge properties that use relative units or
+// logical sides
+func (s *styles) computeEdges(keys []string, percentBasis float64) {
+	for _, key := range keys {
+		lengths, source, logical, ok := s.specifiedEdges(key)
+		if !ok || (lengths.isAbsolute() && !logical) {
+			continue
+		}
+		if s.computed == nil {
+			s.computed = make(map[string]styleValue)
+		}
+		insets := lengths.resolve(s.fontSize(), s.remSize(), percentBasis)
+		s.computed[key] = styleValue{Type: pixel, Value: insets, Source: source}
+	}
+}
+
+// resolveLength converts a single length to pixels, with percentages of percentBasis.
+// It returns false for unset and auto lengths.
+func (s *styles) resolveLength(key string, percentBasis float64) (float64, bool) {
+	value, ok := s.getValue(key)
+	if !ok {
+		return 0, false
+	}
+	return absoluteLength(value, s.fontSize(), s.remSize(), percentBasis)
+}
+
+// fontSize returns the node's font size in pixels
+func (s *styles) fontSize() float64 {
+	if size, ok := s.getFloat("fontSize"); ok {
+		return size
+	}
+	return defaultFontSize
+}
+
+// remSize returns the font size rem lengths are relative to
+func (s *styles) remSize() float64 {
+	if s.rootFontSize == 0 {
+		return defaultFontSize
+	}
+	return s.rootFontSize
+}
+
+// parseAspectRatio parses a width to height ratio such as 16/9, 16 / 9 or 1.5
+func parseAspectRatio(value string) (float64, error) {
+	width, height, found := strings.Cut(value, "/")
+	ratio, err := strconv.ParseFloat(strings.TrimSpace(width), 64)
+	if err == nil && found {
+		var divisor float64
+		divisor, err = strconv.ParseFloat(strings.TrimSpace(height), 64)
+		ratio /= divisor
+	}
+	if err != nil || ratio <= 0 || math.IsInf(ratio, 0) || math.IsNaN(ratio) {
+		return 0, styleError{Property: "aspectRatio", Value: value, Message: "Expected a positive ratio such as 16/9"}
+	}
+	return ratio, nil
+}
@@ -1,649 +1,1103 @@
-package node
-
-import (
-	"strconv"
-	"strings"
-
-	"github.com/noahdw/goui/node/style"
-)
-
-// Style builder methods for Node interface
-type StyleBuilder interface {
-	// Layout properties
-	Width(value interface{}) Node  // Can be number, "auto", percentage string, etc.
-	Height(value interface{}) Node // Can be number, "auto", percentage string, etc.
-	MinWidth(value interface{}) Node
-	MaxWidth(value interface{}) Node
-	MinHeight(value interface{}) Node
-	MaxHeight(value interface{}) Node
-
-	// Spacing
-	Margin(value interface{}) Node  // Can be number (all sides), [top, right, bottom, left], or EdgeInsets
-	Padding(value interface{}) Node // Can be number (all sides), [top, right, bottom, left], or EdgeInsets
-
-	// Positioning
-	Position(value string) Node
-	Top(value interface{}) Node    // Can be number, percentage string, etc.
-	Right(value interface{}) Node  // Can be number, percentage string, etc.
-	Bottom(value interface{}) Node // Can be number, percentage string, etc.
-	Left(value interface{}) Node   // Can be number, percentage string, etc.
-	ZIndex(value int) Node
-
-	// Flex layout
-	FlexDirection(value string) Node
-	JustifyContent(value string) Node
-	AlignItems(value string) Node
-	FlexWrap(value string) Node
-
-	// Typography
-	FontFamily(value string) Node
-	FontSize(value interface{}) Node   // Can be number, "em" string, "rem" string, etc.
-	FontWeight(value interface{}) Node // Can be number, "bold", "normal", etc.
-	LineHeight(value interface{}) Node // Can be number, "em" string, etc.
-	TextAlign(value string) Node
-	Color(value interface{}) Node // Can be Color object, color name string, hex string, etc.
-
-	// Visual styling
-	Background(value interface{}) Node   // Can be Color object, color name string, hex string, etc.
-	Border(value interface{}) Node       // Can be BorderStyle object, or individual components
-	BorderRadius(value interface{}) Node // Can be number (all corners), [top, right, bottom, left], or EdgeInsets
-	Shadow(value interface{}) Node       // Can be ShadowStyle object, or individual components
-	Opacity(value interface{}) Node      // Can be number, percentage string, etc.
-	Scale(value interface{}) Node        // Can be number, percentage string, etc.
-}
-
-// Implementation of style builder methods for BaseNode
-func (n *BaseNode) Width(value interface{}) Node {
-	switch v := value.(type) {
-	case float64:
-		n.styles.Set("width", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
-	case int:
-		n.styles.Set("width", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
-	case string:
-		if v == "auto" {
-			n.styles.Set("width", style.StyleValue{Type: style.AUTO, Value: 0, Source: style.Explicit})
-		} else if strings.HasSuffix(v, "%") {
-			if pct, err := strconv.ParseFloat(v[:len(v)-1], 64); err == nil {
-				n.styles.Set("width", style.StyleValue{Type: style.PERCENTAGE, Value: pct, Source: style.Explicit})
-			}
-		}
-	default:
-		n.styles.Set("width", value)
-	}
-	return n
-}
-
-func (n *BaseNode) Height(value interface{}) Node {
-	switch v := value.(type) {
-	case float64:
-		n.styles.Set("height", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
-	case int:
-		n.styles.Set("height", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
-	case string:
-		if v == "auto" {
-			n.styles.Set("height", style.StyleValue{Type: style.AUTO, Value: 0, Source: style.Explicit})
-		} else if strings.HasSuffix(v, "%") {
-			if pct, err := strconv.ParseFloat(v[:len(v)-1], 64); err == nil {
-				n.styles.Set("height", style.StyleValue{Type: style.PERCENTAGE, Value: pct, Source: style.Explicit})
-			}
-		}
-	default:
-		n.styles.Set("height", value)
-	}
-	return n
-}
-
-func (n *BaseNode) MinWidth(value interface{}) Node {
-	switch v := value.(type) {
-	case float64:
-		n.styles.Set("minWidth", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
-	case int:
-		n.styles.Set("minWidth", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
-	default:
-		n.styles.Set("minWidth", value)
-	}
-	return n
-}
-
-func (n *BaseNode) MaxWidth(value interface{}) Node {
-	switch v := value.(type) {
-	case float64:
-		n.styles.Set("maxWidth", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
-	case int:
-		n.styles.Set("maxWidth", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
-	default:
-		n.styles.Set("maxWidth", value)
-	}
-	return n
-}
-
-func (n *BaseNode) MinHeight(value interface{}) Node {
-	switch v := value.(type) {
-	case float64:
-		n.styles.Set("minHeight", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
-	case int:
-		n.styles.Set("minHeight", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
-	default:
-		n.styles.Set("minHeight", value)
-	}
-	return n
-}
-
-func (n *BaseNode) MaxHeight(value interface{}) Node {
-	switch v := value.(type) {
-	case float64:
-		n.styles.Set("maxHeight", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
-	case int:
-		n.styles.Set("maxHeight", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
-	default:
-		n.styles.Set("maxHeight", value)
-	}
-	return n
-}
-
-func (n *BaseNode) Margin(value interface{}) Node {
-	switch v := value.(type) {
-	case float64:
-		n.styles.Set("margin", style.EdgeInsets{Top: v, Right: v, Bottom: v, Left: v})
-	case int:
-		n.styles.Set("margin", style.EdgeInsets{Top: float64(v), Right: float64(v), Bottom: float64(v), Left: float64(v)})
-	case []float64:
-		if len(v) == 4 {
-			n.styles.Set("margin", style.EdgeInsets{Top: v[0], Right: v[1], Bottom: v[2], Left: v[3]})
-		}
-	case []int:
-		if len(v) == 4 {
-			n.styles.Set("margin", style.EdgeInsets{
-				Top:    float64(v[0]),
-				Right:  float64(v[1]),
-				Bottom: float64(v[2]),
-				Left:   float64(v[3]),
-			})
-		}
-	case style.EdgeInsets:
-		n.styles.Set("margin", v)
-	default:
-		n.styles.Set("margin", value)
-	}
-	return n
-}
-
-func (n *BaseNode) Padding(value interface{}) Node {
-	switch v := value.(type) {
-	case float64:
-		n.styles.Set("padding", style.EdgeInsets{Top: v, Right: v, Bottom: v, Left: v})
-	case int:
-		n.styles.Set("padding", style.EdgeInsets{Top: float64(v), Right: float64(v), Bottom: float64(v), Left: float64(v)})
-	case []float64:
-		if len(v) == 4 {
-			n.styles.Set("padding", style.EdgeInsets{Top: v[0], Right: v[1], Bottom: v[2], Left: v[3]})
-		}
-	case []int:
-		if len(v) == 4 {
-			n.styles.Set("padding", style.EdgeInsets{
-				Top:    float64(v[0]),
-				Right:  float64(v[1]),
-				Bottom: float64(v[2]),
-				Left:   float64(v[3]),
-			})
-		}
-	case style.EdgeInsets:
-		n.styles.Set("padding", v)
-	default:
-		n.styles.Set("padding", value)
-	}
-	return n
-}
-
-func (n *BaseNode) Position(value string) Node {
-	n.styles.Set("position", value)
-	return n
-}
-
-func (n *BaseNode) Top(value interface{}) Node {
-	switch v := value.(type) {
-	case float64:
-		n.styles.Set("top", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
-	case int:
-		n.styles.Set("top", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
-	case string:
-		if strings.HasSuffix(v, "%") {
-			if pct, err := strconv.ParseFloat(v[:len(v)-1], 64); err == nil {
-				n.styles.Set("top", style.StyleValue{Type: style.PERCENTAGE, Value: pct, Source: style.Explicit})
-			}
-		}
-	default:
-		n.styles.Set("top", value)
-	}
-	return n
-}
-
-func (n *BaseNode) Right(value interface{}) Node {
-	switch v := value.(type) {
-	case float64:
-		n.styles.Set("right", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
-	case int:
-		n.styles.Set("right", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
-	case string:
-		if strings.HasSuffix(v, "%") {
-			if pct, err := strconv.ParseFloat(v[:len(v)-1], 64); err == nil {
-				n.styles.Set("right", style.StyleValue{Type: style.PERCENTAGE, Value: pct, Source: style.Explicit})
-			}
-		}
-	default:
-		n.styles.Set("right", value)
-	}
-	return n
-}
-
-func (n *BaseNode) Bottom(value interface{}) Node {
-	switch v := value.(type) {
-	case float64:
-		n.styles.Set("bottom", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
-	case int:
-		n.styles.Set("bottom", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
-	case string:
-		if strings.HasSuffix(v, "%") {
-			if pct, err := strconv.ParseFloat(v[:len(v)-1], 64); err == nil {
-				n.styles.Set("bottom", style.StyleValue{Type: style.PERCENTAGE, Value: pct, Source: style.Explicit})
-			}
-		}
-	default:
-		n.styles.Set("bottom", value)
-	}
-	return n
-}
-
-func (n *BaseNode) Left(value interface{}) Node {
-	switch v := value.(type) {
-	case float64:
-		n.styles.Set("left", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
-	case int:
-		n.styles.Set("left", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
-	case string:
-		if strings.HasSuffix(v, "%") {
-			if pct, err := strconv.ParseFloat(v[:len(v)-1], 64); err == nil {
-				n.styles.Set("left", style.StyleValue{Type: style.PERCENTAGE, Value: pct, Source: style.Explicit})
-			}
-		}
-	default:
-		n.styles.Set("left", value)
-	}
-	return n
-}
-
-func (n *BaseNode) ZIndex(value int) Node {
-	n.styles.Set("zIndex", value)
-	return n
-}
-
-func (n *BaseNode) FlexDirection(value string) Node {
-	n.styles.Set("flexDirection", value)
-	return n
-}
-
-func (n *BaseNode) JustifyContent(value string) Node {
-	n.styles.Set("justifyContent", value)
-	return n
-}
-
-func (n *BaseNode) AlignItems(value string) Node {
-	n.styles.Set("alignItems", value)
-	return n
-}
-
-func (n *BaseNode) FlexWrap(value string) Node {
-	n.styles.Set("flexWrap", value)
-	return n
-}
-
-func (n *BaseNode) FontFamily(value string) Node {
-	n.styles.Set("fontFamily", value)
-	return n
-}
-
-func (n *BaseNode) FontSize(value interface{}) Node {
-	switch v := value.(type) {
-	case float64:
-		n.styles.Set("fontSize", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
-	case int:
-		n.styles.Set("fontSize", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
-	case string:
-		if strings.HasSuffix(v, "em") {
-			if em, err := strconv.ParseFloat(v[:len(v)-2], 64); err == nil {
-				n.styles.Set("fontSize", style.StyleValue{Type: style.EM, Value: em, Source: style.Explicit})
-			}
-		} else if strings.HasSuffix(v, "rem") {
-			if rem, err := strconv.ParseFloat(v[:len(v)-3], 64); err == nil {
-				n.styles.Set("fontSize", style.StyleValue{Type: style.REM, Value: rem, Source: style.Explicit})
-			}
-		}
-	default:
-		n.styles.Set("fontSize", value)
-	}
-	return n
-}
-
-func (n *BaseNode) FontWeight(value interface{}) Node {
-	switch v := value.(type) {
-	case float64:
-		n.styles.Set("fontWeight", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
-	case int:
-		n.styles.Set("fontWeight", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
-	case string:
-		if v == "bold" {
-			n.styles.Set("fontWeight", style.StyleValue{Type: style.PIXEL, Value: 700.0, Source: style.Explicit})
-		} else if v == "normal" {
-			n.styles.Set("fontWeight", style.StyleValue{Type: style.PIXEL, Value: 400.0, Source: style.Explicit})
-		}
-	default:
-		n.styles.Set("fontWeight", value)
-	}
-	return n
-}
-
-func (n *BaseNode) LineHeight(value interface{}) Node {
-	switch v := value.(type) {
-	case float64:
-		n.styles.Set("lineHeight", style.StyleValue{Type: style.EM, Value: v, Source: style.Explicit})
-	case int:
-		n.styles.Set("lineHeight", style.StyleValue{Type: style.EM, Value: float64(v), Source: style.Explicit})
-	case string:
-		if strings.HasSuffix(v, "em") {
-			if em, err := strconv.ParseFloat(v[:len(v)-2], 64); err == nil {
-				n.styles.Set("lineHeight", style.StyleValue{Type: style.EM, Value: em, Source: style.Explicit})
-			}
-		}
-	default:
-		n.styles.Set("lineHeight", value)
-	}
-	return n
-}
-
-func (n *BaseNode) TextAlign(value string) Node {
-	n.styles.Set("textAlign", value)
-	return n
-}
-
-func (n *BaseNode) Color(value interface{}) Node {
-	switch v := value.(type) {
-	case style.Color:
-		n.styles.Set("color", v)
-	case string:
-		if color, ok := parseColorString(v); ok {
-			n.styles.Set("color", color)
-		} else {
-			n.styles.Set("color", v)
-		}
-	default:
-		n.styles.Set("color", value)
-	}
-	return n
-}
-
-func (n *BaseNode) Background(value interface{}) Node {
-	switch v := value.(type) {
-	case style.Color:
-		n.styles.Set("background", v)
-	case string:
-		if color, ok := parseColorString(v); ok {
-			n.styles.Set("background", color)
-		} else {
-			n.styles.Set("background", v)
-		}
-	default:
-		n.styles.Set("background", value)
-	}
-	return n
-}
-
-func (n *BaseNode) Border(value interface{}) Node {
-	switch v := value.(type) {
-	case style.BorderStyle:
-		n.styles.Set("border", v)
-	case []interface{}:
-		if len(v) == 3 {
-			width, _ := v[0].(style.EdgeInsets)
-			styleStr, _ := v[1].(string)
-			color, _ := v[2].(style.Color)
-			n.styles.Set("border", style.BorderStyle{Width: width, Style: styleStr, Color: color})
-		}
-	default:
-		n.styles.Set("border", value)
-	}
-	return n
-}
-
-func (n *BaseNode) BorderRadius(value interface{}) Node {
-	switch v := value.(type) {
-	case float64:
-		n.styles.Set("borderRadius", style.EdgeInsets{Top: v, Right: v, Bottom: v, Left: v})
-	case int:
-		n.styles.Set("borderRadius", style.EdgeInsets{Top: float64(v), Right: float64(v), Bottom: float64(v), Left: float64(v)})
-	case []float64:
-		if len(v) == 4 {
-			n.styles.Set("borderRadius", style.EdgeInsets{Top: v[0], Right: v[1], Bottom: v[2], Left: v[3]})
-		}
-	case []int:
-		if len(v) == 4 {
-			n.styles.Set("borderRadius", style.EdgeInsets{
-				Top:    float64(v[0]),
-				Right:  float64(v[1]),
-				Bottom: float64(v[2]),
-				Left:   float64(v[3]),
-			})
-		}
-	case style.EdgeInsets:
-		n.styles.Set("borderRadius", v)
-	default:
-		n.styles.Set("borderRadius", value)
-	}
-	return n
-}
-
-func (n *BaseNode) Shadow(value interface{}) Node {
-	switch v := value.(type) {
-	case style.ShadowStyle:
-		n.styles.Set("shadow", v)
-	case []interface{}:
-		if len(v) == 5 {
-			offsetX, _ := v[0].(float64)
-			offsetY, _ := v[1].(float64)
-			blurRadius, _ := v[2].(float64)
-			spreadRadius, _ := v[3].(float64)
-			color, _ := v[4].(style.Color)
-			n.styles.Set("shadow", style.ShadowStyle{
-				OffsetX:      offsetX,
-				OffsetY:      offsetY,
-				BlurRadius:   blurRadius,
-				SpreadRadius: spreadRadius,
-				Color:        color,
-			})
-		}
-	default:
-		n.styles.Set("shadow", value)
-	}
-	return n
-}
-
-func (n *BaseNode) Opacity(value interface{}) Node {
-	switch v := value.(type) {
-	case float64:
-		n.styles.Set("opacity", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
-	case int:
-		n.styles.Set("opacity", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
-	case string:
-		if strings.HasSuffix(v, "%") {
-			if pct, err := strconv.ParseFloat(v[:len(v)-1], 64); err == nil {
-				n.styles.Set("opacity", style.StyleValue{Type: style.PERCENTAGE, Value: pct / 100.0, Source: style.Explicit})
-			}
-		}
-	default:
-		n.styles.Set("opacity", value)
-	}
-	return n
-}
-
-func (n *BaseNode) Scale(value interface{}) Node {
-	switch v := value.(type) {
-	case float64:
-		n.styles.Set("scale", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
-	case int:
-		n.styles.Set("scale", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
-	case string:
-		if strings.HasSuffix(v, "%") {
-			if pct, err := strconv.ParseFloat(v[:len(v)-1], 64); err == nil {
-				n.styles.Set("scale", style.StyleValue{Type: style.PERCENTAGE, Value: pct / 100.0, Source: style.Explicit})
-			}
-		}
-	default:
-		n.styles.Set("scale", value)
-	}
-	return n
-}
-
-// Common color names mapped to their hex values
-var colorNames = map[string]string{
-	"black":   "#000000",
-	"white":   "#FFFFFF",
-	"red":     "#FF0000",
-	"green":   "#00FF00",
-	"blue":    "#0000FF",
-	"yellow":  "#FFFF00",
-	"cyan":    "#00FFFF",
-	"magenta": "#FF00FF",
-	"gray":    "#808080",
-	"grey":    "#808080",
-	"purple":  "#800080",
-	"orange":  "#FFA500",
-	"pink":    "#FFC0CB",
-	"brown":   "#A52A2A",
-	"silver":  "#C0C0C0",
-	"gold":    "#FFD700",
-}
-
-// Helper function to parse color strings (hex, color names, etc.)
-func parseColorString(s string) (style.Color, bool) {
-	// Convert to lowercase for case-insensitive matching
-	s = strings.ToLower(strings.TrimSpace(s))
-
-	// Check if it's a named color
-	if hex, ok := colorNames[s]; ok {
-		return parseHexColor(hex)
-	}
-
-	// Check if it's a hex color
-	if strings.HasPrefix(s, "#") {
-		return parseHexColor(s)
-	}
-
-	// Check if it's an rgb/rgba function
-	if strings.HasPrefix(s, "rgb(") || strings.HasPrefix(s, "rgba(") {
-		return parseRGBFunction(s)
-	}
-
-	return style.Color{}, false
-}
-
-// parseHexColor parses a hex color string (#RGB, #RGBA, #RRGGBB, #RRGGBBAA)
-func parseHexColor(hex string) (style.Color, bool) {
-	hex = strings.TrimPrefix(hex, "#")
-
-	var r, g, b, a uint8
-	a = 255 // Default alpha to 255 (fully opaque)
-
-	switch len(hex) {
-	case 3: // #RGB
-		r = parseHexByte(hex[0:1] + hex[0:1])
-		g = parseHexByte(hex[1:2] + hex[1:2])
-		b = parseHexByte(hex[2:3] + hex[2:3])
-	case 4: // #RGBA
-		r = parseHexByte(hex[0:1] + hex[0:1])
-		g = parseHexByte(hex[1:2] + hex[1:2])
-		b = parseHexByte(hex[2:3] + hex[2:3])
-		a = parseHexByte(hex[3:4] + hex[3:4])
-	case 6: // #RRGGBB
-		r = parseHexByte(hex[0:2])
-		g = parseHexByte(hex[2:4])
-		b = parseHexByte(hex[4:6])
-	case 8: // #RRGGBBAA
-		r = parseHexByte(hex[0:2])
-		g = parseHexByte(hex[2:4])
-		b = parseHexByte(hex[4:6])
-		a = parseHexByte(hex[6:8])
-	default:
-		return style.Color{}, false
-	}
-
-	return style.Color{R: r, G: g, B: b, A: a}, true
-}
-
-// parseHexByte converts a hex byte string to a uint8
-func parseHexByte(hex string) uint8 {
-	val, _ := strconv.ParseInt(hex, 16, 64)
-	return uint8(val)
-}
-
-// parseRGBFunction parses rgb() and rgba() function strings
-func parseRGBFunction(s string) (style.Color, bool) {
-	// Remove rgb( or rgba( and the closing )
-	s = strings.TrimPrefix(s, "rgb(")
-	s = strings.TrimPrefix(s, "rgba(")
-	s = strings.TrimSuffix(s, ")")
-
-	// Split by commas and trim spaces
-	parts := strings.Split(s, ",")
-	for i := range parts {
-		parts[i] = strings.TrimSpace(parts[i])
-	}
-
-	var r, g, b, a uint8
-	a = 255 // Default alpha to 255 (fully opaque)
-
-	// Parse RGB values
-	if len(parts) < 3 {
-		return style.Color{}, false
-	}
-
-	// Parse R
-	if strings.HasSuffix(parts[0], "%") {
-		r = uint8(parsePercentage(parts[0]) * 2.55) // Convert percentage to 0-255
-	} else {
-		r = uint8(parseNumber(parts[0]))
-	}
-
-	// Parse G
-	if strings.HasSuffix(parts[1], "%") {
-		g = uint8(parsePercentage(parts[1]) * 2.55) // Convert percentage to 0-255
-	} else {
-		g = uint8(parseNumber(parts[1]))
-	}
-
-	// Parse B
-	if strings.HasSuffix(parts[2], "%") {
-		b = uint8(parsePercentage(parts[2]) * 2.55) // Convert percentage to 0-255
-	} else {
-		b = uint8(parseNumber(parts[2]))
-	}
-
-	// Parse A if present (rgba)
-	if len(parts) > 3 {
-		// Alpha is typically 0-1 in rgba(), so multiply by 255
-		a = uint8(parseNumber(parts[3]) * 255)
-	}
-
-	return style.Color{R: r, G: g, B: b, A: a}, true
-}
-
-// parsePercentage converts a percentage string to a float64
-func parsePercentage(s string) float64 {
-	s = strings.TrimSuffix(s, "%")
-	val, _ := strconv.ParseFloat(s, 64)
-	return val
-}
-
-// parseNumber converts a number string to a float64
-func parseNumber(s string) float64 {
-	val, _ := strconv.ParseFloat(s, 64)
-	return val
-}
+package node
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/noahdw/goui/node/solver"
+	"github.com/noahdw/goui/node/style"
+)
+
+// Style builder methods for Node interface
+type StyleBuilder interface {
+	// Layout properties
+	Width(value interface{}) Node  // Can be number, "auto", percentage string, etc.
+	Height(value interface{}) Node // Can be number, "auto", percentage string, etc.
+	MinWidth(value interface{}) Node
+	MaxWidth(value interface{}) Node
+	MinHeight(value interface{}) Node
+	MaxHeight(value interface{}) Node
+	AspectRatio(value interface{}) Node // Can be a number or a ratio string such as "16/9"
+
+	// Spacing
+	Margin(value interface{}) Node  // Can be number (all sides), [top, right, bottom, left], EdgeInsets or a string such as "1em 5%", where auto sides take up free space
+	Padding(value interface{}) Node // Can be number (all sides), [top, right, bottom, left], EdgeInsets or a string such as "1em 5%"
+
+	// Logical spacing, for one side relative to the direction of the content, which wins over
+	// the physical side it lands on. Each takes a number or a length string such as "1em".
+	MarginInlineStart(value interface{}) Node  // Left, or right in right-to-left content
+	MarginInlineEnd(value interface{}) Node    // Right, or left in right-to-left content
+	MarginBlockStart(value interface{}) Node   // Top
+	MarginBlockEnd(value interface{}) Node     // Bottom
+	PaddingInlineStart(value interface{}) Node // Left, or right in right-to-left content
+	PaddingInlineEnd(value interface{}) Node   // Right, or left in right-to-left content
+	PaddingBlockStart(value interface{}) Node  // Top
+	PaddingBlockEnd(value interface{}) Node    // Bottom
+
+	// Positioning
+	Position(value string) Node    // "static", "relative", "absolute", "fixed" or "sticky"
+	Top(value interface{}) Node    // Can be number, percentage string, etc.
+	Right(value interface{}) Node  // Can be number, percentage string, etc.
+	Bottom(value interface{}) Node // Can be number, percentage string, etc.
+	Left(value interface{}) Node   // Can be number, percentage string, etc.
+	ZIndex(value int) Node
+
+	// Flex layout
+	FlexDirection(value string) Node
+	JustifyContent(value string) Node
+	AlignItems(value string) Node
+	FlexWrap(value string) Node
+	FlexGrow(value float64) Node
+	FlexShrink(value float64) Node
+	FlexBasis(value interface{}) Node // Can be number, percentage string, or "auto"
+	AlignContent(value string) Node
+	AlignSelf(value string) Node      // Overrides the parent's alignItems, or "auto" to follow it
+	Order(value int) Node             // Lays the node out before siblings with a higher order
+	RowGap(value interface{}) Node    // Can be number, percentage, "em" or "rem" string
+	ColumnGap(value interface{}) Node // Can be number, percentage, "em" or "rem" string
+	Gap(value interface{}) Node       // Sets both rowGap and columnGap
+
+	// Grid layout
+	Display(value string) Node                  // "flex", "grid", "table", "table-row", "stack" or "constraint"
+	GridTemplateColumns(value interface{}) Node // Can be a track list like "200px 1fr repeat(2, minmax(100px, 1fr))", or []GridTrack
+	GridTemplateRows(value interface{}) Node    // Same as GridTemplateColumns
+	GridColumn(value interface{}) Node          // Can be a line number, a placement like "1 / 3" or "span 2", or GridPlacement
+	GridRow(value interface{}) Node             // Same as GridColumn
+
+	// Typography
+	FontFamily(value string) Node
+	FontSize(value interface{}) Node   // Can be number, "em" string, "rem" string, etc.
+	FontWeight(value interface{}) Node // Can be number, "bold", "normal", etc.
+	LineHeight(value interface{}) Node // Can be number, "em" string, etc.
+	TextAlign(value string) Node       // "left", "center", "right", "start" or "end", where start is the right in right-to-left content
+	Direction(value string) Node       // "ltr" or "rtl", inherited by descendants
+	Color(value interface{}) Node      // Can be Color object, color name string, hex string, etc.
+
+	// Visual styling
+	Background(value interface{}) Node   // Can be Color object, color name string, hex string, etc.
+	Border(value interface{}) Node       // Can be BorderStyle object, or individual components
+	BorderRadius(value interface{}) Node // Can be number (all corners), [top, right, bottom, left], EdgeInsets or a string such as "50%"
+	Shadow(value interface{}) Node       // Can be ShadowStyle object, or individual components
+	Opacity(value interface{}) Node      // Can be number, percentage string, etc.
+	Scale(value interface{}) Node        // Can be number, percentage string, etc.
+	Filter(value interface{}) Node       // Can be a filter string like "blur(4px) grayscale(1)", Filter, or []Filter
+	MixBlendMode(value string) Node      // "normal", "multiply", "screen", "overlay", "darken", "lighten", etc.
+
+	// Rendering
+	Layer(value bool) Node // Caches the node and its subtree in an offscreen layer
+
+	// Scrolling
+	Overflow(value string) Node                 // "visible", "hidden", "scroll" or "auto"
+	ScrollbarWidth(value float64) Node          // Thickness of the scrollbars
+	ScrollbarColor(value interface{}) Node      // Color of the scrollbar thumb, as for Background
+	ScrollbarTrackColor(value interface{}) Node // Color of the scrollbar track, as for Background
+
+	// Table layout
+	BorderCollapse(value string) Node // "separate" or "collapse"
+	ColumnAlign(value string) Node    // Text alignment of each column, such as "left right center"
+	ColSpan(value int) Node           // Number of columns a cell covers
+	RowSpan(value int) Node           // Number of rows a cell covers
+
+	// Stack layout
+	StackAlign(value string) Node  // Where a child sits in its stack, such as "top-left", "center" or "bottom-right"
+	StackOffset(x, y float64) Node // Moves a child from where its stack aligns it
+
+	// Constraint layout
+	Constrain(rule string, strength solver.Strength) Node // Adds a rule such as "label.left == input.left" relating children by ID
+
+	// Responsive styles
+	When(breakpoint Breakpoint, props map[string]interface{}) Node // Applies the props while the window matches, e.g. When(MinWidth(1024), ...)
+}
+
+// Implementation of style builder methods for BaseNode
+func (n *BaseNode) Width(value interface{}) Node {
+	switch v := value.(type) {
+	case float64:
+		n.setStyle("width", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
+	case int:
+		n.setStyle("width", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
+	case string:
+		if length, err := style.ParseLength(v); err == nil {
+			n.setStyle("width", length)
+		}
+	default:
+		n.setStyle("width", value)
+	}
+	return n
+}
+
+func (n *BaseNode) Height(value interface{}) Node {
+	switch v := value.(type) {
+	case float64:
+		n.setStyle("height", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
+	case int:
+		n.setStyle("height", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
+	case string:
+		if length, err := style.ParseLength(v); err == nil {
+			n.setStyle("height", length)
+		}
+	default:
+		n.setStyle("height", value)
+	}
+	return n
+}
+
+func (n *BaseNode) MinWidth(value interface{}) Node {
+	switch v := value.(type) {
+	case float64:
+		n.setStyle("minWidth", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
+	case int:
+		n.setStyle("minWidth", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
+	default:
+		n.setStyle("minWidth", value)
+	}
+	return n
+}
+
+func (n *BaseNode) MaxWidth(value interface{}) Node {
+	switch v := value.(type) {
+	case float64:
+		n.setStyle("maxWidth", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
+	case int:
+		n.setStyle("maxWidth", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
+	default:
+		n.setStyle("maxWidth", value)
+	}
+	return n
+}
+
+func (n *BaseNode) MinHeight(value interface{}) Node {
+	switch v := value.(type) {
+	case float64:
+		n.setStyle("minHeight", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
+	case int:
+		n.setStyle("minHeight", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
+	default:
+		n.setStyle("minHeight", value)
+	}
+	return n
+}
+
+func (n *BaseNode) MaxHeight(value interface{}) Node {
+	switch v := value.(type) {
+	case float64:
+		n.setStyle("maxHeight", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
+	case int:
+		n.setStyle("maxHeight", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
+	default:
+		n.setStyle("maxHeight", value)
+	}
+	return n
+}
+
+func (n *BaseNode) AspectRatio(value interface{}) Node {
+	switch v := value.(type) {
+	case string:
+		if ratio, err := style.ParseAspectRatio(v); err == nil {
+			n.setStyle("aspectRatio", ratio)
+		}
+	default:
+		n.setStyle("aspectRatio", value)
+	}
+	return n
+}
+
+func (n *BaseNode) Margin(value interface{}) Node {
+	switch v := value.(type) {
+	case float64:
+		n.setStyle("margin", style.EdgeInsets{Top: v, Right: v, Bottom: v, Left: v})
+	case int:
+		n.setStyle("margin", style.EdgeInsets{Top: float64(v), Right: float64(v), Bottom: float64(v), Left: float64(v)})
+	case []float64:
+		if len(v) == 4 {
+			n.setStyle("margin", style.EdgeInsets{Top: v[0], Right: v[1], Bottom: v[2], Left: v[3]})
+		}
+	case []int:
+		if len(v) == 4 {
+			n.setStyle("margin", style.EdgeInsets{
+				Top:    float64(v[0]),
+				Right:  float64(v[1]),
+				Bottom: float64(v[2]),
+				Left:   float64(v[3]),
+			})
+		}
+	case string:
+		if lengths, err := style.ParseEdgeLengths(v); err == nil {
+			n.setStyle("margin", lengths)
+		}
+	case style.EdgeInsets:
+		n.setStyle("margin", v)
+	default:
+		n.setStyle("margin", value)
+	}
+	return n
+}
+
+func (n *BaseNode) Padding(value interface{}) Node {
+	switch v := value.(type) {
+	case float64:
+		n.setStyle("padding", style.EdgeInsets{Top: v, Right: v, Bottom: v, Left: v})
+	case int:
+		n.setStyle("padding", style.EdgeInsets{Top: float64(v), Right: float64(v), Bottom: float64(v), Left: float64(v)})
+	case []float64:
+		if len(v) == 4 {
+			n.setStyle("padding", style.EdgeInsets{Top: v[0], Right: v[1], Bottom: v[2], Left: v[3]})
+		}
+	case []int:
+		if len(v) == 4 {
+			n.setStyle("padding", style.EdgeInsets{
+				Top:    float64(v[0]),
+				Right:  float64(v[1]),
+				Bottom: float64(v[2]),
+				Left:   float64(v[3]),
+			})
+		}
+	case string:
+		if lengths, err := style.ParseEdgeLengths(v); err == nil {
+			n.setStyle("padding", lengths)
+		}
+	case style.EdgeInsets:
+		n.setStyle("padding", v)
+	default:
+		n.setStyle("padding", value)
+	}
+	return n
+}
+
+// setEdge sets one side of a margin or padding to a number or a length string, keeping the
+// others
+func (n *BaseNode) setEdge(key, side string, value interface{}) {
+	var length style.StyleValue
+	switch v := value.(type) {
+	case float64:
+		length = style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit}
+	case int:
+		length = style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit}
+	case string:
+		parsed, err := style.ParseLength(v)
+		if err != nil {
+			return
+		}
+		length = parsed
+	default:
+		return
+	}
+	n.setStyle(key, n.styles.WithEdge(key, side, length))
+}
+
+func (n *BaseNode) MarginInlineStart(value interface{}) Node {
+	n.setLength("marginInlineStart", value)
+	return n
+}
+
+func (n *BaseNode) MarginInlineEnd(value interface{}) Node {
+	n.setLength("marginInlineEnd", value)
+	return n
+}
+
+func (n *BaseNode) MarginBlockStart(value interface{}) Node {
+	n.setEdge("margin", "top", value)
+	return n
+}
+
+func (n *BaseNode) MarginBlockEnd(value interface{}) Node {
+	n.setEdge("margin", "bottom", value)
+	return n
+}
+
+func (n *BaseNode) PaddingInlineStart(value interface{}) Node {
+	n.setLength("paddingInlineStart", value)
+	return n
+}
+
+func (n *BaseNode) PaddingInlineEnd(value interface{}) Node {
+	n.setLength("paddingInlineEnd", value)
+	return n
+}
+
+func (n *BaseNode) PaddingBlockStart(value interface{}) Node {
+	n.setEdge("padding", "top", value)
+	return n
+}
+
+func (n *BaseNode) PaddingBlockEnd(value interface{}) Node {
+	n.setEdge("padding", "bottom", value)
+	return n
+}
+
+func (n *BaseNode) Position(value string) Node {
+	n.setStyle("position", value)
+	return n
+}
+
+func (n *BaseNode) Top(value interface{}) Node {
+	switch v := value.(type) {
+	case float64:
+		n.setStyle("top", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
+	case int:
+		n.setStyle("top", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
+	case string:
+		if length, err := style.ParseLength(v); err == nil {
+			n.setStyle("top", length)
+		}
+	default:
+		n.setStyle("top", value)
+	}
+	return n
+}
+
+func (n *BaseNode) Right(value interface{}) Node {
+	switch v := value.(type) {
+	case float64:
+		n.setStyle("right", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
+	case int:
+		n.setStyle("right", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
+	case string:
+		if length, err := style.ParseLength(v); err == nil {
+			n.setStyle("right", length)
+		}
+	default:
+		n.setStyle("right", value)
+	}
+	return n
+}
+
+func (n *BaseNode) Bottom(value interface{}) Node {
+	switch v := value.(type) {
+	case float64:
+		n.setStyle("bottom", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
+	case int:
+		n.setStyle("bottom", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
+	case string:
+		if length, err := style.ParseLength(v); err == nil {
+			n.setStyle("bottom", length)
+		}
+	default:
+		n.setStyle("bottom", value)
+	}
+	return n
+}
+
+func (n *BaseNode) Left(value interface{}) Node {
+	switch v := value.(type) {
+	case float64:
+		n.setStyle("left", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
+	case int:
+		n.setStyle("left", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
+	case string:
+		if length, err := style.ParseLength(v); err == nil {
+			n.setStyle("left", length)
+		}
+	default:
+		n.setStyle("left", value)
+	}
+	return n
+}
+
+func (n *BaseNode) ZIndex(value int) Node {
+	n.setStyle("zIndex", value)
+	return n
+}
+
+func (n *BaseNode) FlexDirection(value string) Node {
+	n.setStyle("flexDirection", value)
+	return n
+}
+
+func (n *BaseNode) JustifyContent(value string) Node {
+	n.setStyle("justifyContent", value)
+	return n
+}
+
+func (n *BaseNode) AlignItems(value string) Node {
+	n.setStyle("alignItems", value)
+	return n
+}
+
+func (n *BaseNode) FlexWrap(value string) Node {
+	n.setStyle("flexWrap", value)
+	return n
+}
+
+func (n *BaseNode) FlexGrow(value float64) Node {
+	n.setStyle("flexGrow", value)
+	return n
+}
+
+func (n *BaseNode) FlexShrink(value float64) Node {
+	n.setStyle("flexShrink", value)
+	return n
+}
+
+func (n *BaseNode) AlignContent(value string) Node {
+	n.setStyle("alignContent", value)
+	return n
+}
+
+func (n *BaseNode) AlignSelf(value string) Node {
+	n.setStyle("alignSelf", value)
+	return n
+}
+
+func (n *BaseNode) Order(value int) Node {
+	n.setStyle("order", value)
+	return n
+}
+
+func (n *BaseNode) RowGap(value interface{}) Node {
+	n.setLength("rowGap", value)
+	return n
+}
+
+func (n *BaseNode) ColumnGap(value interface{}) Node {
+	n.setLength("columnGap", value)
+	return n
+}
+
+func (n *BaseNode) Gap(value interface{}) Node {
+	n.setLength("rowGap", value)
+	n.setLength("columnGap", value)
+	return n
+}
+
+// setLength stores a length given as a number or as a string with a unit
+func (n *BaseNode) setLength(key string, value interface{}) {
+	if v, ok := value.(string); ok {
+		if length, err := style.ParseLength(v); err == nil {
+			n.setStyle(key, length)
+		}
+		return
+	}
+	n.setStyle(key, value)
+}
+
+func (n *BaseNode) Display(value string) Node {
+	n.setStyle("display", value)
+	return n
+}
+
+func (n *BaseNode) GridTemplateColumns(value interface{}) Node {
+	return n.setGridTemplate("gridTemplateColumns", value)
+}
+
+func (n *BaseNode) GridTemplateRows(value interface{}) Node {
+	return n.setGridTemplate("gridTemplateRows", value)
+}
+
+// setGridTemplate stores a track list, parsing it if it is given as a string
+func (n *BaseNode) setGridTemplate(key string, value interface{}) Node {
+	switch v := value.(type) {
+	case string:
+		if tracks, err := style.ParseGridTemplate(v); err == nil {
+			n.setStyle(key, tracks)
+		}
+	default:
+		n.setStyle(key, value)
+	}
+	return n
+}
+
+func (n *BaseNode) GridColumn(value interface{}) Node {
+	return n.setGridPlacement("gridColumn", value)
+}
+
+func (n *BaseNode) GridRow(value interface{}) Node {
+	return n.setGridPlacement("gridRow", value)
+}
+
+// setGridPlacement stores an item placement, parsing it if it is given as a string or line number
+func (n *BaseNode) setGridPlacement(key string, value interface{}) Node {
+	switch v := value.(type) {
+	case string:
+		if placement, err := style.ParseGridPlacement(v); err == nil {
+			n.setStyle(key, placement)
+		}
+	case int:
+		n.setStyle(key, style.GridPlacement{Start: v, Span: 1})
+	default:
+		n.setStyle(key, value)
+	}
+	return n
+}
+
+func (n *BaseNode) FlexBasis(value interface{}) Node {
+	switch v := value.(type) {
+	case float64:
+		n.setStyle("flexBasis", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
+	case int:
+		n.setStyle("flexBasis", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
+	default:
+		n.setStyle("flexBasis", value)
+	}
+	return n
+}
+
+func (n *BaseNode) FontFamily(value string) Node {
+	n.setStyle("fontFamily", value)
+	return n
+}
+
+func (n *BaseNode) FontSize(value interface{}) Node {
+	switch v := value.(type) {
+	case float64:
+		n.setStyle("fontSize", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
+	case int:
+		n.setStyle("fontSize", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
+	case string:
+		if length, err := style.ParseLength(v); err == nil {
+			n.setStyle("fontSize", length)
+		}
+	default:
+		n.setStyle("fontSize", value)
+	}
+	return n
+}
+
+func (n *BaseNode) FontWeight(value interface{}) Node {
+	switch v := value.(type) {
+	case float64:
+		n.setStyle("fontWeight", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
+	case int:
+		n.setStyle("fontWeight", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
+	case string:
+		if v == "bold" {
+			n.setStyle("fontWeight", style.StyleValue{Type: style.PIXEL, Value: 700.0, Source: style.Explicit})
+		} else if v == "normal" {
+			n.setStyle("fontWeight", style.StyleValue{Type: style.PIXEL, Value: 400.0, Source: style.Explicit})
+		}
+	default:
+		n.setStyle("fontWeight", value)
+	}
+	return n
+}
+
+func (n *BaseNode) LineHeight(value interface{}) Node {
+	switch v := value.(type) {
+	case float64:
+		n.setStyle("lineHeight", style.StyleValue{Type: style.EM, Value: v, Source: style.Explicit})
+	case int:
+		n.setStyle("lineHeight", style.StyleValue{Type: style.EM, Value: float64(v), Source: style.Explicit})
+	case string:
+		if strings.HasSuffix(v, "em") {
+			if em, err := strconv.ParseFloat(v[:len(v)-2], 64); err == nil {
+				n.setStyle("lineHeight", style.StyleValue{Type: style.EM, Value: em, Source: style.Explicit})
+			}
+		}
+	default:
+		n.setStyle("lineHeight", value)
+	}
+	return n
+}
+
+func (n *BaseNode) TextAlign(value string) Node {
+	n.setStyle("textAlign", value)
+	return n
+}
+
+func (n *BaseNode) Direction(value string) Node {
+	n.setStyle("direction", value)
+	return n
+}
+
+func (n *BaseNode) Color(value interface{}) Node {
+	switch v := value.(type) {
+	case style.Color:
+		n.setStyle("color", v)
+	case string:
+		if color, ok := parseColorString(v); ok {
+			n.setStyle("color", color)
+		} else {
+			n.setStyle("color", v)
+		}
+	default:
+		n.setStyle("color", value)
+	}
+	return n
+}
+
+func (n *BaseNode) Background(value interface{}) Node {
+	switch v := value.(type) {
+	case style.Color:
+		n.setStyle("background", v)
+	case string:
+		if color, ok := parseColorString(v); ok {
+			n.setStyle("background", color)
+		} else {
+			n.setStyle("background", v)
+		}
+	default:
+		n.setStyle("background", value)
+	}
+	return n
+}
+
+func (n *BaseNode) Border(value interface{}) Node {
+	switch v := value.(type) {
+	case style.BorderStyle:
+		n.setStyle("border", v)
+	case []interface{}:
+		if len(v) == 3 {
+			width, _ := v[0].(style.EdgeInsets)
+			styleStr, _ := v[1].(string)
+			color, _ := v[2].(style.Color)
+			n.setStyle("border", style.BorderStyle{Width: width, Style: styleStr, Color: color})
+		}
+	default:
+		n.setStyle("border", value)
+	}
+	return n
+}
+
+func (n *BaseNode) BorderRadius(value interface{}) Node {
+	switch v := value.(type) {
+	case float64:
+		n.setStyle("borderRadius", style.EdgeInsets{Top: v, Right: v, Bottom: v, Left: v})
+	case int:
+		n.setStyle("borderRadius", style.EdgeInsets{Top: float64(v), Right: float64(v), Bottom: float64(v), Left: float64(v)})
+	case []float64:
+		if len(v) == 4 {
+			n.setStyle("borderRadius", style.EdgeInsets{Top: v[0], Right: v[1], Bottom: v[2], Left: v[3]})
+		}
+	case []int:
+		if len(v) == 4 {
+			n.setStyle("borderRadius", style.EdgeInsets{
+				Top:    float64(v[0]),
+				Right:  float64(v[1]),
+				Bottom: float64(v[2]),
+				Left:   float64(v[3]),
+			})
+		}
+	case string:
+		if lengths, err := style.ParseEdgeLengths(v); err == nil {
+			n.setStyle("borderRadius", lengths)
+		}
+	case style.EdgeInsets:
+		n.setStyle("borderRadius", v)
+	default:
+		n.setStyle("borderRadius", value)
+	}
+	return n
+}
+
+func (n *BaseNode) Shadow(value interface{}) Node {
+	switch v := value.(type) {
+	case style.ShadowStyle:
+		n.setStyle("shadow", v)
+	case []interface{}:
+		if len(v) == 5 {
+			offsetX, _ := v[0].(float64)
+			offsetY, _ := v[1].(float64)
+			blurRadius, _ := v[2].(float64)
+			spreadRadius, _ := v[3].(float64)
+			color, _ := v[4].(style.Color)
+			n.setStyle("shadow", style.ShadowStyle{
+				OffsetX:      offsetX,
+				OffsetY:      offsetY,
+				BlurRadius:   blurRadius,
+				SpreadRadius: spreadRadius,
+				Color:        color,
+			})
+		}
+	default:
+		n.setStyle("shadow", value)
+	}
+	return n
+}
+
+func (n *BaseNode) Opacity(value interface{}) Node {
+	switch v := value.(type) {
+	case float64:
+		n.setStyle("opacity", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
+	case int:
+		n.setStyle("opacity", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
+	case string:
+		if strings.HasSuffix(v, "%") {
+			if pct, err := strconv.ParseFloat(v[:len(v)-1], 64); err == nil {
+				n.setStyle("opacity", style.StyleValue{Type: style.PERCENTAGE, Value: pct / 100.0, Source: style.Explicit})
+			}
+		}
+	default:
+		n.setStyle("opacity", value)
+	}
+	return n
+}
+
+func (n *BaseNode) Scale(value interface{}) Node {
+	switch v := value.(type) {
+	case float64:
+		n.setStyle("scale", style.StyleValue{Type: style.PIXEL, Value: v, Source: style.Explicit})
+	case int:
+		n.setStyle("scale", style.StyleValue{Type: style.PIXEL, Value: float64(v), Source: style.Explicit})
+	case string:
+		if strings.HasSuffix(v, "%") {
+			if pct, err := strconv.ParseFloat(v[:len(v)-1], 64); err == nil {
+				n.setStyle("scale", style.StyleValue{Type: style.PERCENTAGE, Value: pct / 100.0, Source: style.Explicit})
+			}
+		}
+	default:
+		n.setStyle("scale", value)
+	}
+	return n
+}
+
+func (n *BaseNode) Filter(value interface{}) Node {
+	switch v := value.(type) {
+	case string:
+		if filters, ok := parseFilterString(v); ok {
+			n.setStyle("filter", filters)
+		}
+	case style.Filter:
+		n.setStyle("filter", []style.Filter{v})
+	case []style.Filter:
+		n.setStyle("filter", v)
+	default:
+		n.setStyle("filter", value)
+	}
+	return n
+}
+
+func (n *BaseNode) MixBlendMode(value string) Node {
+	if style.IsBlendMode(value) {
+		n.setStyle("mixBlendMode", value)
+	}
+	return n
+}
+
+func (n *BaseNode) Layer(value bool) Node {
+	n.setStyle("layer", value)
+	return n
+}
+
+func (n *BaseNode) Overflow(value string) Node {
+	n.setStyle("overflow", value)
+	return n
+}
+
+func (n *BaseNode) ScrollbarWidth(value float64) Node {
+	n.setStyle("scrollbarWidth", value)
+	return n
+}
+
+func (n *BaseNode) ScrollbarColor(value interface{}) Node {
+	return n.setColor("scrollbarColor", value)
+}
+
+func (n *BaseNode) ScrollbarTrackColor(value interface{}) Node {
+	return n.setColor("scrollbarTrackColor", value)
+}
+
+func (n *BaseNode) BorderCollapse(value string) Node {
+	n.setStyle("borderCollapse", value)
+	return n
+}
+
+func (n *BaseNode) ColumnAlign(value string) Node {
+	n.setStyle("columnAlign", value)
+	return n
+}
+
+func (n *BaseNode) ColSpan(value int) Node {
+	n.setStyle("colSpan", value)
+	return n
+}
+
+func (n *BaseNode) RowSpan(value int) Node {
+	n.setStyle("rowSpan", value)
+	return n
+}
+
+func (n *BaseNode) StackAlign(value string) Node {
+	n.setStyle("stackAlign", value)
+	return n
+}
+
+func (n *BaseNode) StackOffset(x, y float64) Node {
+	n.setStyle("stackOffsetX", x)
+	n.setStyle("stackOffsetY", y)
+	return n
+}
+
+func (n *BaseNode) When(breakpoint Breakpoint, props map[string]interface{}) Node {
+	n.breakpoints = append(n.breakpoints, breakpointStyle{breakpoint: breakpoint, props: parseBreakpointProps(props)})
+	n.MarkStyleDirty()
+	n.MarkLayoutDirty()
+	return n
+}
+
+// setColor stores a color, parsing it if it is given as a name or hex string
+func (n *BaseNode) setColor(key string, value interface{}) Node {
+	switch v := value.(type) {
+	case string:
+		if color, ok := parseColorString(v); ok {
+			n.setStyle(key, color)
+		}
+	default:
+		n.setStyle(key, value)
+	}
+	return n
+}
+
+// Common color names mapped to their hex values
+var colorNames = map[string]string{
+	"black":   "#000000",
+	"white":   "#FFFFFF",
+	"red":     "#FF0000",
+	"green":   "#00FF00",
+	"blue":    "#0000FF",
+	"yellow":  "#FFFF00",
+	"cyan":    "#00FFFF",
+	"magenta": "#FF00FF",
+	"gray":    "#808080",
+	"grey":    "#808080",
+	"purple":  "#800080",
+	"orange":  "#FFA500",
+	"pink":    "#FFC0CB",
+	"brown":   "#A52A2A",
+	"silver":  "#C0C0C0",
+	"gold":    "#FFD700",
+}
+
+// Helper function to parse color strings (hex, color names, etc.)
+func parseColorString(s string) (style.Color, bool) {
+	// Convert to lowercase for case-insensitive matching
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	// Check if it's a named color
+	if hex, ok := colorNames[s]; ok {
+		return parseHexColor(hex)
+	}
+
+	// Check if it's a hex color
+	if strings.HasPrefix(s, "#") {
+		return parseHexColor(s)
+	}
+
+	// Check if it's an rgb/rgba function
+	if strings.HasPrefix(s, "rgb(") || strings.HasPrefix(s, "rgba(") {
+		return parseRGBFunction(s)
+	}
+
+	return style.Color{}, false
+}
+
+// parseHexColor parses a hex color string (#RGB, #RGBA, #RRGGBB, #RRGGBBAA)
+func parseHexColor(hex string) (style.Color, bool) {
+	hex = strings.TrimPrefix(hex, "#")
+
+	var r, g, b, a uint8
+	a = 255 // Default alpha to 255 (fully opaque)
+
+	switch len(hex) {
+	case 3: // #RGB
+		r = parseHexByte(hex[0:1] + hex[0:1])
+		g = parseHexByte(hex[1:2] + hex[1:2])
+		b = parseHexByte(hex[2:3] + hex[2:3])
+	case 4: // #RGBA
+		r = parseHexByte(hex[0:1] + hex[0:1])
+		g = parseHexByte(hex[1:2] + hex[1:2])
+		b = parseHexByte(hex[2:3] + hex[2:3])
+		a = parseHexByte(hex[3:4] + hex[3:4])
+	case 6: // #RRGGBB
+		r = parseHexByte(hex[0:2])
+		g = parseHexByte(hex[2:4])
+		b = parseHexByte(hex[4:6])
+	case 8: // #RRGGBBAA
+		r = parseHexByte(hex[0:2])
+		g = parseHexByte(hex[2:4])
+		b = parseHexByte(hex[4:6])
+		a = parseHexByte(hex[6:8])
+	default:
+		return style.Color{}, false
+	}
+
+	return style.Color{R: r, G: g, B: b, A: a}, true
+}
+
+// parseHexByte converts a hex byte string to a uint8
+func parseHexByte(hex string) uint8 {
+	val, _ := strconv.ParseInt(hex, 16, 64)
+	return uint8(val)
+}
+
+// parseRGBFunction parses rgb() and rgba() function strings
+func parseRGBFunction(s string) (style.Color, bool) {
+	// Remove rgb( or rgba( and the closing )
+	s = strings.TrimPrefix(s, "rgb(")
+	s = strings.TrimPrefix(s, "rgba(")
+	s = strings.TrimSuffix(s, ")")
+
+	// Split by commas and trim spaces
+	parts := strings.Split(s, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	var r, g, b, a uint8
+	a = 255 // Default alpha to 255 (fully opaque)
+
+	// Parse RGB values
+	if len(parts) < 3 {
+		return style.Color{}, false
+	}
+
+	// Parse R
+	if strings.HasSuffix(parts[0], "%") {
+		r = uint8(parsePercentage(parts[0]) * 2.55) // Convert percentage to 0-255
+	} else {
+		r = uint8(parseNumber(parts[0]))
+	}
+
+	// Parse G
+	if strings.HasSuffix(parts[1], "%") {
+		g = uint8(parsePercentage(parts[1]) * 2.55) // Convert percentage to 0-255
+	} else {
+		g = uint8(parseNumber(parts[1]))
+	}
+
+	// Parse B
+	if strings.HasSuffix(parts[2], "%") {
+		b = uint8(parsePercentage(parts[2]) * 2.55) // Convert percentage to 0-255
+	} else {
+		b = uint8(parseNumber(parts[2]))
+	}
+
+	// Parse A if present (rgba)
+	if len(parts) > 3 {
+		// Alpha is typically 0-1 in rgba(), so multiply by 255
+		a = uint8(parseNumber(parts[3]) * 255)
+	}
+
+	return style.Color{R: r, G: g, B: b, A: a}, true
+}
+
+// parsePercentage converts a percentage string to a float64
+func parsePercentage(s string) float64 {
+	s = strings.TrimSuffix(s, "%")
+	val, _ := strconv.ParseFloat(s, 64)
+	return val
+}
+
+// parseNumber converts a number string to a float64
+func parseNumber(s string) float64 {
+	val, _ := strconv.ParseFloat(s, 64)
+	return val
+}
+
+// parseFilterString parses a CSS filter list such as "blur(4px) brightness(120%) drop-shadow(2px 4px 6px black)".
+// "none" and the empty string parse to no filters.
+func parseFilterString(s string) ([]style.Filter, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "none" {
+		return nil, true
+	}
+
+	var filters []style.Filter
+	for s != "" {
+		open := strings.Index(s, "(")
+		if open <= 0 {
+			return nil, false
+		}
+		// Find the matching parenthesis, colors like rgb() can be nested inside
+		depth := 0
+		end := -1
+		for i := open; i < len(s); i++ {
+			if s[i] == '(' {
+				depth++
+			} else if s[i] == ')' {
+				depth--
+				if depth == 0 {
+					end = i
+					break
+				}
+			}
+		}
+		if end < 0 {
+			return nil, false
+		}
+
+		name := strings.ToLower(strings.TrimSpace(s[:open]))
+		args := strings.TrimSpace(s[open+1 : end])
+		s = strings.TrimSpace(s[end+1:])
+
+		f := style.Filter{Function: name}
+		switch name {
+		case "blur":
+			f.Amount = parseFilterLength(args)
+		case "brightness", "contrast", "grayscale":
+			f.Amount = 1
+			if args != "" {
+				f.Amount = parseFilterAmount(args)
+			}
+		case "drop-shadow":
+			shadow, ok := parseDropShadow(args)
+			if !ok {
+				return nil, false
+			}
+			f.Shadow = shadow
+		default:
+			return nil, false
+		}
+		filters = append(filters, f)
+	}
+	return filters, true
+}
+
+// parseFilterLength parses a length such as "4px" or "4"
+func parseFilterLength(s string) float64 {
+	return parseNumber(strings.TrimSuffix(strings.TrimSpace(s), "px"))
+}
+
+// parseFilterAmount parses a factor such as "1.5" or "150%"
+func parseFilterAmount(s string) float64 {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "%") {
+		return parsePercentage(s) / 100
+	}
+	return parseNumber(s)
+}
+
+// parseDropShadow parses the arguments of drop-shadow(): two or three lengths and an optional color
+func parseDropShadow(args string) (style.ShadowStyle, bool) {
+	shadow := style.ShadowStyle{Color: style.Black}
+
+	// Split on spaces that are not inside a color function
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range args {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ' ' && depth == 0:
+			if part := strings.TrimSpace(args[start:i]); part != "" {
+				parts = append(parts, part)
+			}
+			start = i + 1
+		}
+	}
+	if part := strings.TrimSpace(args[start:]); part != "" {
+		parts = append(parts, part)
+	}
+
+	var lengths []float64
+	for _, part := range parts {
+		if num, err := strconv.ParseFloat(strings.TrimSuffix(part, "px"), 64); err == nil {
+			lengths = append(lengths, num)
+		} else if color, ok := parseColorString(part); ok {
+			shadow.Color = color
+		} else {
+			return style.ShadowStyle{}, false
+		}
+	}
+	if len(lengths) < 2 || len(lengths) > 3 {
+		return style.ShadowStyle{}, false
+	}
+
+	shadow.OffsetX = lengths[0]
+	shadow.OffsetY = lengths[1]
+	if len(lengths) == 3 {
+		shadow.BlurRadius = lengths[2]
+	}
+	return shadow, true
+}
@@ -0,0 +1,256 @@
+package node
+
+import (
+	"math"
+	"strings"
+
+	"github.com/noahdw/goui/node/style"
+)
+
+// tableRowNode is implemented by nodes that can be a row of a table, which sizes the row
+// and places its cells
+type tableRowNode interface {
+	setTableCells(size style.Size, areas []style.Rect)
+}
+
+// setTableCells sizes the row and gives its cells their areas. The table lays out the
+// cells itself, so this leaves the row laid out as storeLayout does for other nodes.
+func (n *BaseNode) setTableCells(size style.Size, areas []style.Rect) {
+	n.finalSize = size
+	n.gridAreas = areas
+	n.layoutValid = true
+	n.descendantLayoutDirty = false
+	n.arrangeValid = false
+}
+
+// isTable returns true if the children are rows of cells laid out on shared columns
+func (n *BaseNode) isTable() bool {
+	display, _ := n.styles.GetString("display")
+	return display == "table"
+}
+
+// isTableRow returns true if the node's cells are placed by the table it is a row of
+func (n *BaseNode) isTableRow() bool {
+	display, _ := n.styles.GetString("display")
+	return display == "table-row"
+}
+
+// tableRows returns the rows of a table and the cells in each
+func (n *BaseNode) tableRows() ([]Node, [][]Node) {
+	rows := n.flowChildren()
+	cells := make([][]Node, len(rows))
+	for i, row := range rows {
+		for _, cell := range row.Children() {
+			if !isOutOfFlow(cell) {
+				cells[i] = append(cells[i], cell)
+			}
+		}
+	}
+	return rows, cells
+}
+
+// placeTableCells assigns each cell its columns and rows. Cells take the first column in their
+// row not covered by a cell spanning down from a row above, and row spans end at the last row.
+// It returns the number of columns the cells need.
+func placeTableCells(cells [][]Node) ([]gridItem, int) {
+	var items []gridItem
+	occupied := make(map[[2]int]bool)
+	columnCount := 0
+	for row, rowCells := range cells {
+		column := 0
+		for _, cell := range rowCells {
+			for occupied[[2]int{row, column}] {
+				column++
+			}
+			item := gridItem{
+				node:       cell,
+				row:        row,
+				column:     column,
+				columnSpan: tableSpan(cell, "colSpan"),
+				rowSpan:    min(tableSpan(cell, "rowSpan"), len(cells)-row),
+			}
+			for r := row; r < row+item.rowSpan; r++ {
+				for c := column; c < column+item.columnSpan; c++ {
+					occupied[[2]int{r, c}] = true
+				}
+			}
+			items = append(items, item)
+			column += item.columnSpan
+			columnCount = max(columnCount, column)
+		}
+	}
+	return items, columnCount
+}
+
+// tableSpan returns the number of columns or rows a cell covers
+func tableSpan(cell Node, key string) int {
+	span, _ := cell.GetStyles().GetFloat(key)
+	return max(int(span), 1)
+}
+
+// tableTracks returns the column tracks from gridTemplateColumns, with content sized columns
+// added for cells beyond them, and a content sized row track for each row with its height
+// as the minimum
+func (n *BaseNode) tableTracks(rows []Node, columnCount int) (columns, rowTracks []style.GridTrack) {
+	columns = withImplicitTracks(n.gridTracks("gridTemplateColumns"), columnCount)
+	for _, row := range rows {
+		track := style.GridTrack{Min: style.StyleValue{Type: style.AUTO}, Max: style.StyleValue{Type: style.AUTO}}
+		if height, ok := intrinsicLength(row.GetStyles(), "height"); ok {
+			track.Min = style.StyleValue{Type: style.PIXEL, Value: height}
+		}
+		rowTracks = append(rowTracks, track)
+	}
+	return columns, rowTracks
+}
+
+// tableGaps returns the spacing between rows and columns. Separate borders are spaced by
+// the gaps, collapsed borders overlap, which is a negative gap of the widest border.
+func (n *BaseNode) tableGaps(items []gridItem, content style.Size) (rowGap, columnGap float64) {
+	if collapse, _ := n.styles.GetString("borderCollapse"); collapse != "collapse" {
+		return n.gaps(content)
+	}
+	for _, item := range items {
+		border, _ := item.node.GetStyles().Get("border")
+		if b, ok := border.(style.BorderStyle); ok && b.CanDisplay() {
+			rowGap = math.Max(rowGap, math.Max(b.Width.Top, b.Width.Bottom))
+			columnGap = math.Max(columnGap, math.Max(b.Width.Left, b.Width.Right))
+		}
+	}
+	return -rowGap, -columnGap
+}
+
+// measureTable sizes the columns and rows of an indefinite table around the cells
+func (n *BaseNode) measureTable(ctx RenderContext) (minContent, maxContent style.Size) {
+	for _, child := range n.children {
+		if isOutOfFlow(child) {
+			child.MeasurePreferred(ctx)
+		}
+	}
+
+	rows, cells := n.tableRows()
+	items, columnCount := placeTableCells(cells)
+	preferred := make([]style.Size, len(items))
+	for i, item := range items {
+		preferred[i] = item.node.MeasurePreferred(ctx)
+	}
+	rowGap, columnGap := n.tableGaps(items, style.Size{})
+	columns, rowTracks := n.tableTracks(rows, columnCount)
+
+	measure := func(contentSize func(child Node, preferred style.Size) style.Size) style.Size {
+		for i := range items {
+			items[i].size = contentSize(items[i].node, preferred[i])
+		}
+		columnSizes := sizeGridTracks(columns, items, 0, false, columnGap, true)
+		rowSizes := sizeGridTracks(rowTracks, items, 0, false, rowGap, false)
+		return style.Size{
+			Width:  math.Max(0, spanSize(columnSizes, 0, len(columnSizes), columnGap)),
+			Height: math.Max(0, spanSize(rowSizes, 0, len(rowSizes), rowGap)),
+		}
+	}
+
+	maxContent = measure(func(child Node, preferred style.Size) style.Size { return preferred })
+	minContent = measure(minContentContribution)
+	return minContent, maxContent
+}
+
+// layoutTable sizes the columns from the cells, then the rows from the cells at their column
+// widths. Cells fill the columns and rows they span, and each row is sized to its track.
+func (n *BaseNode) layoutTable(ctx RenderContext, available style.Size, definiteWidth, definiteHeight bool) {
+	rows, cells := n.tableRows()
+	items, columnCount := placeTableCells(cells)
+	rowGap, columnGap := n.tableGaps(items, available)
+	columns, rowTracks := n.tableTracks(rows, columnCount)
+	aligns := strings.Fields(n.columnAlign())
+
+	constraints := Constraints{
+		MaxWidth:  math.Max(0, available.Width),
+		MaxHeight: math.Max(0, available.Height),
+	}
+	for i := range items {
+		items[i].node.GetStyles().ResolvePercentages(available.Width)
+		items[i].size = items[i].node.Layout(ctx, constraints)
+		if column := items[i].column; column < len(aligns) {
+			alignText(items[i].node, aligns[column])
+		}
+	}
+	columnSizes := sizeGridTracks(columns, items, available.Width, definiteWidth, columnGap, true)
+
+	for i := range items {
+		item := &items[i]
+		item.size.Width = math.Max(0, spanSize(columnSizes, item.column, item.columnSpan, columnGap))
+		resizeChild(ctx, item.node, item.size, true, false)
+		item.size = item.node.GetFinalSize()
+	}
+	rowSizes := sizeGridTracks(rowTracks, items, available.Height, definiteHeight, rowGap, false)
+
+	columnStarts := trackStarts(columnSizes, columnGap)
+	rowStarts := trackStarts(rowSizes, rowGap)
+	width := math.Max(0, spanSize(columnSizes, 0, len(columnSizes), columnGap))
+
+	// Cells are placed relative to the row they belong to
+	cellAreas := make([][]style.Rect, len(rows))
+	for i := range items {
+		item := &items[i]
+		area := style.Rect{
+			Position: style.Point{X: columnStarts[item.column]},
+			Size: style.Size{
+				Width:  item.size.Width,
+				Height: math.Max(0, spanSize(rowSizes, item.row, item.rowSpan, rowGap)),
+			},
+		}
+		item.size.Height = area.Size.Height
+		resizeChild(ctx, item.node, item.size, true, true)
+		cellAreas[item.row] = append(cellAreas[item.row], area)
+	}
+
+	areas := make([]style.Rect, len(rows))
+	for i, row := range rows {
+		areas[i] = style.Rect{
+			Position: style.Point{Y: rowStarts[i]},
+			Size:     style.Size{Width: width, Height: rowSizes[i]},
+		}
+		if tableRow, ok := row.(tableRowNode); ok {
+			tableRow.setTableCells(areas[i].Size, cellAreas[i])
+		}
+	}
+	n.gridAreas = areas
+}
+
+// columnAlign returns the text alignment of each column, separated by spaces
+func (n *BaseNode) columnAlign() string {
+	align, _ := n.styles.GetString("columnAlign")
+	return align
+}
+
+// alignText gives a cell and its descendants its column's text alignment, stopping at
+// nodes with an alignment of their own
+func alignText(n Node, align string) {
+	styles := n.GetStyles()
+	if value, ok := styles.GetValue("textAlign"); ok && value.Source == style.Explicit {
+		return
+	}
+	if current, _ := styles.GetString("textAlign"); current != align {
+		styles.Set("textAlign", style.StyleValue{Type: style.PIXEL, Value: align, Source: style.Default})
+		n.MarkPaintDirty()
+	}
+	for _, child := range n.Children() {
+		alignText(child, align)
+	}
+}
+
+// arrangeTable positions the rows, which position their cells
+func (n *BaseNode) arrangeTable(ctx RenderContext, content style.Rect) {
+	for i, row := range n.flowChildren() {
+		area := style.Rect{}
+		if i < len(n.gridAreas) {
+			area = n.gridAreas[i]
+		}
+		row.ArrangeChildren(ctx, style.Rect{
+			Position: style.Point{
+				X: content.Position.X + area.Position.X,
+				Y: content.Position.Y + area.Position.Y,
+			},
+			Size: area.Size,
+		})
+	}
+}
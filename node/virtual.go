@@ -0,0 +1,426 @@
+package node
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/noahdw/goui/node/style"
+)
+
+const (
+	defaultOverscan    = 4  // Rows realized beyond each edge of the view
+	estimatedRowHeight = 24 // Height assumed for measured rows until one has been laid out
+)
+
+// VirtualNode is a scrolling list of rows that only creates, lays out and paints the rows in
+// view plus an overscan. Rows leaving the view are handed back to the row builder to be
+// reused for the rows coming into view. Rows have a fixed height, or are measured once they
+// are laid out with an estimate standing in for the rows not seen yet.
+type VirtualNode struct {
+	BaseNode
+	count     int
+	columns   int     // Items per row, 1 for a list
+	rowHeight float64 // 0 when rows are measured
+	overscan  int
+	build     func(index int, recycled Node) Node
+	ctx       RenderContext
+
+	// The realized rows [first, last) between spacers standing in for the rest
+	first, last   int
+	rows          []Node
+	stale         bool
+	before, after Node
+
+	// Measured row heights, 0 for rows not laid out yet, and the offsets of the rows
+	heights       []float64
+	tops          []float64
+	measuredSum   float64
+	measuredCount int
+}
+
+// NewVirtualNode creates a virtual list of count items laid out columns to a row. build returns
+// the node for an item, updating and returning recycled when it is not nil. A rowHeight of 0
+// measures the rows.
+func NewVirtualNode(baseNode BaseNode, count, columns int, rowHeight float64, build func(index int, recycled Node) Node) *VirtualNode {
+	spacer := func() Node {
+		return NewBaseNodeWithProps("virtual_spacer", map[string]interface{}{
+			"background": style.Transparent,
+			"flexShrink": 0,
+		})
+	}
+	v := &VirtualNode{
+		BaseNode:  baseNode,
+		columns:   max(columns, 1),
+		rowHeight: math.Max(rowHeight, 0),
+		overscan:  defaultOverscan,
+		build:     build,
+		before:    spacer(),
+		after:     spacer(),
+	}
+	v.scrolled = v.refresh
+	v.SetItemCount(count)
+	return v
+}
+
+// SetItemCount changes the number of items and rebuilds the rows in view
+func (v *VirtualNode) SetItemCount(count int) *VirtualNode {
+	v.count = max(count, 0)
+	if v.rowHeight == 0 {
+		rows := v.rowCount()
+		if rows < len(v.heights) {
+			v.heights = v.heights[:rows]
+		} else {
+			v.heights = append(v.heights, make([]float64, rows-len(v.heights))...)
+		}
+		v.measuredSum, v.measuredCount = 0, 0
+		for _, height := range v.heights {
+			if height > 0 {
+				v.measuredSum += height
+				v.measuredCount++
+			}
+		}
+		v.tops = nil
+	}
+	v.Refresh()
+	return v
+}
+
+// Overscan sets the number of rows realized beyond each edge of the view
+func (v *VirtualNode) Overscan(rows int) *VirtualNode {
+	v.overscan = max(rows, 0)
+	v.refresh()
+	return v
+}
+
+// Refresh rebuilds the rows in view, for when the items they show have changed
+func (v *VirtualNode) Refresh() *VirtualNode {
+	v.stale = true
+	v.refresh()
+	return v
+}
+
+// ScrollToItem scrolls the item's row to the top of the view
+func (v *VirtualNode) ScrollToItem(index int) *VirtualNode {
+	padding, _ := v.styles.GetEdgeInsets("padding")
+	v.ScrollTo(v.scrollOffset.X, v.rowTop(index/v.columns)+padding.Top)
+	return v
+}
+
+// The style methods usually chained onto a list return the list itself, so that the node
+// added to the parent is still virtual
+
+func (v *VirtualNode) SetID(id string) Node {
+	v.BaseNode.SetID(id)
+	return v
+}
+
+func (v *VirtualNode) Width(value interface{}) Node {
+	v.BaseNode.Width(value)
+	return v
+}
+
+func (v *VirtualNode) Height(value interface{}) Node {
+	v.BaseNode.Height(value)
+	return v
+}
+
+func (v *VirtualNode) MinWidth(value interface{}) Node {
+	v.BaseNode.MinWidth(value)
+	return v
+}
+
+func (v *VirtualNode) MaxWidth(value interface{}) Node {
+	v.BaseNode.MaxWidth(value)
+	return v
+}
+
+func (v *VirtualNode) MinHeight(value interface{}) Node {
+	v.BaseNode.MinHeight(value)
+	return v
+}
+
+func (v *VirtualNode) MaxHeight(value interface{}) Node {
+	v.BaseNode.MaxHeight(value)
+	return v
+}
+
+func (v *VirtualNode) Margin(value interface{}) Node {
+	v.BaseNode.Margin(value)
+	return v
+}
+
+func (v *VirtualNode) Padding(value interface{}) Node {
+	v.BaseNode.Padding(value)
+	return v
+}
+
+func (v *VirtualNode) FlexGrow(value float64) Node {
+	v.BaseNode.FlexGrow(value)
+	return v
+}
+
+func (v *VirtualNode) FlexShrink(value float64) Node {
+	v.BaseNode.FlexShrink(value)
+	return v
+}
+
+func (v *VirtualNode) FlexBasis(value interface{}) Node {
+	v.BaseNode.FlexBasis(value)
+	return v
+}
+
+func (v *VirtualNode) AlignSelf(value string) Node {
+	v.BaseNode.AlignSelf(value)
+	return v
+}
+
+func (v *VirtualNode) Gap(value interface{}) Node {
+	v.BaseNode.Gap(value)
+	return v
+}
+
+func (v *VirtualNode) Background(value interface{}) Node {
+	v.BaseNode.Background(value)
+	return v
+}
+
+func (v *VirtualNode) Border(value interface{}) Node {
+	v.BaseNode.Border(value)
+	return v
+}
+
+func (v *VirtualNode) BorderRadius(value interface{}) Node {
+	v.BaseNode.BorderRadius(value)
+	return v
+}
+
+func (v *VirtualNode) ScrollbarWidth(value float64) Node {
+	v.BaseNode.ScrollbarWidth(value)
+	return v
+}
+
+// ResolveStyles realizes the rows in view before the tree is laid out
+func (v *VirtualNode) ResolveStyles(parentStyles style.Styles) style.Styles {
+	v.realize()
+	return v.BaseNode.ResolveStyles(parentStyles)
+}
+
+func (v *VirtualNode) MeasurePreferred(ctx RenderContext) style.Size {
+	v.ctx = ctx
+	return v.BaseNode.MeasurePreferred(ctx)
+}
+
+// ArrangeChildren arranges the rows, then realizes the rows for the view at its final size
+func (v *VirtualNode) ArrangeChildren(ctx RenderContext, bounds style.Rect) {
+	v.ctx = ctx
+	v.BaseNode.ArrangeChildren(ctx, bounds)
+	v.refresh()
+}
+
+// refresh realizes the rows in view and lays out just this list when they or the measured
+// heights changed. A second pass picks up the heights of rows measured by the first.
+func (v *VirtualNode) refresh() {
+	if v.ctx == nil {
+		return
+	}
+	for pass := 0; pass < 2; pass++ {
+		measured := v.recordHeights()
+		if !v.realize() && !measured {
+			return
+		}
+		v.updateSpacers()
+		v.relayout()
+	}
+}
+
+// relayout lays out the realized rows within the list's current bounds
+func (v *VirtualNode) relayout() {
+	for _, child := range v.children {
+		child.ResolveStyles(v.styles)
+		child.MeasurePreferred(v.ctx)
+	}
+	v.layoutAtSize(v.ctx, v.finalSize, true, true)
+	v.arrangeContent(v.ctx)
+	v.MarkPaintDirty()
+}
+
+// realize builds the rows in view, reusing the rows that stay in view and recycling the
+// rest. Recycled rows left over are detached and dropped. It returns false if the realized
+// rows did not change.
+func (v *VirtualNode) realize() bool {
+	first, last := v.visibleRows()
+	if first == v.first && last == v.last && !v.stale {
+		return false
+	}
+
+	// Rows leaving the view are free to be reused
+	var kept, free []Node
+	for i, row := range v.rows {
+		index := v.first + i
+		if v.stale || index < first || index >= last {
+			free = append(free, row)
+		}
+	}
+	for index := first; index < last; index++ {
+		if !v.stale && index >= v.first && index < v.last {
+			kept = append(kept, v.rows[index-v.first])
+			continue
+		}
+		var recycled Node
+		if len(free) > 0 {
+			recycled = free[len(free)-1]
+			free = free[:len(free)-1]
+		}
+		row := v.bindRow(index, recycled)
+		row.SetParent(v)
+		row.MarkStyleDirty()
+		kept = append(kept, row)
+	}
+	for _, row := range free {
+		row.SetParent(nil)
+	}
+	v.rows, v.first, v.last, v.stale = kept, first, last, false
+
+	v.children = append([]Node{v.before}, v.rows...)
+	v.children = append(v.children, v.after)
+	for _, child := range v.children {
+		child.SetParent(v)
+	}
+	v.updateSpacers()
+	v.MarkLayoutDirty()
+	return true
+}
+
+// bindRow builds the row at index, from a recycled row when there is one
+func (v *VirtualNode) bindRow(index int, recycled Node) Node {
+	var row Node
+	if v.columns == 1 {
+		row = v.build(index, recycled)
+	} else {
+		// A grid row holds its items in equal columns
+		container, ok := recycled.(*BaseNode)
+		if !ok {
+			container = NewBaseNodeWithProps("virtual_row", map[string]interface{}{
+				"background": style.Transparent,
+				"display":    "grid",
+			}).(*BaseNode)
+			container.GridTemplateColumns(fmt.Sprintf("repeat(%d, 1fr)", v.columns))
+		}
+		cells := container.children
+		container.children = nil
+		for column := 0; column < v.columns; column++ {
+			item := index*v.columns + column
+			if item >= v.count {
+				break
+			}
+			var old Node
+			if column < len(cells) {
+				old = cells[column]
+			}
+			cell := v.build(item, old)
+			cell.SetParent(container)
+			cell.MarkStyleDirty()
+			container.children = append(container.children, cell)
+		}
+		for i := len(container.children); i < len(cells); i++ {
+			cells[i].SetParent(nil)
+		}
+		container.MarkLayoutDirty()
+		row = container
+	}
+
+	// Rows keep their height in the scrolled column
+	row.FlexShrink(0)
+	if v.rowHeight > 0 {
+		row.Height(v.rowHeight)
+	}
+	return row
+}
+
+// updateSpacers sizes the spacers to the rows before and after the realized rows
+func (v *VirtualNode) updateSpacers() {
+	v.before.Height(v.rowTop(v.first))
+	v.after.Height(v.rowTop(v.rowCount()) - v.rowTop(v.last))
+}
+
+// recordHeights stores the heights of the realized rows. It returns true if any changed.
+func (v *VirtualNode) recordHeights() bool {
+	if v.rowHeight > 0 {
+		return false
+	}
+	changed := false
+	for i, row := range v.rows {
+		index := v.first + i
+		height := row.GetFinalSize().Height
+		if height <= 0 || index >= len(v.heights) || height == v.heights[index] {
+			continue
+		}
+		if v.heights[index] > 0 {
+			v.measuredSum -= v.heights[index]
+		} else {
+			v.measuredCount++
+		}
+		v.measuredSum += height
+		v.heights[index] = height
+		changed = true
+	}
+	if changed {
+		v.tops = nil
+	}
+	return changed
+}
+
+// visibleRows returns the rows in view plus the overscan
+func (v *VirtualNode) visibleRows() (int, int) {
+	padding, _ := v.styles.GetEdgeInsets("padding")
+	top := v.scrollOffset.Y - padding.Top
+	rows := v.rowCount()
+	first := max(v.rowAt(top)-v.overscan, 0)
+	last := min(v.rowAt(top+v.finalSize.Height)+1+v.overscan, rows)
+	return min(first, last), last
+}
+
+func (v *VirtualNode) rowCount() int {
+	return (v.count + v.columns - 1) / v.columns
+}
+
+// rowAt returns the row at offset y from the top of the rows
+func (v *VirtualNode) rowAt(y float64) int {
+	if y <= 0 {
+		return 0
+	}
+	if v.rowHeight > 0 {
+		return int(y / v.rowHeight)
+	}
+	tops := v.rowTops()
+	return sort.Search(len(tops)-1, func(i int) bool { return tops[i+1] > y })
+}
+
+// rowTop returns the offset of a row from the top of the rows
+func (v *VirtualNode) rowTop(row int) float64 {
+	if v.rowHeight > 0 {
+		return float64(row) * v.rowHeight
+	}
+	return v.rowTops()[row]
+}
+
+// rowTops returns the offsets of the measured rows, with the average measured height
+// standing in for the rows not laid out yet
+func (v *VirtualNode) rowTops() []float64 {
+	if v.tops != nil {
+		return v.tops
+	}
+	estimate := float64(estimatedRowHeight)
+	if v.measuredCount > 0 {
+		estimate = v.measuredSum / float64(v.measuredCount)
+	}
+	v.tops = make([]float64, len(v.heights)+1)
+	for i, height := range v.heights {
+		if height <= 0 {
+			height = estimate
+		}
+		v.tops[i+1] = v.tops[i] + height
+	}
+	return v.tops
+}
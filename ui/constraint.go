@@ -0,0 +1,29 @@
+package ui
+
+import (
+	n "github.com/noahdw/goui/node"
+	"github.com/noahdw/goui/node/style"
+)
+
+// ConstraintLayout creates a container that places its children by rules relating their
+// edges, sizes and centers, which are added with Constrain and name children by their ID.
+// Children keep their own size and sit at the top left unless a rule says otherwise.
+//
+// Example:
+//
+//	ConstraintLayout(
+//	  Text("Name").SetID("label"),
+//	  Rect().SetID("input").Width(200).Height(24),
+//	).
+//	  Constrain("label.left == input.left", solver.Required).
+//	  Constrain("input.top == label.bottom + 4", solver.Required).
+//	  Constrain("input.width >= 0.5 * parent.width", solver.Weak)
+func ConstraintLayout(children ...n.Node) n.Node {
+	props := map[string]interface{}{
+		"display":    "constraint",
+		"background": style.Transparent,
+	}
+	node := n.NewBaseNodeWithProps("constraint", props)
+	node.AddChildren(children...)
+	return node
+}
@@ -0,0 +1,26 @@
+package ui
+
+import (
+	n "github.com/noahdw/goui/node"
+	"github.com/noahdw/goui/node/style"
+)
+
+// Stack creates a container that layers its children on top of each other in its content
+// box, later children painting over earlier ones. Each child is placed with StackAlign and
+// StackOffset, and an auto sized stack fits its largest child.
+//
+// Example:
+//
+//	Stack(
+//	  Image("avatar.png"),
+//	  Rect().Width(12).Height(12).Background("red").StackAlign("top-right").StackOffset(4, -4),
+//	)
+func Stack(children ...n.Node) n.Node {
+	props := map[string]interface{}{
+		"display":    "stack",
+		"background": style.Transparent,
+	}
+	node := n.NewBaseNodeWithProps("stack", props)
+	node.AddChildren(children...)
+	return node
+}
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	n "github.com/noahdw/goui/node"
+	"github.com/noahdw/goui/node/style"
+)
+
+// Table creates a table of rows whose cells share columns. Columns are sized by
+// gridTemplateColumns, and from their cells' content where it has no track for them.
+func Table(rows ...n.Node) n.Node {
+	props := map[string]interface{}{
+		"display": "table",
+	}
+	node := n.NewBaseNodeWithProps("table", props)
+	node.AddChildren(rows...)
+	return node
+}
+
+// Row creates a table row with the given cells
+func Row(cells ...n.Node) n.Node {
+	props := map[string]interface{}{
+		"display":    "table-row",
+		"background": style.Transparent,
+	}
+	node := n.NewBaseNodeWithProps("row", props)
+	node.AddChildren(cells...)
+	return node
+}
+
+// HeaderRow creates a table row that sticks to the top of the scrolling container
+// while its table is in view
+func HeaderRow(cells ...n.Node) n.Node {
+	return Row(cells...).Position("sticky").Top(0)
+}
+
+// Cell creates a table cell. Its children are stacked in a column and fill its width.
+func Cell(children ...n.Node) n.Node {
+	props := map[string]interface{}{
+		"flexDirection": "column",
+		"padding":       style.EdgeInsets{Top: 4, Right: 8, Bottom: 4, Left: 8},
+	}
+	node := n.NewBaseNodeWithProps("cell", props)
+	node.AddChildren(children...)
+	return node
+}
@@ -0,0 +1,28 @@
+package ui
+
+import (
+	n "github.com/noahdw/goui/node"
+	"github.com/noahdw/goui/node/style"
+)
+
+// VirtualList creates a scrolling list of count rows that only builds the rows in view.
+// build returns the node for a row, updating and returning recycled when it is not nil.
+// A rowHeight of 0 measures each row instead. The sizing, spacing and background style
+// methods return the list itself, so it can be styled as it is added to its parent.
+func VirtualList(count int, rowHeight float64, build func(index int, recycled n.Node) n.Node) *n.VirtualNode {
+	return n.NewVirtualNode(virtualBase("virtual_list"), count, 1, rowHeight, build)
+}
+
+// VirtualGrid creates a scrolling grid of count items in equal columns that only builds the
+// rows in view. build works as for VirtualList.
+func VirtualGrid(count, columns int, rowHeight float64, build func(index int, recycled n.Node) n.Node) *n.VirtualNode {
+	return n.NewVirtualNode(virtualBase("virtual_grid"), count, columns, rowHeight, build)
+}
+
+func virtualBase(nodeType string) n.BaseNode {
+	props := map[string]interface{}{
+		"flexDirection": "column",
+		"overflow":      "auto",
+	}
+	return n.NewBaseNode(nodeType, style.NewStyles(props))
+}